@@ -1,47 +1,297 @@
 package main
 
 import (
+	"auth-ad/src/internal/adminApi"
 	"auth-ad/src/internal/authentication"
+	"auth-ad/src/internal/grpcApi"
+	"auth-ad/src/internal/httpApi"
+	"auth-ad/src/internal/interfaces"
+	"auth-ad/src/internal/metrics"
+	"auth-ad/src/internal/repositories/breakerRepositories"
+	"auth-ad/src/internal/repositories/metricsRepositories"
 	"auth-ad/src/internal/repositories/microsoftActiveDirectory"
+	"auth-ad/src/internal/repositories/natsGateway"
 	"auth-ad/src/internal/repositories/smarketAPIGateway"
+	"auth-ad/src/internal/repositories/spoolRepositories"
 	"auth-ad/src/internal/services/apiService"
 	"auth-ad/src/internal/services/authService"
+	"auth-ad/src/internal/spool"
+	"auth-ad/src/pkg/circuitBreaker"
 	"auth-ad/src/pkg/configs"
-	"fmt"
-	"log"
-
-	"github.com/go-ldap/ldap/v3"
+	"auth-ad/src/pkg/logging"
+	"auth-ad/src/pkg/throttle"
+	"auth-ad/src/pkg/tracing"
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 )
 
+// tracingShutdownTimeout é o prazo para enviar os spans pendentes no encerramento
+const tracingShutdownTimeout = 5 * time.Second
+
+// spoolFlushTimeout é o prazo para o último reenvio do spool de respostas no encerramento
+const spoolFlushTimeout = 5 * time.Second
+
+// logger registra a inicialização e o encerramento do serviço
+var logger = logging.Component("main")
+
 func main() {
 
 	configs.LoadEnv()
 
+	// Os logs são configurados antes de tudo, para que os demais erros já saiam no formato configurado
+	loggingConfig, err := configs.GetLoggingConfig()
+	if err != nil {
+		fatal("Erro ao carregar as configurações", err)
+	}
+	logging.Setup(loggingConfig)
+
 	adConfig, err := configs.GetADConfig()
 	if err != nil {
-		log.Fatalf("Erro ao carregar as configurações: %v", err)
+		fatal("Erro ao carregar as configurações", err)
+	}
+
+	apiConfig, err := configs.GetApiConfig()
+	if err != nil {
+		fatal("Erro ao carregar as configurações", err)
+	}
+
+	authConfig, err := configs.GetAuthenticationConfig()
+	if err != nil {
+		fatal("Erro ao carregar as configurações", err)
+	}
+
+	spoolConfig, err := configs.GetSpoolConfig()
+	if err != nil {
+		fatal("Erro ao carregar as configurações", err)
+	}
+
+	httpConfig, err := configs.GetHttpApiConfig()
+	if err != nil {
+		fatal("Erro ao carregar as configurações", err)
+	}
+
+	grpcConfig, err := configs.GetGrpcApiConfig()
+	if err != nil {
+		fatal("Erro ao carregar as configurações", err)
+	}
+
+	adminConfig, err := configs.GetAdminConfig()
+	if err != nil {
+		fatal("Erro ao carregar as configurações", err)
+	}
+
+	tracingConfig, err := configs.GetTracingConfig()
+	if err != nil {
+		fatal("Erro ao carregar as configurações", err)
+	}
+
+	throttleConfig, err := configs.GetThrottleConfig()
+	if err != nil {
+		fatal("Erro ao carregar as configurações", err)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracingConfig)
+	if err != nil {
+		fatal("Erro ao configurar os traces", err)
+	}
+
+	serviceMetrics := metrics.NewMetrics()
+
+	// Cada worker precisa de uma conexão de busca própria no pool
+	if adConfig.PoolMaxSize < authConfig.Workers {
+		adConfig.PoolMaxSize = authConfig.Workers
+	}
+
+	dialFunc := microsoftActiveDirectory.NewDialFunc(adConfig)
+
+	ldapConn, err := microsoftActiveDirectory.NewLDAPPool(adConfig, dialFunc)
+	if err != nil {
+		fatal("Erro ao conectar ao AD", err)
+	}
+
+	adRepository, err := microsoftActiveDirectory.NewADRepository(adConfig, ldapConn, dialFunc)
+	if err != nil {
+		fatal("Erro ao criar o repositório", err)
+	}
+
+	apiRepository, closeApi, err := newApiRepository(apiConfig, serviceMetrics.InstrumentTransport(http.DefaultTransport))
+	if err != nil {
+		fatal("Erro ao criar o gateway da API", err)
 	}
 
-	ldapConn, err := ldap.DialURL(fmt.Sprintf("ldap://%s:%d", adConfig.Server, adConfig.Port))
+	// As verificações de prontidão usam os repositórios sem métricas nem circuit breaker, para que
+	// as probes não alterem a latência medida nem o estado dos circuitos
+	directory, gateway := adRepository, apiRepository
+
+	// A latência é medida nas chamadas que chegam ao AD, sem as recusadas pelo circuit breaker
+	adRepository = metricsRepositories.NewADRepository(adRepository, serviceMetrics)
+	serviceMetrics.RegisterLDAPPool(ldapConn)
+
+	// Com o AD ou a API fora do ar, as chamadas falham imediatamente em vez de aguardar o timeout
+	adBreaker := circuitBreaker.NewCircuitBreaker("ad", adConfig.Breaker)
+	apiBreaker := circuitBreaker.NewCircuitBreaker("api", apiConfig.Breaker)
+	adRepository = breakerRepositories.NewADRepository(adRepository, adBreaker)
+	apiRepository = breakerRepositories.NewApiRepository(apiRepository, apiBreaker)
+
+	// Respostas que não puderem ser entregues são guardadas em disco e reenviadas em segundo plano
+	responseSpool, err := spool.Open(spoolConfig.Path)
 	if err != nil {
-		log.Fatalf("Erro ao conectar ao AD: %v", err)
+		fatal("Erro ao abrir o spool de respostas", err)
+	}
+	if err := responseSpool.Compact(); err != nil {
+		logger.Warn("Erro ao compactar o spool de respostas", logging.AttrError, err)
+	}
+	if pending := responseSpool.Stats().Pending; pending > 0 {
+		logger.Info("Respostas pendentes no spool serão reenviadas", "pending", pending)
+	}
+	spoolRepository := spoolRepositories.NewApiRepository(apiRepository, responseSpool, spoolConfig)
+	apiRepository = spoolRepository
+
+	apiService := apiService.NewApiService(apiRepository, apiConfig)
+	// Tentativas repetidas com senha incorreta são atrasadas e bloqueadas antes de bloquear a conta no AD
+	var throttler *throttle.Throttle
+	if throttleConfig.Enabled {
+		throttler = throttle.NewThrottle(*throttleConfig)
+	}
+	authService := authService.NewAuthService(adRepository, throttler)
+
+	authentication := authentication.NewAuthentication(authService, apiService, authConfig)
+	serviceMetrics.RegisterAuthentication(authentication)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var background sync.WaitGroup
+
+	// A API HTTP atende chamadas diretas das aplicações, em paralelo à fila da API Smarket
+	if httpConfig.Addr != "" {
+		listener, err := net.Listen("tcp", httpConfig.Addr)
+		if err != nil {
+			fatal("Erro ao iniciar a API HTTP", err)
+		}
+
+		server := httpApi.NewServer(authService, httpConfig)
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			if err := server.Serve(ctx, listener); err != nil {
+				logger.Error("Erro na API HTTP", logging.AttrError, err)
+			}
+		}()
 	}
 
-	adRepository, err := microsoftActiveDirectory.NewADRepository(adConfig, ldapConn)
+	// A API gRPC atende os serviços que só se comunicam por gRPC
+	if grpcConfig.Addr != "" {
+		listener, err := net.Listen("tcp", grpcConfig.Addr)
+		if err != nil {
+			fatal("Erro ao iniciar a API gRPC", err)
+		}
+
+		server := grpcApi.NewServer(authService, grpcConfig)
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			if err := server.Serve(ctx, listener); err != nil {
+				logger.Error("Erro na API gRPC", logging.AttrError, err)
+			}
+		}()
+	}
+
+	// O servidor de administração expõe as métricas para o Prometheus, as verificações de saúde
+	// para as probes do Kubernetes e a remoção dos bloqueios da limitação de tentativas
+	if adminConfig.Addr != "" {
+		listener, err := net.Listen("tcp", adminConfig.Addr)
+		if err != nil {
+			fatal("Erro ao iniciar o servidor de administração", err)
+		}
+
+		server := adminApi.NewServer(adminConfig, serviceMetrics)
+		server.RegisterAuthentication(authentication)
+		server.RegisterBreaker(adBreaker)
+		server.RegisterBreaker(apiBreaker)
+		if pinger, ok := directory.(adminApi.Pinger); ok {
+			server.AddCheck("ad", pinger)
+		}
+		if pinger, ok := gateway.(adminApi.Pinger); ok {
+			server.AddCheck("api", pinger)
+		}
+		if throttler != nil {
+			server.RegisterThrottle(throttler)
+		}
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			if err := server.Serve(ctx, listener); err != nil {
+				logger.Error("Erro no servidor de administração", logging.AttrError, err)
+			}
+		}()
+	}
+
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		spoolRepository.Run(ctx)
+	}()
+
+	err = authentication.Start(ctx)
+	background.Wait()
+
+	// Os workers drenados podem ter gravado no spool depois do último ciclo do reenvio
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), spoolFlushTimeout)
+	spoolRepository.Flush(flushCtx)
+	cancelFlush()
+	if closeErr := responseSpool.Close(); closeErr != nil {
+		logger.Error("Erro ao fechar o spool de respostas", logging.AttrError, closeErr)
+	}
+	if closeErr := authService.Close(); closeErr != nil {
+		logger.Error("Erro ao encerrar as conexões com o AD", logging.AttrError, closeErr)
+	}
+	if closeErr := closeApi(); closeErr != nil {
+		logger.Error("Erro ao encerrar a conexão com a API", logging.AttrError, closeErr)
+	}
+
+	tracingCtx, cancelTracing := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+	if closeErr := shutdownTracing(tracingCtx); closeErr != nil {
+		logger.Error("Erro ao enviar os traces pendentes", logging.AttrError, closeErr)
+	}
+	cancelTracing()
 	if err != nil {
-		log.Fatalf("Erro ao criar o repositório: %v", err)
+		fatal("Erro ao iniciar a autenticação", err)
 	}
 
-	apiRepository := smarketAPIGateway.NewSmarketGateway("1234567890", adConfig.ApiUrl)
+	logger.Info("Serviço encerrado")
+}
+
+// fatal registra o erro que impede a execução do serviço e o encerra
+func fatal(message string, err error) {
+	logger.Error(message, logging.AttrError, err)
+	os.Exit(1)
+}
 
-	apiService := apiService.NewApiService(apiRepository)
-	authService := authService.NewAuthService(adRepository)
+// newApiRepository cria o gateway da fila de requisições conforme API_TRANSPORT: a API HTTP da
+// Smarket, cujas chamadas passam por transport, ou um stream do NATS JetStream
+// Retorna: o gateway, a função que encerra sua conexão e um erro em caso de falha na criação
+func newApiRepository(apiConfig *configs.ApiConfig, transport http.RoundTripper) (interfaces.IApiRepository, func() error, error) {
+	if apiConfig.Transport != configs.ApiTransportNats {
+		gateway, err := smarketAPIGateway.NewSmarketGatewayWithTransport(apiConfig, transport)
+		return gateway, func() error { return nil }, err
+	}
 
-	authentication := authentication.NewAuthentication(authService, apiService)
+	natsConfig, err := configs.GetNatsConfig()
+	if err != nil {
+		return nil, nil, err
+	}
 
-	err = authentication.Start()
+	gateway, err := natsGateway.NewNatsGateway(apiConfig, natsConfig)
 	if err != nil {
-		authService.Close()
-		log.Fatalf("Erro ao iniciar a autenticação: %v", err)
+		return nil, nil, err
 	}
+
+	return gateway, gateway.Close, nil
 }
@@ -0,0 +1,166 @@
+// spoolctl inspeciona e reenvia as respostas guardadas no spool de respostas não entregues.
+//
+// Uso:
+//
+//	spoolctl [-path arquivo] list
+//	spoolctl [-path arquivo] stats
+//	spoolctl [-path arquivo] replay [request_id...]
+//
+// O arquivo padrão é o de SPOOL_PATH. O replay usa as configurações da API (API_* e, com
+// API_TRANSPORT=nats, NATS_*) e deve ser executado com o serviço parado, já que ambos gravam no
+// mesmo arquivo.
+package main
+
+import (
+	"auth-ad/src/internal/interfaces"
+	"auth-ad/src/internal/repositories/natsGateway"
+	"auth-ad/src/internal/repositories/smarketAPIGateway"
+	"auth-ad/src/internal/spool"
+	"auth-ad/src/pkg/configs"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+func main() {
+	configs.LoadEnv()
+
+	spoolConfig, err := configs.GetSpoolConfig()
+	if err != nil {
+		fatalf("Erro ao carregar as configurações: %v", err)
+	}
+
+	path := flag.String("path", spoolConfig.Path, "arquivo do spool")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	responseSpool, err := spool.Open(*path)
+	if err != nil {
+		fatalf("Erro ao abrir o spool: %v", err)
+	}
+	defer responseSpool.Close()
+
+	switch flag.Arg(0) {
+	case "list":
+		list(responseSpool)
+	case "stats":
+		stats(responseSpool)
+	case "replay":
+		if failed := replay(responseSpool, flag.Args()[1:]); failed > 0 {
+			responseSpool.Close()
+			fatalf("%d respostas não puderam ser reenviadas", failed)
+		}
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+// usage exibe as instruções de uso
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "Uso: spoolctl [-path arquivo] list | stats | replay [request_id...]\n")
+	flag.PrintDefaults()
+}
+
+// list exibe as respostas pendentes
+func list(responseSpool *spool.Spool) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "REQUEST_ID\tGUARDADA EM\tIDADE\tSUCESSO\tMOTIVO")
+	for _, entry := range responseSpool.Entries() {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%t\t%s\n",
+			entry.RequestID,
+			entry.CreatedAt.Format(time.RFC3339),
+			time.Since(entry.CreatedAt).Round(time.Second),
+			entry.Response.Success,
+			entry.Response.Reason,
+		)
+	}
+	writer.Flush()
+}
+
+// stats exibe os contadores do spool
+func stats(responseSpool *spool.Spool) {
+	stats := responseSpool.Stats()
+	fmt.Printf("Pendentes:   %d\n", stats.Pending)
+	fmt.Printf("Entregues:   %d\n", stats.Delivered)
+	fmt.Printf("Descartadas: %d\n", stats.Dropped)
+}
+
+// replay reenvia as respostas pendentes informadas, ou todas quando nenhuma é informada
+// Parâmetros:
+//   - responseSpool: Spool de respostas
+//   - requestIDs: IDs das requisições a reenviar
+//
+// Retorna:
+//   - int: Quantidade de respostas que não puderam ser reenviadas
+func replay(responseSpool *spool.Spool, requestIDs []string) int {
+	apiConfig, err := configs.GetApiConfig()
+	if err != nil {
+		fatalf("Erro ao carregar as configurações: %v", err)
+	}
+
+	var gateway interfaces.IApiRepository
+	if apiConfig.Transport == configs.ApiTransportNats {
+		natsConfig, err := configs.GetNatsConfig()
+		if err != nil {
+			fatalf("Erro ao carregar as configurações: %v", err)
+		}
+
+		natsRepository, err := natsGateway.NewNatsGateway(apiConfig, natsConfig)
+		if err != nil {
+			fatalf("Erro ao criar o gateway da API: %v", err)
+		}
+		defer natsRepository.Close()
+		gateway = natsRepository
+	} else {
+		gateway, err = smarketAPIGateway.NewSmarketGateway(apiConfig)
+		if err != nil {
+			fatalf("Erro ao criar o gateway da API: %v", err)
+		}
+	}
+
+	selected := make(map[string]bool, len(requestIDs))
+	for _, requestID := range requestIDs {
+		if !responseSpool.Has(requestID) {
+			fmt.Printf("%s: sem resposta pendente\n", requestID)
+		}
+		selected[requestID] = true
+	}
+
+	failed := 0
+	for _, entry := range responseSpool.Entries() {
+		if len(selected) > 0 && !selected[entry.RequestID] {
+			continue
+		}
+
+		if err := gateway.SendResponse(context.Background(), entry.RequestID, entry.Response); err != nil {
+			fmt.Printf("%s: erro ao reenviar: %v\n", entry.RequestID, err)
+			failed++
+			continue
+		}
+
+		if err := responseSpool.Ack(entry.RequestID); err != nil {
+			fmt.Printf("%s: reenviada, mas não foi possível registrar a entrega: %v\n", entry.RequestID, err)
+			failed++
+			continue
+		}
+
+		fmt.Printf("%s: reenviada\n", entry.RequestID)
+	}
+
+	return failed
+}
+
+// fatalf exibe a mensagem de erro e encerra com código 1
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,247 @@
+package adminApi
+
+import (
+	"auth-ad/src/internal/authentication"
+	"auth-ad/src/pkg/circuitBreaker"
+	"auth-ad/src/pkg/logging"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Estados retornados pelas verificações de saúde
+const (
+	statusOK       = "ok"        // Processo ativo e laço de consultas em andamento
+	statusStalled  = "stalled"   // Laço de consultas sem consultas concluídas há mais de PollStallTimeout
+	statusReady    = "ready"     // Todas as dependências acessíveis
+	statusNotReady = "not_ready" // Alguma dependência inacessível
+	statusError    = "error"     // Falha na verificação de uma dependência
+)
+
+// Authentication é a parte do processamento das requisições lida pelas verificações de saúde
+type Authentication interface {
+	PollStatus() authentication.PollStatus
+}
+
+// Breaker é um circuit breaker cujo estado é exibido em /status
+type Breaker interface {
+	Name() string
+	State() circuitBreaker.State
+}
+
+// Pinger é uma dependência do serviço verificada em /readyz
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// check é uma dependência registrada, identificada pelo nome
+type check struct {
+	name   string
+	pinger Pinger
+}
+
+// checkResult é o resultado da verificação de uma dependência
+type checkResult struct {
+	Status     string  `json:"status"`
+	Error      string  `json:"error,omitempty"`
+	DurationMs float64 `json:"duration_ms"`
+}
+
+// readinessResponse é o corpo de /readyz
+type readinessResponse struct {
+	Status string                 `json:"status"`
+	Checks map[string]checkResult `json:"checks"`
+}
+
+// livenessResponse é o corpo de /healthz
+type livenessResponse struct {
+	Status       string     `json:"status"`
+	LastActivity *time.Time `json:"last_poll_activity,omitempty"`
+}
+
+// pollResponse é o estado do laço de consultas em /status
+type pollResponse struct {
+	Running             bool       `json:"running"`
+	LastActivity        *time.Time `json:"last_activity,omitempty"`
+	LastSuccess         *time.Time `json:"last_success,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+}
+
+// roundTripResponse é a última operação concluída no AD em /status
+type roundTripResponse struct {
+	Operation  string    `json:"operation"`
+	Outcome    string    `json:"outcome"`
+	DurationMs float64   `json:"duration_ms"`
+	Time       time.Time `json:"time"`
+}
+
+// statusResponse é o corpo de /status
+type statusResponse struct {
+	Status          string             `json:"status"`
+	Poll            *pollResponse      `json:"poll,omitempty"`
+	LastADRoundTrip *roundTripResponse `json:"last_ad_round_trip"`
+	Breakers        map[string]string  `json:"breakers"`
+}
+
+// RegisterAuthentication passa a verificar o laço de consultas em /healthz e a exibir seu estado
+// em /status. Deve ser chamado antes de Serve.
+// Parâmetros:
+//   - authentication: Processamento das requisições de autenticação
+func (s *Server) RegisterAuthentication(authentication Authentication) {
+	s.authentication = authentication
+}
+
+// RegisterBreaker passa a exibir o estado de um circuit breaker em /status. Deve ser chamado
+// antes de Serve.
+// Parâmetros:
+//   - breaker: Circuit breaker, identificado pelo nome
+func (s *Server) RegisterBreaker(breaker Breaker) {
+	s.breakers = append(s.breakers, breaker)
+}
+
+// AddCheck acrescenta uma dependência às verificações de /readyz. Deve ser chamado antes de Serve.
+// Parâmetros:
+//   - name: Nome da dependência na resposta (ex.: ad, api)
+//   - pinger: Dependência verificada
+func (s *Server) AddCheck(name string, pinger Pinger) {
+	s.checks = append(s.checks, check{name: name, pinger: pinger})
+}
+
+// handleHealthz indica se o processo está ativo e o laço de consultas não está travado. Responde
+// 503 quando o laço está em execução sem concluir uma consulta há mais de config.PollStallTimeout.
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	response := livenessResponse{Status: statusOK}
+	if s.authentication != nil {
+		poll := s.authentication.PollStatus()
+		response.LastActivity = timeOrNil(poll.LastActivity)
+		if s.stalled(poll) {
+			response.Status = statusStalled
+			writeJSON(w, http.StatusServiceUnavailable, response)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleReadyz verifica em paralelo as dependências registradas, cada uma com o prazo
+// config.CheckTimeout. Responde 503 quando alguma delas falha.
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	results := make(map[string]checkResult, len(s.checks))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range s.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := s.runCheck(r.Context(), c)
+
+			mu.Lock()
+			results[c.name] = result
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	response := readinessResponse{Status: statusReady, Checks: results}
+	for _, result := range results {
+		if result.Status != statusOK {
+			response.Status = statusNotReady
+		}
+	}
+
+	if response.Status != statusReady {
+		writeJSON(w, http.StatusServiceUnavailable, response)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleStatus retorna o estado detalhado do serviço: o laço de consultas, a última operação
+// concluída no AD e o estado dos circuit breakers
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	response := statusResponse{Status: statusOK, Breakers: make(map[string]string, len(s.breakers))}
+
+	if s.authentication != nil {
+		poll := s.authentication.PollStatus()
+		if s.stalled(poll) {
+			response.Status = statusStalled
+		}
+		response.Poll = &pollResponse{
+			Running:             poll.Running,
+			LastActivity:        timeOrNil(poll.LastActivity),
+			LastSuccess:         timeOrNil(poll.LastSuccess),
+			LastError:           logging.RedactText(poll.LastError),
+			ConsecutiveFailures: poll.ConsecutiveFailures,
+		}
+	}
+
+	if last, ok := s.metrics.LastLDAP(); ok {
+		response.LastADRoundTrip = &roundTripResponse{
+			Operation:  last.Operation,
+			Outcome:    last.Outcome,
+			DurationMs: milliseconds(last.Duration),
+			Time:       last.Time,
+		}
+	}
+
+	for _, breaker := range s.breakers {
+		response.Breakers[breaker.Name()] = breaker.State().String()
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// runCheck verifica uma dependência com o prazo config.CheckTimeout
+func (s *Server) runCheck(ctx context.Context, c check) checkResult {
+	ctx, cancel := context.WithTimeout(ctx, s.config.CheckTimeout)
+	defer cancel()
+
+	started := time.Now()
+	err := c.pinger.Ping(ctx)
+	result := checkResult{Status: statusOK, DurationMs: milliseconds(time.Since(started))}
+	if err != nil {
+		// As mensagens de erro podem trazer respostas da API; as credenciais são mascaradas como nos logs
+		result.Status = statusError
+		result.Error = logging.RedactText(err.Error())
+		logger.DebugContext(ctx, "Dependência indisponível", "check", c.name, logging.AttrError, err)
+	}
+
+	return result
+}
+
+// stalled indica se o laço de consultas está em execução sem concluir uma consulta há mais de
+// config.PollStallTimeout
+func (s *Server) stalled(poll authentication.PollStatus) bool {
+	return poll.Running && s.now().Sub(poll.LastActivity) > s.config.PollStallTimeout
+}
+
+// writeJSON envia uma resposta JSON com o status informado
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logger.Warn("Erro ao enviar a resposta HTTP", logging.AttrError, err)
+	}
+}
+
+// timeOrNil retorna nil para o instante zero, omitido nas respostas
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+// milliseconds converte uma duração em milissegundos
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,160 @@
+package adminApi
+
+import (
+	"auth-ad/src/internal/authentication"
+	"auth-ad/src/internal/metrics"
+	"auth-ad/src/pkg/circuitBreaker"
+	"auth-ad/src/pkg/configs"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAuthentication simula o estado do laço de consultas
+type fakeAuthentication struct {
+	status authentication.PollStatus
+}
+
+func (f *fakeAuthentication) PollStatus() authentication.PollStatus { return f.status }
+
+// fakeBreaker simula um circuit breaker em um estado fixo
+type fakeBreaker struct {
+	name  string
+	state circuitBreaker.State
+}
+
+func (f fakeBreaker) Name() string                { return f.name }
+func (f fakeBreaker) State() circuitBreaker.State { return f.state }
+
+// pingerFunc adapta uma função a Pinger
+type pingerFunc func(ctx context.Context) error
+
+func (f pingerFunc) Ping(ctx context.Context) error { return f(ctx) }
+
+// now é o instante fixo dos testes
+var now = time.Date(2024, 12, 9, 12, 0, 0, 0, time.UTC)
+
+func newTestServer() *Server {
+	server := NewServer(&configs.AdminConfig{
+		RequestTimeout:   time.Second,
+		PollStallTimeout: time.Minute,
+		CheckTimeout:     50 * time.Millisecond,
+	}, metrics.NewMetrics())
+	server.now = func() time.Time { return now }
+
+	return server
+}
+
+// get executa uma requisição GET no servidor e decodifica o corpo JSON
+func get(t *testing.T, server *Server, path string, body any) int {
+	recorder := httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+	if err := json.Unmarshal(recorder.Body.Bytes(), body); err != nil {
+		t.Fatalf("Resposta JSON inválida em %s: %v", path, err)
+	}
+
+	return recorder.Code
+}
+
+func TestHealthz(t *testing.T) {
+	server := newTestServer()
+
+	// Sem o processamento registrado, basta o processo responder
+	var response livenessResponse
+	assert.Equal(t, http.StatusOK, get(t, server, "/healthz", &response))
+	assert.Equal(t, statusOK, response.Status)
+
+	auth := &fakeAuthentication{status: authentication.PollStatus{Running: true, LastActivity: now.Add(-30 * time.Second)}}
+	server.RegisterAuthentication(auth)
+	assert.Equal(t, http.StatusOK, get(t, server, "/healthz", &response))
+
+	// Laço em execução sem concluir consultas além do limite
+	auth.status.LastActivity = now.Add(-2 * time.Minute)
+	assert.Equal(t, http.StatusServiceUnavailable, get(t, server, "/healthz", &response))
+	assert.Equal(t, statusStalled, response.Status)
+
+	// Laço encerrado não é considerado travado
+	auth.status.Running = false
+	assert.Equal(t, http.StatusOK, get(t, server, "/healthz", &response))
+}
+
+func TestReadyz(t *testing.T) {
+	server := newTestServer()
+
+	var apiErr error
+	server.AddCheck("ad", pingerFunc(func(context.Context) error { return nil }))
+	server.AddCheck("api", pingerFunc(func(context.Context) error { return apiErr }))
+
+	var response readinessResponse
+	assert.Equal(t, http.StatusOK, get(t, server, "/readyz", &response))
+	assert.Equal(t, statusReady, response.Status)
+	assert.Equal(t, statusOK, response.Checks["ad"].Status)
+	assert.Equal(t, statusOK, response.Checks["api"].Status)
+
+	apiErr = errors.New("credenciais recusadas pela API: 401, body: token=abc123")
+	response = readinessResponse{}
+	assert.Equal(t, http.StatusServiceUnavailable, get(t, server, "/readyz", &response))
+	assert.Equal(t, statusNotReady, response.Status)
+	assert.Equal(t, statusOK, response.Checks["ad"].Status)
+	assert.Equal(t, statusError, response.Checks["api"].Status)
+	assert.NotContains(t, response.Checks["api"].Error, "abc123")
+}
+
+func TestReadyz_CheckTimeout(t *testing.T) {
+	server := newTestServer()
+
+	// Uma dependência que não responde é interrompida no prazo da verificação
+	server.AddCheck("ad", pingerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+
+	var response readinessResponse
+	assert.Equal(t, http.StatusServiceUnavailable, get(t, server, "/readyz", &response))
+	assert.Contains(t, response.Checks["ad"].Error, context.DeadlineExceeded.Error())
+}
+
+func TestStatus(t *testing.T) {
+	server := newTestServer()
+
+	var response statusResponse
+	assert.Equal(t, http.StatusOK, get(t, server, "/status", &response))
+	assert.Nil(t, response.Poll)
+	assert.Nil(t, response.LastADRoundTrip)
+
+	lastSuccess := now.Add(-10 * time.Second)
+	server.RegisterAuthentication(&fakeAuthentication{status: authentication.PollStatus{
+		Running:             true,
+		LastActivity:        now.Add(-5 * time.Second),
+		LastSuccess:         lastSuccess,
+		LastError:           "connection refused",
+		ConsecutiveFailures: 1,
+	}})
+	server.RegisterBreaker(fakeBreaker{name: "ad", state: circuitBreaker.StateClosed})
+	server.RegisterBreaker(fakeBreaker{name: "api", state: circuitBreaker.StateOpen})
+	server.metrics.ObserveLDAP("bind", 15*time.Millisecond, nil)
+
+	response = statusResponse{}
+	assert.Equal(t, http.StatusOK, get(t, server, "/status", &response))
+	assert.Equal(t, statusOK, response.Status)
+	assert.Equal(t, map[string]string{"ad": "closed", "api": "open"}, response.Breakers)
+
+	if assert.NotNil(t, response.Poll) {
+		assert.True(t, response.Poll.Running)
+		assert.True(t, lastSuccess.Equal(*response.Poll.LastSuccess))
+		assert.Equal(t, "connection refused", response.Poll.LastError)
+		assert.Equal(t, 1, response.Poll.ConsecutiveFailures)
+	}
+	if assert.NotNil(t, response.LastADRoundTrip) {
+		assert.Equal(t, "bind", response.LastADRoundTrip.Operation)
+		assert.Equal(t, metrics.OutcomeSuccess, response.LastADRoundTrip.Outcome)
+		assert.Equal(t, 15.0, response.LastADRoundTrip.DurationMs)
+	}
+}
@@ -0,0 +1,106 @@
+package adminApi
+
+import (
+	"auth-ad/src/internal/metrics"
+	"auth-ad/src/pkg/apiKeys"
+	"auth-ad/src/pkg/configs"
+	"auth-ad/src/pkg/logging"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// readHeaderTimeout é o prazo para o cliente enviar os cabeçalhos de uma requisição
+const readHeaderTimeout = 10 * time.Second
+
+// logger registra os eventos do servidor de administração
+var logger = logging.Component("adminApi")
+
+// Server é o servidor de administração do serviço, com as métricas Prometheus em /metrics, as
+// verificações de saúde em /healthz, /readyz e /status, usadas pelas probes do Kubernetes, e os
+// bloqueios da limitação de tentativas em /throttle/blocks. Deve escutar apenas na rede interna:
+// as operações de bloqueio exigem uma das chaves de config.ApiKeys e não são expostas sem elas.
+type Server struct {
+	config  *configs.AdminConfig
+	metrics *metrics.Metrics
+	keys    *apiKeys.KeySet
+	now     func() time.Time
+
+	// authentication, breakers e checks são registrados antes de Serve e lidos pelas verificações de saúde
+	authentication Authentication
+	breakers       []Breaker
+	checks         []check
+
+	// throttle é registrado antes de Serve; sem ele ou sem chaves, as rotas de bloqueio não são expostas
+	throttle Throttle
+}
+
+// NewServer cria o servidor de administração
+// Parâmetros:
+//   - config: Configurações do servidor de administração
+//   - metrics: Métricas do serviço, expostas em /metrics e lidas por /status
+//
+// Retorna:
+//   - *Server: Servidor, iniciado por Serve
+func NewServer(config *configs.AdminConfig, metrics *metrics.Metrics) *Server {
+	server := &Server{config: config, metrics: metrics, now: time.Now}
+	if len(config.ApiKeys) > 0 {
+		server.keys = apiKeys.NewKeySet(config.ApiKeys)
+	}
+
+	return server
+}
+
+// Handler retorna o handler com as rotas de administração
+// Retorna:
+//   - http.Handler: Handler do servidor
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", s.metrics.Handler())
+	mux.HandleFunc("GET /healthz", s.handleHealthz)
+	mux.HandleFunc("GET /readyz", s.handleReadyz)
+	mux.HandleFunc("GET /status", s.handleStatus)
+
+	if s.throttle != nil {
+		mux.HandleFunc("GET /throttle/blocks", s.authorize(s.handleListBlocks))
+		mux.HandleFunc("DELETE /throttle/blocks/{scope}/{key...}", s.authorize(s.handleUnblock))
+	}
+
+	return mux
+}
+
+// Serve atende as requisições recebidas em listener até o cancelamento de ctx. No cancelamento,
+// novas conexões são recusadas e as requisições em andamento têm até config.RequestTimeout
+// para terminar.
+// Parâmetros:
+//   - ctx: Contexto que encerra o servidor
+//   - listener: Listener em que o servidor escuta
+//
+// Retorna:
+//   - error: Erro em caso de falha no servidor
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	server := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+		WriteTimeout:      s.config.RequestTimeout + readHeaderTimeout,
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), s.config.RequestTimeout)
+		defer cancel()
+		done <- server.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info("Servidor de administração escutando", "addr", listener.Addr().String())
+
+	if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return <-done
+}
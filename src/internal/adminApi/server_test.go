@@ -0,0 +1,57 @@
+package adminApi
+
+import (
+	"auth-ad/src/internal/metrics"
+	"auth-ad/src/pkg/configs"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServe(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Erro ao abrir o listener: %v", err)
+	}
+
+	server := NewServer(&configs.AdminConfig{RequestTimeout: time.Second}, metrics.NewMetrics())
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(ctx, listener) }()
+
+	base := "http://" + listener.Addr().String()
+
+	// Sem reaproveitar conexões, nenhuma conexão aberta e ainda sem uso atrasa o encerramento
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+	response, err := client.Get(base + "/metrics")
+	if err != nil {
+		t.Fatalf("Erro na requisição: %v", err)
+	}
+	body, _ := io.ReadAll(response.Body)
+	response.Body.Close()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Contains(t, string(body), "auth_ad_build_info")
+
+	response, err = client.Post(base+"/metrics", "text/plain", nil)
+	if err != nil {
+		t.Fatalf("Erro na requisição: %v", err)
+	}
+	response.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, response.StatusCode)
+
+	response, err = client.Get(base + "/outra")
+	if err != nil {
+		t.Fatalf("Erro na requisição: %v", err)
+	}
+	response.Body.Close()
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+
+	cancel()
+	assert.NoError(t, <-done)
+}
@@ -0,0 +1,96 @@
+package adminApi
+
+import (
+	"auth-ad/src/pkg/throttle"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Throttle é a limitação de tentativas de autenticação cujos bloqueios são exibidos e removidos
+// em /throttle/blocks
+type Throttle interface {
+	Blocks() []throttle.Block
+	Unblock(scope throttle.Scope, key string) bool
+}
+
+// blockResponse é um bloqueio ativo em /throttle/blocks
+type blockResponse struct {
+	Scope string    `json:"scope"`
+	Key   string    `json:"key"`
+	Until time.Time `json:"until"`
+}
+
+// blocksResponse é o corpo de GET /throttle/blocks
+type blocksResponse struct {
+	Blocks []blockResponse `json:"blocks"`
+}
+
+// errorResponse é o corpo das respostas de erro das operações de bloqueio
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// RegisterThrottle passa a exibir os bloqueios da limitação de tentativas em GET /throttle/blocks
+// e a removê-los em DELETE /throttle/blocks/{scope}/{key}. Deve ser chamado antes de Serve. Sem
+// config.ApiKeys as rotas não são registradas, já que permitiriam remover bloqueios sem autenticação.
+// Parâmetros:
+//   - throttle: Limitação de tentativas de autenticação
+func (s *Server) RegisterThrottle(throttle Throttle) {
+	if s.keys == nil {
+		logger.Warn("Rotas de bloqueio não expostas: ADMIN_API_KEYS não configurada")
+		return
+	}
+
+	s.throttle = throttle
+}
+
+// handleListBlocks retorna os bloqueios ativos, do que termina primeiro ao que termina por último
+func (s *Server) handleListBlocks(w http.ResponseWriter, r *http.Request) {
+	blocks := s.throttle.Blocks()
+
+	response := blocksResponse{Blocks: make([]blockResponse, 0, len(blocks))}
+	for _, block := range blocks {
+		response.Blocks = append(response.Blocks, blockResponse{
+			Scope: string(block.Scope),
+			Key:   block.Key,
+			Until: block.Until,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleUnblock remove o bloqueio de um usuário (scope user), de uma origem (scope source) ou de um
+// chamador (scope caller), respondendo 204 quando havia um bloqueio ativo e 404 caso contrário
+func (s *Server) handleUnblock(w http.ResponseWriter, r *http.Request) {
+	scope := throttle.Scope(r.PathValue("scope"))
+	if scope != throttle.ScopeUser && scope != throttle.ScopeSource && scope != throttle.ScopeCaller {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "scope deve ser user, source ou caller"})
+		return
+	}
+
+	key := r.PathValue("key")
+	if !s.throttle.Unblock(scope, key) {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "bloqueio não encontrado"})
+		return
+	}
+
+	logger.InfoContext(r.Context(), "Bloqueio removido pela administração", "scope", string(scope))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// authorize exige uma das chaves de config.ApiKeys no cabeçalho Authorization: Bearer, respondendo
+// 401 às requisições sem uma chave válida
+func (s *Server) authorize(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || !s.keys.Valid(token) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="auth-ad-admin"`)
+			writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "chave de acesso ausente ou inválida"})
+			return
+		}
+
+		next(w, r)
+	}
+}
@@ -0,0 +1,96 @@
+package adminApi
+
+import (
+	"auth-ad/src/internal/metrics"
+	"auth-ad/src/pkg/configs"
+	"auth-ad/src/pkg/throttle"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestThrottle cria uma limitação de tentativas com o usuário jdoe e a origem 2001:db8::1 bloqueados
+func newTestThrottle() *throttle.Throttle {
+	limiter := throttle.NewThrottle(configs.ThrottleConfig{
+		Window:        time.Minute,
+		BlockDuration: time.Hour,
+		User:          configs.ThrottleLimits{BlockAfter: 1},
+		Source:        configs.ThrottleLimits{BlockAfter: 1},
+	})
+	reservation, _ := limiter.Reserve("jdoe", "2001:db8::1", "")
+	reservation.Failure()
+
+	return limiter
+}
+
+// do executa uma requisição no servidor, com a chave de acesso informada
+func do(server *Server, method, path, key string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, nil)
+	if key != "" {
+		request.Header.Set("Authorization", "Bearer "+key)
+	}
+
+	recorder := httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+// getBlocks lê os bloqueios ativos com a chave de acesso informada
+func getBlocks(t *testing.T, server *Server, key string) blocksResponse {
+	t.Helper()
+
+	recorder := do(server, http.MethodGet, "/throttle/blocks", key)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var response blocksResponse
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+
+	return response
+}
+
+func TestThrottleBlocks(t *testing.T) {
+	server := NewServer(&configs.AdminConfig{ApiKeys: []string{"chave"}}, metrics.NewMetrics())
+
+	// Sem a limitação registrada, as rotas não existem
+	assert.Equal(t, http.StatusNotFound, do(server, http.MethodGet, "/throttle/blocks", "chave").Code)
+
+	server.RegisterThrottle(newTestThrottle())
+
+	response := getBlocks(t, server, "chave")
+	if assert.Len(t, response.Blocks, 2) {
+		assert.ElementsMatch(t, []string{"jdoe", "2001:db8::1"}, []string{response.Blocks[0].Key, response.Blocks[1].Key})
+	}
+
+	assert.Equal(t, http.StatusNoContent, do(server, http.MethodDelete, "/throttle/blocks/user/JDoe", "chave").Code)
+	assert.Equal(t, http.StatusNotFound, do(server, http.MethodDelete, "/throttle/blocks/user/jdoe", "chave").Code)
+	assert.Equal(t, http.StatusBadRequest, do(server, http.MethodDelete, "/throttle/blocks/group/jdoe", "chave").Code)
+	assert.Equal(t, http.StatusNoContent, do(server, http.MethodDelete, "/throttle/blocks/source/2001:db8::1", "chave").Code)
+
+	assert.Empty(t, getBlocks(t, server, "chave").Blocks)
+}
+
+func TestThrottleBlocks_WithoutApiKeys(t *testing.T) {
+	server := newTestServer()
+	server.RegisterThrottle(newTestThrottle())
+
+	// Sem chaves configuradas, as rotas de bloqueio não são expostas
+	assert.Equal(t, http.StatusNotFound, do(server, http.MethodGet, "/throttle/blocks", "").Code)
+	assert.Equal(t, http.StatusNotFound, do(server, http.MethodDelete, "/throttle/blocks/user/jdoe", "").Code)
+}
+
+func TestThrottleBlocks_ApiKeys(t *testing.T) {
+	server := NewServer(&configs.AdminConfig{ApiKeys: []string{"chave"}}, metrics.NewMetrics())
+	server.RegisterThrottle(newTestThrottle())
+
+	assert.Equal(t, http.StatusUnauthorized, do(server, http.MethodGet, "/throttle/blocks", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, do(server, http.MethodDelete, "/throttle/blocks/user/jdoe", "errada").Code)
+	assert.Equal(t, http.StatusNoContent, do(server, http.MethodDelete, "/throttle/blocks/user/jdoe", "chave").Code)
+
+	// As métricas e as verificações de saúde continuam liberadas para o Prometheus e as probes
+	assert.Equal(t, http.StatusOK, do(server, http.MethodGet, "/healthz", "").Code)
+}
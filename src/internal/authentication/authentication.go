@@ -3,69 +3,540 @@ package authentication
 import (
 	"auth-ad/src/internal/interfaces"
 	"auth-ad/src/internal/models"
+	"auth-ad/src/pkg/configs"
+	"auth-ad/src/pkg/logging"
+	"auth-ad/src/pkg/requestContext"
+	"auth-ad/src/pkg/tracing"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// minBackoff é a espera inicial após uma falha sistêmica
+	minBackoff = 1 * time.Second
+	// maxBackoff é a espera máxima após falhas sistêmicas consecutivas
+	maxBackoff = 30 * time.Second
+	// tracerName identifica os spans do processamento das requisições
+	tracerName = "auth-ad/authentication"
 )
 
+// logger registra os eventos do processamento das requisições
+var logger = logging.Component("authentication")
+
 type Authentication struct {
 	adService  interfaces.IActiveDirectoryService
 	apiService interfaces.IApiService
+	config     *configs.AuthenticationConfig
+
+	// queue liga a consulta de requisições aos workers
+	queue chan models.AuthRequest
+	// interval controla o intervalo adaptativo entre as consultas
+	interval *pollInterval
+	// pollBackoff controla a espera após falhas na consulta de requisições
+	pollBackoff backoff
+	// processBackoff controla a espera após falhas sistêmicas no processamento
+	processBackoff backoff
+
+	mu       sync.Mutex
+	inFlight map[string]bool
+
+	// seen guarda as respostas já calculadas, reaproveitadas quando a API devolve a mesma requisição
+	seen *seenSet
+	// received, skipped e replayed contam as requisições recebidas e as duplicatas
+	received atomic.Uint64
+	skipped  atomic.Uint64
+	replayed atomic.Uint64
+	// outcomes conta os resultados das autenticações processadas
+	outcomes outcomeCounter
+	// polls acompanha o laço de consultas, lido pelas verificações de saúde
+	polls pollTracker
+}
+
+// DedupStats reúne os contadores de deduplicação das requisições
+type DedupStats struct {
+	Received uint64 // Requisições recebidas nas consultas à API
+	Skipped  uint64 // Duplicatas ignoradas por ainda estarem na fila ou em processamento
+	Replayed uint64 // Duplicatas respondidas com a resposta já calculada, sem novo bind no AD
+	Cached   int    // Respostas guardadas para deduplicação
+}
+
+// DuplicateRate retorna a fração das requisições recebidas que eram duplicatas.
+func (s DedupStats) DuplicateRate() float64 {
+	if s.Received == 0 {
+		return 0
+	}
+
+	return float64(s.Skipped+s.Replayed) / float64(s.Received)
 }
 
 // NewAuthentication cria uma nova instância de Authentication.
 // Parâmetros:
 // - adService: serviço de autenticação.
 // - apiService: serviço de API.
+// - config: configurações do processamento das requisições.
 // Retorna: uma nova instância de Authentication.
-func NewAuthentication(adService interfaces.IActiveDirectoryService, apiService interfaces.IApiService) *Authentication {
+func NewAuthentication(adService interfaces.IActiveDirectoryService, apiService interfaces.IApiService, config *configs.AuthenticationConfig) *Authentication {
 	return &Authentication{
 		adService:  adService,
 		apiService: apiService,
+		config:     config,
+		queue:      make(chan models.AuthRequest, config.QueueSize),
+		interval:   newPollInterval(config.PollMinInterval, config.PollMaxInterval),
+		inFlight:   make(map[string]bool),
+		seen:       newSeenSet(config.DedupTTL),
 	}
 }
 
-// Start inicia o processo de autenticação.
+// Start inicia o processo de autenticação e executa até o cancelamento de ctx.
+// As requisições consultadas na API são colocadas em uma fila limitada e processadas em paralelo
+// por config.Workers workers. Com a fila cheia, a consulta aguarda os workers, limitando a carga
+// sobre o AD. Cada requisição é tratada de forma independente: erros de uma requisição
+// (credenciais inválidas, usuário inexistente) são respondidos com falha e não afetam as demais.
+// O intervalo entre consultas é adaptativo: config.PollMinInterval enquanto há requisições chegando,
+// dobrando a cada consulta vazia até config.PollMaxInterval.
+// Falhas sistêmicas (AD ou API indisponíveis) suspendem novas consultas com backoff exponencial.
+// Com o cancelamento de ctx, as consultas param e as requisições já obtidas são drenadas (ver drain).
+// Parâmetros:
+// - ctx: contexto que, ao ser cancelado, encerra o processamento.
 // Retorna: um erro caso ocorra algum problema durante a execução.
-func (a *Authentication) Start() error {
-	for {
-		requests, err := a.apiService.GetRequest()
+func (a *Authentication) Start(ctx context.Context) error {
+	// O processamento das requisições não é interrompido junto com as consultas,
+	// apenas quando o prazo de drenagem se esgota
+	workCtx, cancelWork := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelWork()
+
+	wg := a.startWorkers(workCtx)
+
+	a.polls.start(time.Now())
+	defer a.polls.stop()
+
+	for a.wait(ctx) {
+		started := time.Now()
+		received, err := a.poll(ctx)
 		if err != nil {
-			return err
+			if ctx.Err() != nil {
+				break
+			}
+
+			a.polls.failure(time.Now(), err)
+			delay := a.pollBackoff.failure()
+			logger.WarnContext(ctx, "Falha ao consultar requisições", "retry_in", delay, logging.AttrError, err)
+			continue
 		}
 
-		for _, request := range requests {
+		a.pollBackoff.success()
+		a.polls.success(time.Now())
 
-			authenticated, err := a.adService.Authenticate(request.Username, request.Password)
-			if err != nil {
-				return err
-			}
+		// O tempo em que a API segurou a consulta (long polling) conta como parte do intervalo
+		if !sleep(ctx, a.interval.next(received)-time.Since(started)) {
+			break
+		}
+	}
 
-			if !authenticated {
-				continue
-			}
+	a.drain(wg, cancelWork)
 
-			user, err := a.adService.GetUser(request.Username)
-			if err != nil {
-				return err
-			}
+	stats := a.DedupStats()
+	logger.Info("Processamento encerrado",
+		"received", stats.Received,
+		"skipped", stats.Skipped,
+		"replayed", stats.Replayed,
+		"duplicate_rate", stats.DuplicateRate())
 
-			response := models.AuthResponse{
-				RequestID: request.RequestID,
-				Success:   authenticated,
-				UserData: models.UserData{
-					Username: user.Username,
-					Email:    user.Email,
-					Groups:   user.Groups,
-				},
-			}
+	return nil
+}
 
-			err = a.apiService.SendResponse(request.RequestID, response)
-			if err != nil {
-				return err
-			}
+// QueueDepth retorna a quantidade de requisições aguardando um worker livre.
+func (a *Authentication) QueueDepth() int {
+	return len(a.queue)
+}
+
+// DedupStats retorna os contadores de deduplicação das requisições.
+func (a *Authentication) DedupStats() DedupStats {
+	return DedupStats{
+		Received: a.received.Load(),
+		Skipped:  a.skipped.Load(),
+		Replayed: a.replayed.Load(),
+		Cached:   a.seen.len(),
+	}
+}
+
+// Outcomes retorna os resultados das autenticações processadas, por motivo de falha.
+func (a *Authentication) Outcomes() OutcomeStats {
+	return a.outcomes.stats()
+}
+
+// PollStatus retorna o estado do laço de consultas, usado para identificar um laço travado.
+func (a *Authentication) PollStatus() PollStatus {
+	return a.polls.get()
+}
+
+// startWorkers inicia os workers que consomem a fila de requisições.
+// Parâmetros:
+// - ctx: contexto base das requisições processadas.
+// Retorna: um WaitGroup liberado quando a fila for fechada e todos os workers terminarem.
+func (a *Authentication) startWorkers(ctx context.Context) *sync.WaitGroup {
+	var wg sync.WaitGroup
+	for i := 0; i < a.config.Workers; i++ {
+		wg.Add(1)
+		go a.worker(ctx, &wg)
+	}
+
+	return &wg
+}
+
+// worker processa as requisições da fila até que ela seja fechada.
+func (a *Authentication) worker(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
 
-			a.adService.Unbind()
+	for request := range a.queue {
+		err := a.process(ctx, request)
+		a.release(request.RequestID)
+
+		if err != nil {
+			delay := a.processBackoff.failure()
+			logger.ErrorContext(requestContext.WithRequestID(ctx, request.RequestID), "Falha sistêmica no processamento, novas consultas suspensas",
+				"retry_in", delay, logging.AttrError, err)
+			continue
 		}
 
-		time.Sleep(1 * time.Second)
+		a.processBackoff.success()
 	}
 }
+
+// drain fecha a fila e aguarda os workers concluírem as requisições já obtidas. Caso o prazo de
+// drenagem se esgote, o processamento restante é cancelado: as operações no AD são interrompidas
+// e as requisições ainda pendentes são respondidas com directory_unavailable.
+// Parâmetros:
+// - wg: WaitGroup dos workers.
+// - cancelWork: função que cancela o contexto das requisições.
+func (a *Authentication) drain(wg *sync.WaitGroup, cancelWork context.CancelFunc) {
+	close(a.queue)
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	logger.Info("Encerrando: aguardando as requisições na fila e em processamento", "pending", a.pending())
+
+	timer := time.NewTimer(a.config.DrainTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+	case <-timer.C:
+		logger.Warn("Prazo de drenagem esgotado, cancelando as requisições restantes", "pending", a.pending())
+		cancelWork()
+		<-done
+	}
+}
+
+// wait aguarda o fim das esperas de backoff antes de uma nova consulta.
+// Retorna: false caso ctx seja cancelado durante a espera.
+func (a *Authentication) wait(ctx context.Context) bool {
+	delay := a.pollBackoff.remaining()
+	if processDelay := a.processBackoff.remaining(); processDelay > delay {
+		delay = processDelay
+	}
+
+	return sleep(ctx, delay)
+}
+
+// sleep aguarda o tempo informado ou o cancelamento de ctx.
+// Retorna: false caso ctx seja cancelado.
+func sleep(ctx context.Context, delay time.Duration) bool {
+	if delay <= 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// poll busca as requisições pendentes e as coloca na fila. Requisições que ainda estão na fila
+// ou em processamento não são enfileiradas novamente; as já processadas são respondidas pelos
+// workers com a resposta guardada (ver process).
+// Parâmetros:
+// - ctx: contexto da consulta.
+// Retorna: a quantidade de requisições obtidas e um erro caso a consulta à API falhe.
+func (a *Authentication) poll(ctx context.Context) (received int, err error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "authentication.poll")
+	defer func() {
+		span.SetAttributes(attribute.Int("auth.requests_received", received))
+		tracing.End(span, err)
+	}()
+
+	requests, err := a.apiService.GetRequest(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, request := range requests {
+		a.received.Add(1)
+		if !a.acquire(request.RequestID) {
+			a.skipped.Add(1)
+			continue
+		}
+
+		select {
+		case a.queue <- request:
+		case <-ctx.Done():
+			// As requisições não enfileiradas são devolvidas à API para serem obtidas novamente
+			a.release(request.RequestID)
+			a.giveBack(ctx, requests[i:])
+			return i, ctx.Err()
+		}
+	}
+
+	return len(requests), nil
+}
+
+// giveBack devolve à API requisições reservadas que não serão processadas. Requisições que
+// já estão na fila ou em processamento são mantidas.
+func (a *Authentication) giveBack(ctx context.Context, requests []models.AuthRequest) {
+	for _, request := range requests {
+		if a.isInFlight(request.RequestID) {
+			continue
+		}
+		if err := a.apiService.Release(context.WithoutCancel(ctx), request.RequestID); err != nil {
+			logger.WarnContext(requestContext.WithRequestID(ctx, request.RequestID), "Erro ao devolver a requisição", logging.AttrError, err)
+		}
+	}
+}
+
+// acquire marca uma requisição como em processamento.
+// Retorna: false caso a requisição já esteja na fila ou em processamento.
+func (a *Authentication) acquire(requestID string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.inFlight[requestID] {
+		return false
+	}
+	a.inFlight[requestID] = true
+
+	return true
+}
+
+// release remove a marca de processamento de uma requisição.
+func (a *Authentication) release(requestID string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.inFlight, requestID)
+}
+
+// isInFlight indica se uma requisição está na fila ou em processamento.
+func (a *Authentication) isInFlight(requestID string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.inFlight[requestID]
+}
+
+// pending retorna a quantidade de requisições na fila ou em processamento.
+func (a *Authentication) pending() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return len(a.inFlight)
+}
+
+// process autentica uma requisição e envia a resposta para a API. Toda requisição é
+// respondida, com sucesso ou com o motivo da falha, inclusive quando o prazo da
+// autenticação se esgota. Uma requisição já processada dentro de config.DedupTTL com as
+// mesmas credenciais é respondida novamente com a resposta guardada, sem um novo bind no
+// AD, evitando que tentativas repetidas contribuam para o bloqueio da conta.
+// Parâmetros:
+// - ctx: contexto base do processamento.
+// - request: requisição de autenticação.
+// Retorna: um erro caso ocorra uma falha sistêmica.
+func (a *Authentication) process(ctx context.Context, request models.AuthRequest) (err error) {
+	ctx, cancel := a.requestContext(ctx, request)
+	defer cancel()
+
+	// Cada requisição é a raiz de um trace, com as etapas bind, search e respond
+	ctx, span := startSpan(ctx, "authentication.process", request)
+	defer func() { tracing.End(span, err) }()
+
+	response, ok, reused := a.seen.get(request)
+	if ok {
+		a.replayed.Add(1)
+		span.SetAttributes(attribute.Bool("auth.replayed", true))
+		logger.InfoContext(ctx, "Requisição já processada, reenviando a resposta anterior")
+		return a.respond(ctx, request, response)
+	}
+	if reused {
+		// A resposta guardada não vale para outras credenciais: a requisição é autenticada novamente
+		logger.WarnContext(ctx, "ID de requisição reutilizado com outras credenciais")
+	}
+
+	defer a.adService.Unbind(ctx)
+
+	response, err = a.authenticate(ctx, request)
+	a.outcomes.add(response)
+	span.SetAttributes(attribute.Bool("auth.success", response.Success))
+	if response.Reason != "" {
+		span.SetAttributes(attribute.String("auth.reason", string(response.Reason)))
+	}
+	if err == nil {
+		// Falhas sistêmicas não são guardadas: a requisição é autenticada novamente quando devolvida
+		a.seen.add(request, response)
+	}
+
+	if sendErr := a.respond(ctx, request, response); sendErr != nil {
+		if errors.Is(sendErr, models.ErrLeaseLost) {
+			// Outra instância assumiu a requisição e a responderá
+			logger.InfoContext(ctx, "Resposta descartada", logging.AttrError, sendErr)
+			return err
+		}
+		return sendErr
+	}
+
+	return err
+}
+
+// respond envia a resposta de uma requisição à API. O envio não é interrompido pelo prazo da
+// requisição, para que toda requisição seja respondida.
+// Parâmetros:
+// - ctx: contexto da requisição.
+// - request: requisição de autenticação.
+// - response: resposta a ser enviada.
+// Retorna: um erro caso o envio falhe.
+func (a *Authentication) respond(ctx context.Context, request models.AuthRequest, response models.AuthResponse) error {
+	ctx, span := startSpan(context.WithoutCancel(ctx), "authentication.respond", request)
+	err := a.apiService.SendResponse(ctx, request.RequestID, response)
+	if errors.Is(err, models.ErrLeaseLost) {
+		// Outra instância assumiu a requisição: não é uma falha do envio
+		span.SetAttributes(attribute.Bool("auth.lease_lost", true))
+		span.End()
+		return err
+	}
+	tracing.End(span, err)
+
+	return err
+}
+
+// requestContext cria o contexto de uma requisição, com seu ID, tenant e o prazo configurado. Na
+// limitação de tentativas, a origem é o endereço do usuário final repassado no campo source, e o
+// chamador é o tenant, identificado pela API.
+// Parâmetros:
+// - ctx: contexto base do processamento.
+// - request: requisição de autenticação.
+// Retorna: o contexto da requisição e a função que o cancela.
+func (a *Authentication) requestContext(ctx context.Context, request models.AuthRequest) (context.Context, context.CancelFunc) {
+	ctx = requestContext.WithRequestID(ctx, request.RequestID)
+	if request.Tenant != "" {
+		ctx = requestContext.WithTenant(ctx, request.Tenant)
+		ctx = requestContext.WithCaller(ctx, "tenant:"+request.Tenant)
+	}
+	if request.Source != "" {
+		ctx = requestContext.WithSource(ctx, request.Source)
+	}
+
+	if a.config.RequestTimeout > 0 {
+		return context.WithTimeout(ctx, a.config.RequestTimeout)
+	}
+
+	return context.WithCancel(ctx)
+}
+
+// authenticate valida as credenciais da requisição e monta a resposta correspondente.
+// Parâmetros:
+// - ctx: contexto da requisição.
+// - request: requisição de autenticação.
+// Retorna: a resposta a ser enviada e, em caso de falha sistêmica, o erro ocorrido.
+func (a *Authentication) authenticate(ctx context.Context, request models.AuthRequest) (models.AuthResponse, error) {
+	bindCtx, span := startSpan(ctx, "authentication.bind", request)
+	authenticated, err := a.adService.Authenticate(bindCtx, request.Username, request.Password)
+	if err == nil && !authenticated {
+		err = models.ErrInvalidCredentials
+	}
+	endStage(span, err)
+	if err != nil {
+		return failureResponse(ctx, request, err)
+	}
+
+	searchCtx, span := startSpan(ctx, "authentication.search", request)
+	user, err := a.adService.GetUser(searchCtx, request.Username)
+	endStage(span, err)
+	if err != nil {
+		return failureResponse(ctx, request, err)
+	}
+
+	return models.AuthResponse{
+		RequestID: request.RequestID,
+		Success:   authenticated,
+		UserData: models.UserData{
+			Username: user.Username,
+			Email:    user.Email,
+			Groups:   user.Groups,
+		},
+	}, nil
+}
+
+// startSpan inicia o span de uma etapa do processamento, identificado pelo ID da requisição.
+// Parâmetros:
+// - ctx: contexto da etapa.
+// - name: nome do span.
+// - request: requisição de autenticação.
+// Retorna: o contexto com o span e o span iniciado.
+func startSpan(ctx context.Context, name string, request models.AuthRequest) (context.Context, trace.Span) {
+	attributes := []attribute.KeyValue{tracing.AttrRequestID.String(request.RequestID)}
+	if request.Tenant != "" {
+		attributes = append(attributes, tracing.AttrTenant.String(request.Tenant))
+	}
+
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// endStage encerra o span de uma etapa no AD. Credenciais recusadas e usuários inexistentes são
+// respostas normais do AD: o motivo é registrado sem marcar o span com erro.
+// Parâmetros:
+// - span: span da etapa.
+// - err: erro retornado pelo AD.
+func endStage(span trace.Span, err error) {
+	if err != nil && models.IsRequestError(err) {
+		span.SetAttributes(attribute.String("auth.reason", string(models.ReasonFor(err))))
+		err = nil
+	}
+
+	tracing.End(span, err)
+}
+
+// failureResponse monta a resposta de falha com o motivo correspondente ao erro.
+// Parâmetros:
+// - ctx: contexto da requisição, usado na correlação dos logs.
+// - request: requisição de autenticação.
+// - err: erro ocorrido no processamento.
+// Retorna: a resposta de falha e, caso seja uma falha sistêmica, o erro original.
+func failureResponse(ctx context.Context, request models.AuthRequest, err error) (models.AuthResponse, error) {
+	response := models.AuthResponse{
+		RequestID: request.RequestID,
+		Success:   false,
+		Reason:    models.ReasonFor(err),
+	}
+
+	if !models.IsRequestError(err) {
+		return response, err
+	}
+
+	logger.InfoContext(ctx, "Falha na autenticação", "reason", response.Reason, logging.AttrError, err)
+
+	return response, nil
+}
@@ -0,0 +1,548 @@
+package authentication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"auth-ad/src/internal/interfaces/mocks"
+	"auth-ad/src/internal/models"
+	"auth-ad/src/pkg/configs"
+	"auth-ad/src/pkg/requestContext"
+	"auth-ad/src/pkg/tracing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestAuthentication() (*Authentication, *mocks.IActiveDirectoryService, *mocks.IApiService) {
+	adService := new(mocks.IActiveDirectoryService)
+	apiService := new(mocks.IApiService)
+	adService.On("Unbind", mock.Anything).Return(nil)
+
+	return NewAuthentication(adService, apiService, &configs.AuthenticationConfig{
+		Workers:         2,
+		QueueSize:       10,
+		RequestTimeout:  time.Minute,
+		DrainTimeout:    time.Minute,
+		PollMinInterval: time.Millisecond,
+		PollMaxInterval: 10 * time.Millisecond,
+	}), adService, apiService
+}
+
+// runOnce executa uma consulta e aguarda os workers processarem toda a fila
+func runOnce(authentication *Authentication) error {
+	wg := authentication.startWorkers(context.Background())
+	_, err := authentication.poll(context.Background())
+	close(authentication.queue)
+	wg.Wait()
+
+	return err
+}
+
+func TestPoll_SendsSuccessResponse(t *testing.T) {
+	authentication, adService, apiService := newTestAuthentication()
+
+	apiService.On("GetRequest", mock.Anything).Return([]models.AuthRequest{{RequestID: "1", Username: "user", Password: "pass"}}, nil)
+	adService.On("Authenticate", mock.Anything, "user", "pass").Return(true, nil)
+	adService.On("GetUser", mock.Anything, "user").Return(models.UserData{Username: "user", Email: "user@example.com", Groups: []string{"Vendas"}}, nil)
+	apiService.On("SendResponse", mock.Anything, "1", models.AuthResponse{
+		RequestID: "1",
+		Success:   true,
+		UserData:  models.UserData{Username: "user", Email: "user@example.com", Groups: []string{"Vendas"}},
+	}).Return(nil)
+
+	err := runOnce(authentication)
+	assert.NoError(t, err)
+	apiService.AssertExpectations(t)
+}
+
+func TestPoll_RequestErrorsDoNotStopProcessing(t *testing.T) {
+	authentication, adService, apiService := newTestAuthentication()
+
+	apiService.On("GetRequest", mock.Anything).Return([]models.AuthRequest{
+		{RequestID: "1", Username: "wrong", Password: "pass"},
+		{RequestID: "2", Username: "ghost", Password: "pass"},
+		{RequestID: "3", Username: "user", Password: "pass"},
+	}, nil)
+	adService.On("Authenticate", mock.Anything, "wrong", "pass").Return(false, fmt.Errorf("erro na autenticação: %w", models.ErrInvalidCredentials))
+	adService.On("Authenticate", mock.Anything, "ghost", "pass").Return(true, nil)
+	adService.On("GetUser", mock.Anything, "ghost").Return(models.UserData{}, models.ErrUserNotFound)
+	adService.On("Authenticate", mock.Anything, "user", "pass").Return(true, nil)
+	adService.On("GetUser", mock.Anything, "user").Return(models.UserData{Username: "user"}, nil)
+	apiService.On("SendResponse", mock.Anything, "1", models.AuthResponse{RequestID: "1", Reason: models.ReasonInvalidCredentials}).Return(nil)
+	apiService.On("SendResponse", mock.Anything, "2", models.AuthResponse{RequestID: "2", Reason: models.ReasonUserNotFound}).Return(nil)
+	apiService.On("SendResponse", mock.Anything, "3", models.AuthResponse{RequestID: "3", Success: true, UserData: models.UserData{Username: "user"}}).Return(nil)
+
+	err := runOnce(authentication)
+	assert.NoError(t, err)
+	apiService.AssertExpectations(t)
+}
+
+func TestPoll_SystemicErrorSuspendsPolling(t *testing.T) {
+	authentication, adService, apiService := newTestAuthentication()
+
+	apiService.On("GetRequest", mock.Anything).Return([]models.AuthRequest{
+		{RequestID: "1", Username: "user", Password: "pass"},
+		{RequestID: "2", Username: "other", Password: "pass"},
+	}, nil)
+	adService.On("Authenticate", mock.Anything, "user", "pass").Return(false, fmt.Errorf("erro ao conectar ao AD: %w", models.ErrDirectoryUnavailable))
+	adService.On("Authenticate", mock.Anything, "other", "pass").Return(false, fmt.Errorf("erro ao conectar ao AD: %w", models.ErrDirectoryUnavailable))
+	apiService.On("SendResponse", mock.Anything, "1", models.AuthResponse{RequestID: "1", Reason: models.ReasonDirectoryUnavailable}).Return(nil)
+	apiService.On("SendResponse", mock.Anything, "2", models.AuthResponse{RequestID: "2", Reason: models.ReasonDirectoryUnavailable}).Return(nil)
+
+	err := runOnce(authentication)
+	assert.NoError(t, err)
+	assert.Greater(t, authentication.processBackoff.remaining(), time.Duration(0))
+	apiService.AssertExpectations(t)
+}
+
+func TestPoll_AnswersUnauthenticatedRequests(t *testing.T) {
+	authentication, adService, apiService := newTestAuthentication()
+
+	apiService.On("GetRequest", mock.Anything).Return([]models.AuthRequest{{RequestID: "1", Username: "user", Password: "pass"}}, nil)
+	adService.On("Authenticate", mock.Anything, "user", "pass").Return(false, nil)
+	apiService.On("SendResponse", mock.Anything, "1", models.AuthResponse{RequestID: "1", Reason: models.ReasonInvalidCredentials}).Return(nil)
+
+	err := runOnce(authentication)
+	assert.NoError(t, err)
+	apiService.AssertExpectations(t)
+}
+
+func TestPoll_ReasonFromAuthError(t *testing.T) {
+	authentication, adService, apiService := newTestAuthentication()
+
+	apiService.On("GetRequest", mock.Anything).Return([]models.AuthRequest{{RequestID: "1", Username: "user", Password: "pass"}}, nil)
+	adService.On("Authenticate", mock.Anything, "user", "pass").Return(false, &models.AuthError{Reason: models.ReasonAccountLocked, Err: models.ErrInvalidCredentials})
+	apiService.On("SendResponse", mock.Anything, "1", models.AuthResponse{RequestID: "1", Reason: models.ReasonAccountLocked}).Return(nil)
+
+	err := runOnce(authentication)
+	assert.NoError(t, err)
+	apiService.AssertExpectations(t)
+}
+
+func TestPoll_SendResponseError(t *testing.T) {
+	authentication, adService, apiService := newTestAuthentication()
+
+	apiService.On("GetRequest", mock.Anything).Return([]models.AuthRequest{{RequestID: "1", Username: "user", Password: "pass"}}, nil)
+	adService.On("Authenticate", mock.Anything, "user", "pass").Return(false, models.ErrInvalidCredentials)
+	apiService.On("SendResponse", mock.Anything, "1", models.AuthResponse{RequestID: "1", Reason: models.ReasonInvalidCredentials}).Return(errors.New("503"))
+
+	err := runOnce(authentication)
+	assert.NoError(t, err)
+	assert.Greater(t, authentication.processBackoff.remaining(), time.Duration(0))
+}
+
+func TestPoll_ApiError(t *testing.T) {
+	authentication, _, apiService := newTestAuthentication()
+
+	apiService.On("GetRequest", mock.Anything).Return([]models.AuthRequest(nil), errors.New("connection refused"))
+
+	err := runOnce(authentication)
+	assert.Error(t, err)
+}
+
+func TestNextBackoff(t *testing.T) {
+	assert.Equal(t, 2*time.Second, nextBackoff(time.Second))
+	assert.Equal(t, maxBackoff, nextBackoff(20*time.Second))
+	assert.Equal(t, maxBackoff, nextBackoff(maxBackoff))
+}
+
+func TestPoll_SkipsRequestsInFlight(t *testing.T) {
+	authentication, _, apiService := newTestAuthentication()
+
+	apiService.On("GetRequest", mock.Anything).Return([]models.AuthRequest{
+		{RequestID: "1", Username: "user", Password: "pass"},
+		{RequestID: "1", Username: "user", Password: "pass"},
+		{RequestID: "2", Username: "other", Password: "pass"},
+	}, nil)
+
+	received, err := authentication.poll(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, received)
+	assert.Equal(t, 2, authentication.QueueDepth())
+	assert.Equal(t, DedupStats{Received: 3, Skipped: 1}, authentication.DedupStats())
+}
+
+func TestPoll_GivesBackRequestsOnShutdown(t *testing.T) {
+	authentication, _, apiService := newTestAuthentication()
+	// Sem workers e sem espaço na fila, nenhuma requisição é enfileirada antes do encerramento
+	authentication.queue = make(chan models.AuthRequest)
+	authentication.acquire("3")
+	ctx, cancel := context.WithCancel(context.Background())
+
+	apiService.On("GetRequest", mock.Anything).Run(func(mock.Arguments) {
+		cancel()
+	}).Return([]models.AuthRequest{{RequestID: "1"}, {RequestID: "2"}, {RequestID: "3"}}, nil)
+	apiService.On("Release", mock.Anything, "1").Return(nil)
+	apiService.On("Release", mock.Anything, "2").Return(nil)
+
+	// As requisições não enfileiradas são devolvidas, exceto a que já está em processamento
+	_, err := authentication.poll(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	apiService.AssertExpectations(t)
+	apiService.AssertNotCalled(t, "Release", mock.Anything, "3")
+}
+
+func TestProcess_LeaseLostIsNotSystemic(t *testing.T) {
+	authentication, adService, apiService := newTestAuthentication()
+
+	adService.On("Authenticate", mock.Anything, "user", "pass").Return(false, nil)
+	apiService.On("SendResponse", mock.Anything, "1", mock.Anything).Return(models.ErrLeaseLost)
+
+	err := authentication.process(context.Background(), models.AuthRequest{RequestID: "1", Username: "user", Password: "pass"})
+	assert.NoError(t, err)
+}
+
+func TestProcess_AnswersDuplicatesFromCache(t *testing.T) {
+	authentication, adService, apiService := newTestAuthentication()
+	authentication.seen = newSeenSet(time.Minute)
+	request := models.AuthRequest{RequestID: "1", Username: "user", Password: "wrong"}
+	response := models.AuthResponse{RequestID: "1", Reason: models.ReasonInvalidCredentials}
+
+	adService.On("Authenticate", mock.Anything, "user", "wrong").Return(false, models.ErrInvalidCredentials).Once()
+	apiService.On("SendResponse", mock.Anything, "1", response).Return(nil).Twice()
+
+	// A mesma requisição devolvida pela API é respondida sem um novo bind no AD
+	assert.NoError(t, authentication.process(context.Background(), request))
+	assert.NoError(t, authentication.process(context.Background(), request))
+
+	adService.AssertNumberOfCalls(t, "Authenticate", 1)
+	apiService.AssertExpectations(t)
+	assert.Equal(t, uint64(1), authentication.DedupStats().Replayed)
+
+	// A resposta reenviada não é contada como uma nova autenticação
+	assert.Equal(t, OutcomeStats{Failed: map[models.FailureReason]uint64{models.ReasonInvalidCredentials: 1}}, authentication.Outcomes())
+}
+
+func TestProcess_ReauthenticatesReusedRequestID(t *testing.T) {
+	authentication, adService, apiService := newTestAuthentication()
+	authentication.seen = newSeenSet(time.Minute)
+	failed := models.AuthResponse{RequestID: "1", Reason: models.ReasonInvalidCredentials}
+	succeeded := models.AuthResponse{RequestID: "1", Success: true, UserData: models.UserData{Username: "user"}}
+
+	adService.On("Authenticate", mock.Anything, "user", "wrong").Return(false, models.ErrInvalidCredentials).Once()
+	adService.On("Authenticate", mock.Anything, "user", "pass").Return(true, nil).Once()
+	adService.On("Authenticate", mock.Anything, "other", "pass").Return(false, models.ErrInvalidCredentials).Once()
+	adService.On("GetUser", mock.Anything, "user").Return(models.UserData{Username: "user"}, nil)
+	apiService.On("SendResponse", mock.Anything, "1", failed).Return(nil).Twice()
+	apiService.On("SendResponse", mock.Anything, "1", succeeded).Return(nil).Once()
+
+	// O mesmo ID com outra senha ou outro usuário não recebe a resposta guardada
+	assert.NoError(t, authentication.process(context.Background(), models.AuthRequest{RequestID: "1", Username: "user", Password: "wrong"}))
+	assert.NoError(t, authentication.process(context.Background(), models.AuthRequest{RequestID: "1", Username: "user", Password: "pass"}))
+	assert.NoError(t, authentication.process(context.Background(), models.AuthRequest{RequestID: "1", Username: "other", Password: "pass"}))
+
+	adService.AssertNumberOfCalls(t, "Authenticate", 3)
+	apiService.AssertExpectations(t)
+	assert.Equal(t, uint64(0), authentication.DedupStats().Replayed)
+}
+
+func TestProcess_DoesNotCacheSystemicFailures(t *testing.T) {
+	authentication, adService, apiService := newTestAuthentication()
+	authentication.seen = newSeenSet(time.Minute)
+	request := models.AuthRequest{RequestID: "1", Username: "user", Password: "pass"}
+
+	adService.On("Authenticate", mock.Anything, "user", "pass").Return(false, models.ErrDirectoryUnavailable).Once()
+	adService.On("Authenticate", mock.Anything, "user", "pass").Return(true, nil).Once()
+	adService.On("GetUser", mock.Anything, "user").Return(models.UserData{Username: "user"}, nil)
+	apiService.On("SendResponse", mock.Anything, "1", mock.Anything).Return(nil)
+
+	assert.Error(t, authentication.process(context.Background(), request))
+	assert.NoError(t, authentication.process(context.Background(), request))
+
+	adService.AssertNumberOfCalls(t, "Authenticate", 2)
+	assert.Equal(t, uint64(0), authentication.DedupStats().Replayed)
+	assert.Equal(t, OutcomeStats{
+		Succeeded: 1,
+		Failed:    map[models.FailureReason]uint64{models.ReasonDirectoryUnavailable: 1},
+	}, authentication.Outcomes())
+}
+
+func TestSeenSet_Expires(t *testing.T) {
+	now := time.Now()
+	seen := newSeenSet(time.Minute)
+	seen.now = func() time.Time { return now }
+
+	request := models.AuthRequest{RequestID: "1", Username: "user", Password: "pass"}
+	seen.add(request, models.AuthResponse{RequestID: "1"})
+	_, ok, _ := seen.get(request)
+	assert.True(t, ok)
+
+	// Outras credenciais com o mesmo ID não reaproveitam a resposta
+	_, ok, reused := seen.get(models.AuthRequest{RequestID: "1", Username: "user", Password: "outra"})
+	assert.False(t, ok)
+	assert.True(t, reused)
+
+	// Após o ttl, a resposta deixa de ser reaproveitada e é descartada na próxima inclusão
+	now = now.Add(time.Minute)
+	_, ok, reused = seen.get(request)
+	assert.False(t, ok)
+	assert.False(t, reused)
+
+	seen.add(models.AuthRequest{RequestID: "2"}, models.AuthResponse{RequestID: "2"})
+	assert.Equal(t, 1, seen.len())
+
+	// Com ttl 0, nenhuma resposta é guardada
+	disabled := newSeenSet(0)
+	disabled.add(request, models.AuthResponse{RequestID: "1"})
+	_, ok, _ = disabled.get(request)
+	assert.False(t, ok)
+}
+
+func TestDedupStats_DuplicateRate(t *testing.T) {
+	assert.Equal(t, 0.0, DedupStats{}.DuplicateRate())
+	assert.Equal(t, 0.5, DedupStats{Received: 4, Skipped: 1, Replayed: 1}.DuplicateRate())
+}
+
+func TestWorkers_ProcessConcurrently(t *testing.T) {
+	authentication, adService, apiService := newTestAuthentication()
+
+	// Cada autenticação só termina quando a outra também começou, o que exige dois workers
+	started := make(chan struct{}, 2)
+	waitBoth := func(mock.Arguments) {
+		started <- struct{}{}
+		for len(started) < 2 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	apiService.On("GetRequest", mock.Anything).Return([]models.AuthRequest{
+		{RequestID: "1", Username: "user", Password: "pass"},
+		{RequestID: "2", Username: "other", Password: "pass"},
+	}, nil)
+	adService.On("Authenticate", mock.Anything, "user", "pass").Run(waitBoth).Return(false, nil)
+	adService.On("Authenticate", mock.Anything, "other", "pass").Run(waitBoth).Return(false, nil)
+	apiService.On("SendResponse", mock.Anything, "1", models.AuthResponse{RequestID: "1", Reason: models.ReasonInvalidCredentials}).Return(nil)
+	apiService.On("SendResponse", mock.Anything, "2", models.AuthResponse{RequestID: "2", Reason: models.ReasonInvalidCredentials}).Return(nil)
+
+	err := runOnce(authentication)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, authentication.QueueDepth())
+	apiService.AssertExpectations(t)
+}
+
+func TestPollInterval(t *testing.T) {
+	interval := newPollInterval(100*time.Millisecond, time.Second)
+
+	// Consultas vazias dobram o intervalo até o máximo
+	assert.Equal(t, 200*time.Millisecond, interval.next(0))
+	assert.Equal(t, 400*time.Millisecond, interval.next(0))
+	assert.Equal(t, 800*time.Millisecond, interval.next(0))
+	assert.Equal(t, time.Second, interval.next(0))
+	assert.Equal(t, time.Second, interval.next(0))
+
+	// Com requisições chegando, o intervalo volta ao mínimo
+	assert.Equal(t, 100*time.Millisecond, interval.next(3))
+	assert.Equal(t, 200*time.Millisecond, interval.next(0))
+}
+
+func TestBackoff(t *testing.T) {
+	var b backoff
+
+	assert.Equal(t, minBackoff, b.failure())
+	assert.Equal(t, 2*minBackoff, b.failure())
+	assert.Greater(t, b.remaining(), time.Duration(0))
+
+	b.success()
+	assert.LessOrEqual(t, b.remaining(), time.Duration(0))
+	assert.Equal(t, minBackoff, b.failure())
+}
+
+func TestProcess_RequestContext(t *testing.T) {
+	authentication, adService, apiService := newTestAuthentication()
+
+	request := models.AuthRequest{RequestID: "1", Username: "user", Password: "pass", Tenant: "loja-01", Source: "10.0.0.1"}
+	adService.On("Authenticate", mock.Anything, "user", "pass").Run(func(args mock.Arguments) {
+		ctx := args.Get(0).(context.Context)
+		assert.Equal(t, "1", requestContext.RequestID(ctx))
+		assert.Equal(t, "loja-01", requestContext.Tenant(ctx))
+		assert.Equal(t, "10.0.0.1", requestContext.Source(ctx))
+		assert.Equal(t, "tenant:loja-01", requestContext.Caller(ctx))
+
+		_, hasDeadline := ctx.Deadline()
+		assert.True(t, hasDeadline)
+	}).Return(false, nil)
+	apiService.On("SendResponse", mock.Anything, "1", models.AuthResponse{RequestID: "1", Reason: models.ReasonInvalidCredentials}).Return(nil)
+
+	err := authentication.process(context.Background(), request)
+	assert.NoError(t, err)
+	apiService.AssertExpectations(t)
+}
+
+func TestProcess_AnswersAfterDeadline(t *testing.T) {
+	authentication, adService, apiService := newTestAuthentication()
+	authentication.config.RequestTimeout = 10 * time.Millisecond
+
+	adService.On("Authenticate", mock.Anything, "user", "pass").Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	}).Return(false, fmt.Errorf("%w: %w", models.ErrDirectoryUnavailable, context.DeadlineExceeded))
+	apiService.On("SendResponse", mock.Anything, "1", models.AuthResponse{RequestID: "1", Reason: models.ReasonDirectoryUnavailable}).Run(func(args mock.Arguments) {
+		assert.NoError(t, args.Get(0).(context.Context).Err())
+	}).Return(nil)
+
+	err := authentication.process(context.Background(), models.AuthRequest{RequestID: "1", Username: "user", Password: "pass"})
+	assert.ErrorIs(t, err, models.ErrDirectoryUnavailable)
+	apiService.AssertExpectations(t)
+}
+
+func TestStart_DrainsRequestsOnShutdown(t *testing.T) {
+	authentication, adService, apiService := newTestAuthentication()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	apiService.On("GetRequest", mock.Anything).Return([]models.AuthRequest{{RequestID: "1", Username: "user", Password: "pass"}}, nil).Once()
+	apiService.On("GetRequest", mock.Anything).Return([]models.AuthRequest(nil), nil)
+	adService.On("Authenticate", mock.Anything, "user", "pass").Run(func(mock.Arguments) {
+		// O encerramento chega com a requisição em andamento
+		cancel()
+		time.Sleep(10 * time.Millisecond)
+	}).Return(false, nil)
+	apiService.On("SendResponse", mock.Anything, "1", models.AuthResponse{RequestID: "1", Reason: models.ReasonInvalidCredentials}).Return(nil)
+
+	err := authentication.Start(ctx)
+	assert.NoError(t, err)
+	apiService.AssertExpectations(t)
+}
+
+func TestStart_CancelsRequestsAfterDrainTimeout(t *testing.T) {
+	authentication, adService, apiService := newTestAuthentication()
+	authentication.config.DrainTimeout = 20 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+
+	apiService.On("GetRequest", mock.Anything).Return([]models.AuthRequest{{RequestID: "1", Username: "user", Password: "pass"}}, nil).Once()
+	apiService.On("GetRequest", mock.Anything).Return([]models.AuthRequest(nil), nil)
+	adService.On("Authenticate", mock.Anything, "user", "pass").Run(func(args mock.Arguments) {
+		// A operação no AD só termina com o cancelamento do contexto da requisição
+		cancel()
+		<-args.Get(0).(context.Context).Done()
+	}).Return(false, fmt.Errorf("%w: %w", models.ErrDirectoryUnavailable, context.Canceled))
+	apiService.On("SendResponse", mock.Anything, "1", models.AuthResponse{RequestID: "1", Reason: models.ReasonDirectoryUnavailable}).Return(nil)
+
+	err := authentication.Start(ctx)
+	assert.NoError(t, err)
+	apiService.AssertExpectations(t)
+}
+
+func TestStart_PollStatus(t *testing.T) {
+	authentication, _, apiService := newTestAuthentication()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	assert.False(t, authentication.PollStatus().Running)
+
+	var during PollStatus
+	apiService.On("GetRequest", mock.Anything).Return([]models.AuthRequest(nil), errors.New("connection refused")).Once()
+	apiService.On("GetRequest", mock.Anything).Run(func(mock.Arguments) {
+		// Estado após a consulta com falha, antes da nova consulta terminar
+		during = authentication.PollStatus()
+		cancel()
+	}).Return([]models.AuthRequest(nil), nil)
+
+	err := authentication.Start(ctx)
+	assert.NoError(t, err)
+
+	assert.True(t, during.Running)
+	assert.Equal(t, 1, during.ConsecutiveFailures)
+	assert.Equal(t, "connection refused", during.LastError)
+	assert.True(t, during.LastSuccess.IsZero())
+
+	status := authentication.PollStatus()
+	assert.False(t, status.Running)
+	assert.Zero(t, status.ConsecutiveFailures)
+	assert.Empty(t, status.LastError)
+	assert.False(t, status.LastSuccess.IsZero())
+	assert.Equal(t, status.LastSuccess, status.LastActivity)
+}
+
+// recordSpans registra os spans do teste em memória
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	return exporter
+}
+
+// spanAttribute retorna o valor de um atributo do span
+func spanAttribute(span tracetest.SpanStub, key string) string {
+	for _, attribute := range span.Attributes {
+		if string(attribute.Key) == key {
+			return attribute.Value.Emit()
+		}
+	}
+	return ""
+}
+
+func TestProcess_RecordsSpans(t *testing.T) {
+	exporter := recordSpans(t)
+	authentication, adService, apiService := newTestAuthentication()
+	request := models.AuthRequest{RequestID: "1", Username: "user", Password: "pass", Tenant: "loja"}
+
+	adService.On("Authenticate", mock.Anything, "user", "pass").Return(true, nil)
+	adService.On("GetUser", mock.Anything, "user").Return(models.UserData{Username: "user"}, nil)
+	apiService.On("SendResponse", mock.Anything, "1", mock.Anything).Return(nil)
+
+	assert.NoError(t, authentication.process(context.Background(), request))
+
+	spans := exporter.GetSpans()
+	names := make([]string, 0, len(spans))
+	for _, span := range spans {
+		names = append(names, span.Name)
+	}
+	assert.Equal(t, []string{"authentication.bind", "authentication.search", "authentication.respond", "authentication.process"}, names)
+
+	root := spans[3]
+	assert.False(t, root.Parent.IsValid())
+	assert.Equal(t, "true", spanAttribute(root, "auth.success"))
+	for _, span := range spans {
+		assert.Equal(t, "1", spanAttribute(span, string(tracing.AttrRequestID)))
+		assert.Equal(t, "loja", spanAttribute(span, string(tracing.AttrTenant)))
+		assert.Equal(t, root.SpanContext.TraceID(), span.SpanContext.TraceID())
+	}
+}
+
+func TestProcess_SpanStatus(t *testing.T) {
+	exporter := recordSpans(t)
+	authentication, adService, apiService := newTestAuthentication()
+
+	adService.On("Authenticate", mock.Anything, "user", "errada").Return(false, nil)
+	adService.On("Authenticate", mock.Anything, "user", "pass").Return(false, models.ErrDirectoryUnavailable)
+	apiService.On("SendResponse", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	// Credenciais recusadas são uma resposta normal do AD, registrada no motivo
+	assert.NoError(t, authentication.process(context.Background(), models.AuthRequest{RequestID: "1", Username: "user", Password: "errada"}))
+	bind := exporter.GetSpans()[0]
+	assert.Equal(t, "authentication.bind", bind.Name)
+	assert.Equal(t, otelcodes.Unset, bind.Status.Code)
+	assert.Equal(t, string(models.ReasonInvalidCredentials), spanAttribute(bind, "auth.reason"))
+
+	exporter.Reset()
+	assert.Error(t, authentication.process(context.Background(), models.AuthRequest{RequestID: "2", Username: "user", Password: "pass"}))
+	spans := exporter.GetSpans()
+	assert.Equal(t, otelcodes.Error, spans[0].Status.Code)
+	assert.Equal(t, "authentication.process", spans[len(spans)-1].Name)
+	assert.Equal(t, otelcodes.Error, spans[len(spans)-1].Status.Code)
+}
+
+func TestPoll_RecordsSpan(t *testing.T) {
+	exporter := recordSpans(t)
+	authentication, _, apiService := newTestAuthentication()
+
+	apiService.On("GetRequest", mock.Anything).Return([]models.AuthRequest{{RequestID: "1"}, {RequestID: "2"}}, nil).Once()
+
+	received, err := authentication.poll(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, received)
+
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, "authentication.poll", spans[0].Name)
+		assert.Equal(t, "2", spanAttribute(spans[0], "auth.requests_received"))
+	}
+}
@@ -0,0 +1,56 @@
+package authentication
+
+import (
+	"sync"
+	"time"
+)
+
+// backoff controla a espera exponencial após falhas sistêmicas consecutivas
+type backoff struct {
+	mu      sync.Mutex
+	current time.Duration
+	until   time.Time
+}
+
+// failure registra uma falha, dobrando a espera até maxBackoff.
+// Retorna: a espera aplicada.
+func (b *backoff) failure() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.current == 0 {
+		b.current = minBackoff
+	} else {
+		b.current = nextBackoff(b.current)
+	}
+	b.until = time.Now().Add(b.current)
+
+	return b.current
+}
+
+// success registra um sucesso, encerrando a espera.
+func (b *backoff) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.current = 0
+	b.until = time.Time{}
+}
+
+// remaining retorna quanto tempo ainda resta de espera.
+func (b *backoff) remaining() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return time.Until(b.until)
+}
+
+// nextBackoff dobra a espera atual, limitada a maxBackoff.
+func nextBackoff(current time.Duration) time.Duration {
+	next := current * 2
+	if next > maxBackoff {
+		return maxBackoff
+	}
+
+	return next
+}
@@ -0,0 +1,41 @@
+package authentication
+
+import "time"
+
+// pollInterval controla o intervalo adaptativo entre as consultas: mínimo enquanto há requisições
+// chegando, dobrando a cada consulta vazia até o máximo configurado
+type pollInterval struct {
+	min     time.Duration
+	max     time.Duration
+	current time.Duration
+}
+
+// newPollInterval cria o intervalo adaptativo, começando pelo mínimo.
+func newPollInterval(min, max time.Duration) *pollInterval {
+	if max < min {
+		max = min
+	}
+
+	return &pollInterval{min: min, max: max, current: min}
+}
+
+// next registra o resultado de uma consulta e retorna o intervalo até a próxima.
+// Parâmetros:
+// - received: quantidade de requisições obtidas na consulta.
+// Retorna: o intervalo até a próxima consulta.
+func (p *pollInterval) next(received int) time.Duration {
+	if received > 0 {
+		p.current = p.min
+		return p.current
+	}
+
+	if p.current <= 0 {
+		p.current = time.Millisecond
+	}
+	p.current *= 2
+	if p.current > p.max {
+		p.current = p.max
+	}
+
+	return p.current
+}
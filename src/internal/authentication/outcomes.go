@@ -0,0 +1,51 @@
+package authentication
+
+import (
+	"auth-ad/src/internal/models"
+	"sync"
+)
+
+// OutcomeStats reúne os resultados das autenticações processadas. Respostas reenviadas a
+// requisições duplicadas não são contadas novamente.
+type OutcomeStats struct {
+	Succeeded uint64                          // Autenticações bem-sucedidas
+	Failed    map[models.FailureReason]uint64 // Autenticações com falha, por motivo
+}
+
+// outcomeCounter conta os resultados das autenticações
+type outcomeCounter struct {
+	mu        sync.Mutex
+	succeeded uint64
+	failed    map[models.FailureReason]uint64
+}
+
+// add conta o resultado de uma resposta calculada.
+// Parâmetros:
+// - response: resposta enviada à API.
+func (c *outcomeCounter) add(response models.AuthResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if response.Success {
+		c.succeeded++
+		return
+	}
+
+	if c.failed == nil {
+		c.failed = make(map[models.FailureReason]uint64)
+	}
+	c.failed[response.Reason]++
+}
+
+// stats retorna uma cópia dos contadores.
+func (c *outcomeCounter) stats() OutcomeStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	failed := make(map[models.FailureReason]uint64, len(c.failed))
+	for reason, count := range c.failed {
+		failed[reason] = count
+	}
+
+	return OutcomeStats{Succeeded: c.succeeded, Failed: failed}
+}
@@ -0,0 +1,70 @@
+package authentication
+
+import (
+	"sync"
+	"time"
+)
+
+// PollStatus reúne o estado do laço de consultas de requisições
+type PollStatus struct {
+	Running             bool      // Laço de consultas em execução
+	LastActivity        time.Time // Última consulta concluída, com sucesso ou falha, ou o início do laço
+	LastSuccess         time.Time // Última consulta bem-sucedida
+	LastError           string    // Erro da última consulta, vazio após uma consulta bem-sucedida
+	ConsecutiveFailures int       // Consultas com falha desde a última bem-sucedida
+}
+
+// pollTracker acompanha o laço de consultas
+type pollTracker struct {
+	mu     sync.Mutex
+	status PollStatus
+}
+
+// start registra o início do laço de consultas.
+func (t *pollTracker) start(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.status.Running = true
+	t.status.LastActivity = now
+}
+
+// stop registra o fim do laço de consultas.
+func (t *pollTracker) stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.status.Running = false
+}
+
+// success registra uma consulta bem-sucedida.
+func (t *pollTracker) success(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.status.LastActivity = now
+	t.status.LastSuccess = now
+	t.status.LastError = ""
+	t.status.ConsecutiveFailures = 0
+}
+
+// failure registra uma consulta com falha.
+// Parâmetros:
+// - now: momento da falha.
+// - err: erro da consulta.
+func (t *pollTracker) failure(now time.Time, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.status.LastActivity = now
+	t.status.LastError = err.Error()
+	t.status.ConsecutiveFailures++
+}
+
+// get retorna uma cópia do estado.
+func (t *pollTracker) get() PollStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.status
+}
@@ -0,0 +1,109 @@
+package authentication
+
+import (
+	"auth-ad/src/internal/models"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+// seenSet guarda, por um tempo limitado, as respostas das requisições já processadas, para que
+// uma requisição devolvida novamente pela API seja respondida sem um novo bind no AD. Cada resposta
+// é guardada com o usuário e um HMAC da senha, com chave aleatória por processo, e só é reenviada
+// a uma requisição com as mesmas credenciais.
+type seenSet struct {
+	ttl time.Duration
+	now func() time.Time
+	key []byte
+
+	mu        sync.Mutex
+	responses map[string]seenResponse
+	pruned    time.Time
+}
+
+// seenResponse é a resposta de uma requisição processada, as credenciais que a produziram e o
+// momento em que ela expira
+type seenResponse struct {
+	username string
+	password []byte
+	response models.AuthResponse
+	expires  time.Time
+}
+
+// newSeenSet cria o conjunto de requisições processadas.
+// Parâmetros:
+// - ttl: tempo em que cada resposta é mantida; 0 desativa o conjunto.
+// Retorna: o conjunto de requisições processadas.
+func newSeenSet(ttl time.Duration) *seenSet {
+	key := make([]byte, sha256.Size)
+	rand.Read(key)
+
+	return &seenSet{ttl: ttl, now: time.Now, key: key, responses: make(map[string]seenResponse)}
+}
+
+// get retorna a resposta de uma requisição já processada com as mesmas credenciais.
+// Parâmetros:
+// - request: requisição de autenticação.
+// Retorna: a resposta e true caso a requisição tenha sido processada dentro do prazo com o mesmo
+// usuário e senha, e true em reused caso o ID tenha sido processado com outras credenciais.
+func (s *seenSet) get(request models.AuthRequest) (response models.AuthResponse, ok, reused bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen, found := s.responses[request.RequestID]
+	if !found || !s.now().Before(seen.expires) {
+		return models.AuthResponse{}, false, false
+	}
+	if seen.username != request.Username || !hmac.Equal(seen.password, s.hash(request.Password)) {
+		return models.AuthResponse{}, false, true
+	}
+
+	return seen.response, true, false
+}
+
+// add registra a resposta de uma requisição processada, descartando as respostas expiradas.
+func (s *seenSet) add(request models.AuthRequest, response models.AuthResponse) {
+	if s.ttl <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.responses[request.RequestID] = seenResponse{
+		username: request.Username,
+		password: s.hash(request.Password),
+		response: response,
+		expires:  now.Add(s.ttl),
+	}
+
+	// As respostas expiradas são descartadas no máximo uma vez por ttl
+	if now.Sub(s.pruned) < s.ttl {
+		return
+	}
+	for id, seen := range s.responses {
+		if !now.Before(seen.expires) {
+			delete(s.responses, id)
+		}
+	}
+	s.pruned = now
+}
+
+// hash calcula o HMAC da senha, para que ela não fique guardada em memória
+func (s *seenSet) hash(password string) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(password))
+
+	return mac.Sum(nil)
+}
+
+// len retorna a quantidade de respostas guardadas.
+func (s *seenSet) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.responses)
+}
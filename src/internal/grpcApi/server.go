@@ -0,0 +1,185 @@
+package grpcApi
+
+import (
+	"auth-ad/src/internal/grpcApi/pb"
+	"auth-ad/src/internal/interfaces"
+	"auth-ad/src/pkg/apiKeys"
+	"auth-ad/src/pkg/configs"
+	"auth-ad/src/pkg/logging"
+	"auth-ad/src/pkg/requestContext"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+//go:generate sh -c "cd ../../.. && buf generate"
+
+const (
+	// requestIDKey é o metadado com o ID da chamada, informado pelo cliente ou gerado pelo servidor
+	requestIDKey = "x-request-id"
+	// healthService é o prefixo dos métodos do protocolo de health checking, liberados sem chave de acesso
+	healthService = "/grpc.health.v1.Health/"
+)
+
+// logger registra os eventos da API gRPC
+var logger = logging.Component("grpcApi")
+
+// Server expõe a autenticação e as consultas ao AD como o serviço gRPC auth.v1.AuthService, com
+// health checking pelo protocolo padrão e, opcionalmente, server reflection
+type Server struct {
+	pb.UnimplementedAuthServiceServer
+
+	adService interfaces.IActiveDirectoryService
+	config    *configs.GrpcApiConfig
+	keys      *apiKeys.KeySet
+	health    *health.Server
+}
+
+// NewServer cria o servidor da API gRPC
+// Parâmetros:
+//   - adService: Serviço do Active Directory usado pelas chamadas
+//   - config: Configurações da API gRPC, com as chaves de acesso e o prazo das chamadas
+//
+// Retorna:
+//   - *Server: Servidor, iniciado por Serve
+func NewServer(adService interfaces.IActiveDirectoryService, config *configs.GrpcApiConfig) *Server {
+	return &Server{
+		adService: adService,
+		config:    config,
+		keys:      apiKeys.NewKeySet(config.ApiKeys),
+		health:    health.NewServer(),
+	}
+}
+
+// Serve atende as chamadas recebidas em listener até o cancelamento de ctx. No cancelamento, o
+// health checking passa a informar NOT_SERVING, novas chamadas são recusadas e as em andamento
+// têm até config.RequestTimeout para terminar.
+// Parâmetros:
+//   - ctx: Contexto que encerra o servidor
+//   - listener: Listener em que o servidor escuta
+//
+// Retorna:
+//   - error: Erro em caso de falha no servidor
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	server := s.newGrpcServer()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		<-ctx.Done()
+
+		s.health.Shutdown()
+
+		stopped := make(chan struct{})
+		go func() {
+			server.GracefulStop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+		case <-time.After(s.config.RequestTimeout):
+			server.Stop()
+		}
+	}()
+
+	logger.Info("API gRPC escutando", "addr", listener.Addr().String())
+
+	if err := server.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	<-done
+
+	return nil
+}
+
+// newGrpcServer cria o servidor gRPC com o serviço de autenticação, o health checking e, se
+// configurado, o server reflection
+func (s *Server) newGrpcServer() *grpc.Server {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(s.unaryInterceptor),
+		grpc.ChainStreamInterceptor(s.streamInterceptor),
+	)
+
+	pb.RegisterAuthServiceServer(server, s)
+
+	healthpb.RegisterHealthServer(server, s.health)
+	s.health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	s.health.SetServingStatus(pb.AuthService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+
+	if s.config.Reflection {
+		reflection.Register(server)
+	}
+
+	return server
+}
+
+// unaryInterceptor exige a chave de acesso e cria o contexto de cada chamada, com o ID da chamada
+// e o prazo configurado
+func (s *Server) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := s.authorize(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.requestContext(ctx)
+	defer cancel()
+
+	return handler(ctx, req)
+}
+
+// streamInterceptor exige a chave de acesso nas chamadas de streaming (health checking e reflection)
+func (s *Server) streamInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := s.authorize(stream.Context(), info.FullMethod); err != nil {
+		return err
+	}
+
+	return handler(srv, stream)
+}
+
+// authorize exige do cliente uma das chaves de acesso configuradas no metadado authorization:
+// Bearer. O health checking é liberado, para que orquestradores possam verificar o servidor.
+// Retorna: erro UNAUTHENTICATED caso a chave esteja ausente ou seja inválida
+func (s *Server) authorize(ctx context.Context, fullMethod string) error {
+	if strings.HasPrefix(fullMethod, healthService) {
+		return nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		if token, ok := strings.CutPrefix(value, "Bearer "); ok && s.keys.Valid(token) {
+			return nil
+		}
+	}
+
+	return status.Error(codes.Unauthenticated, "chave de acesso ausente ou inválida")
+}
+
+// requestContext cria o contexto de uma chamada, com o ID do metadado x-request-id ou gerado,
+// devolvido no cabeçalho da resposta, e o prazo configurado
+// Retorna: o contexto da chamada e a função que o cancela
+func (s *Server) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	var requestID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDKey); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+	if !requestContext.ValidRequestID(requestID) {
+		requestID = requestContext.NewRequestID()
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, requestID))
+
+	ctx = requestContext.WithRequestID(ctx, requestID)
+
+	return context.WithTimeout(ctx, s.config.RequestTimeout)
+}
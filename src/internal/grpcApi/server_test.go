@@ -0,0 +1,184 @@
+package grpcApi
+
+import (
+	"auth-ad/src/internal/grpcApi/pb"
+	"auth-ad/src/internal/interfaces/mocks"
+	"auth-ad/src/internal/models"
+	"auth-ad/src/pkg/configs"
+	"auth-ad/src/pkg/requestContext"
+	"auth-ad/src/pkg/throttle"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// startTestServer inicia o servidor em memória com o serviço do AD simulado
+func startTestServer(t *testing.T) (*grpc.ClientConn, *mocks.IActiveDirectoryService) {
+	adService := new(mocks.IActiveDirectoryService)
+	adService.On("Unbind", mock.Anything).Return(nil).Maybe()
+
+	server := NewServer(adService, &configs.GrpcApiConfig{
+		ApiKeys:        []string{"chave-1"},
+		RequestTimeout: time.Second,
+	})
+
+	listener := bufconn.Listen(1024 * 1024)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(ctx, listener) }()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Erro ao conectar ao servidor: %v", err)
+	}
+
+	t.Cleanup(func() {
+		conn.Close()
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Erro no encerramento do servidor: %v", err)
+		}
+	})
+
+	return conn, adService
+}
+
+// withKey adiciona a chave de acesso ao contexto da chamada
+func withKey(key string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+key)
+}
+
+func TestAuthenticate(t *testing.T) {
+	conn, adService := startTestServer(t)
+	client := pb.NewAuthServiceClient(conn)
+	user := models.UserData{Username: "user", Email: "user@example.com", Groups: []string{"Vendas"}}
+
+	// A origem da limitação de tentativas é o campo source da mensagem, e o chamador é o endereço da conexão
+	withSource := mock.MatchedBy(func(ctx context.Context) bool {
+		return requestContext.Source(ctx) == "10.0.0.1" && requestContext.Caller(ctx) == "bufconn"
+	})
+	adService.On("Authenticate", withSource, "user", "pass").Return(true, nil)
+	adService.On("Authenticate", mock.Anything, "user", "errada").Return(false, nil)
+	limited := &throttle.LimitedError{Scope: throttle.ScopeSource, Until: time.Now().Add(90 * time.Second)}
+	adService.On("Authenticate", mock.Anything, "limited", "pass").Return(false, fmt.Errorf("%w: %w", models.ErrTooManyAttempts, limited))
+	adService.On("Authenticate", mock.Anything, "down", "pass").Return(false, errors.New("LDAP Result Code 200"))
+	adService.On("GetUser", mock.Anything, "user").Return(user, nil)
+
+	response, err := client.Authenticate(withKey("chave-1"), &pb.AuthRequest{RequestId: "req-1", Username: "user", Password: "pass", Source: "10.0.0.1"})
+	assert.NoError(t, err)
+	assert.True(t, response.GetSuccess())
+	assert.Equal(t, "req-1", response.GetRequestId())
+	assert.Equal(t, []string{"Vendas"}, response.GetUserData().GetGroups())
+
+	// Credenciais recusadas são um resultado da autenticação
+	response, err = client.Authenticate(withKey("chave-1"), &pb.AuthRequest{Username: "user", Password: "errada"})
+	assert.NoError(t, err)
+	assert.False(t, response.GetSuccess())
+	assert.Equal(t, string(models.ReasonInvalidCredentials), response.GetReason())
+	assert.NotEmpty(t, response.GetRequestId())
+
+	// A recusa pela limitação de tentativas informa a espera sugerida
+	var header metadata.MD
+	response, err = client.Authenticate(withKey("chave-1"), &pb.AuthRequest{Username: "limited", Password: "pass"}, grpc.Header(&header))
+	assert.NoError(t, err)
+	assert.Equal(t, string(models.ReasonTooManyAttempts), response.GetReason())
+	assert.Equal(t, []string{"90"}, header.Get("retry-after"))
+
+	_, err = client.Authenticate(withKey("chave-1"), &pb.AuthRequest{Username: "down", Password: "pass"})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.NotContains(t, err.Error(), "LDAP")
+
+	_, err = client.Authenticate(withKey("chave-1"), &pb.AuthRequest{Username: "user"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestAuthorize(t *testing.T) {
+	conn, adService := startTestServer(t)
+	client := pb.NewAuthServiceClient(conn)
+
+	_, err := client.GetUser(context.Background(), &pb.GetUserRequest{Username: "user"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = client.GetUser(withKey("chave-2"), &pb.GetUserRequest{Username: "user"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	adService.AssertNotCalled(t, "GetUser", mock.Anything, mock.Anything)
+
+	// O health checking não exige chave de acesso
+	health, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{Service: "auth.v1.AuthService"})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, health.GetStatus())
+}
+
+func TestGetUserAndIsMemberOf(t *testing.T) {
+	conn, adService := startTestServer(t)
+	client := pb.NewAuthServiceClient(conn)
+	user := models.UserData{Username: "user", Email: "user@example.com", Groups: []string{"Vendas", "Gerentes"}}
+
+	adService.On("GetUser", mock.Anything, "user").Return(user, nil)
+	adService.On("GetUser", mock.Anything, "ghost").Return(models.UserData{}, models.ErrUserNotFound)
+	adService.On("IsMemberOf", mock.Anything, "user", "gerentes").Return(true, nil)
+	adService.On("IsMemberOf", mock.Anything, "user", "Financeiro").Return(false, nil)
+	adService.On("IsMemberOf", mock.Anything, "ghost", "Vendas").Return(false, models.ErrUserNotFound)
+
+	var header metadata.MD
+	ctx := metadata.AppendToOutgoingContext(withKey("chave-1"), "x-request-id", "req-42")
+	data, err := client.GetUser(ctx, &pb.GetUserRequest{Username: "user"}, grpc.Header(&header))
+	assert.NoError(t, err)
+	assert.Equal(t, "user@example.com", data.GetEmail())
+	assert.Equal(t, []string{"req-42"}, header.Get("x-request-id"))
+
+	_, err = client.GetUser(withKey("chave-1"), &pb.GetUserRequest{Username: "ghost"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	member, err := client.IsMemberOf(withKey("chave-1"), &pb.IsMemberOfRequest{Username: "user", Group: "gerentes"})
+	assert.NoError(t, err)
+	assert.True(t, member.GetMember())
+
+	member, err = client.IsMemberOf(withKey("chave-1"), &pb.IsMemberOfRequest{Username: "user", Group: "Financeiro"})
+	assert.NoError(t, err)
+	assert.False(t, member.GetMember())
+
+	_, err = client.IsMemberOf(withKey("chave-1"), &pb.IsMemberOfRequest{Username: "ghost", Group: "Vendas"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestListGroupMembers(t *testing.T) {
+	conn, adService := startTestServer(t)
+	client := pb.NewAuthServiceClient(conn)
+
+	adService.On("GetUsers", mock.Anything, "Vendas").Return([]models.UserData{{Username: "user1"}, {Username: "user2"}}, nil)
+	adService.On("GetUsers", mock.Anything, "Nenhum").Return([]models.UserData(nil), models.ErrGroupNotFound)
+	adService.On("GetUsers", mock.Anything, "Todos").Return([]models.UserData(nil), models.ErrGroupTooLarge)
+
+	response, err := client.ListGroupMembers(withKey("chave-1"), &pb.ListGroupMembersRequest{Group: "Vendas"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Vendas", response.GetGroup())
+	assert.Len(t, response.GetMembers(), 2)
+	assert.Equal(t, "user2", response.GetMembers()[1].GetUsername())
+
+	_, err = client.ListGroupMembers(withKey("chave-1"), &pb.ListGroupMembersRequest{Group: "Nenhum"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.ListGroupMembers(withKey("chave-1"), &pb.ListGroupMembersRequest{Group: "Todos"})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	_, err = client.ListGroupMembers(withKey("chave-1"), &pb.ListGroupMembersRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
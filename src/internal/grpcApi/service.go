@@ -0,0 +1,172 @@
+package grpcApi
+
+import (
+	"auth-ad/src/internal/grpcApi/pb"
+	"auth-ad/src/internal/models"
+	"auth-ad/src/pkg/logging"
+	"auth-ad/src/pkg/requestContext"
+	"context"
+	"errors"
+	"math"
+	"net"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Authenticate valida as credenciais do usuário. Credenciais recusadas são um resultado da
+// autenticação, com success falso e o motivo da falha; apenas falhas sistêmicas retornam erro.
+// O request_id da mensagem, quando informado, identifica a chamada no lugar do x-request-id. A
+// origem da limitação de tentativas é o endereço do cliente da conexão; o campo source da mensagem
+// serve apenas para identificar a tentativa nos logs. Recusas pela limitação de tentativas informam
+// a espera sugerida, em segundos, no cabeçalho retry-after.
+func (s *Server) Authenticate(ctx context.Context, request *pb.AuthRequest) (*pb.AuthResponse, error) {
+	if request.GetUsername() == "" || request.GetPassword() == "" {
+		return nil, status.Error(codes.InvalidArgument, "username e password são obrigatórios")
+	}
+
+	requestID := request.GetRequestId()
+	if requestContext.ValidRequestID(requestID) {
+		ctx = requestContext.WithRequestID(ctx, requestID)
+	} else {
+		requestID = requestContext.RequestID(ctx)
+	}
+	if request.GetTenant() != "" {
+		ctx = requestContext.WithTenant(ctx, request.GetTenant())
+	}
+	ctx = requestContext.WithSource(ctx, request.GetSource())
+	ctx = requestContext.WithCaller(ctx, peerHost(ctx))
+
+	defer s.adService.Unbind(ctx)
+
+	authenticated, err := s.adService.Authenticate(ctx, request.GetUsername(), request.GetPassword())
+	if err == nil && !authenticated {
+		err = models.ErrInvalidCredentials
+	}
+
+	var user models.UserData
+	if err == nil {
+		user, err = s.adService.GetUser(ctx, request.GetUsername())
+	}
+
+	if err != nil {
+		if !models.IsRequestError(err) {
+			return nil, serviceError(ctx, "Authenticate", err)
+		}
+
+		logger.InfoContext(ctx, "Falha na autenticação", "reason", models.ReasonFor(err), "source", request.GetSource(), logging.AttrError, err)
+		if retryAfter, ok := models.RetryAfter(err); ok {
+			grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(int(math.Ceil(retryAfter.Seconds())))))
+		}
+		return &pb.AuthResponse{
+			RequestId: requestID,
+			Success:   false,
+			Reason:    string(models.ReasonFor(err)),
+		}, nil
+	}
+
+	return &pb.AuthResponse{
+		RequestId: requestID,
+		Success:   true,
+		UserData:  toUserData(user),
+	}, nil
+}
+
+// GetUser retorna os dados de um usuário
+func (s *Server) GetUser(ctx context.Context, request *pb.GetUserRequest) (*pb.UserData, error) {
+	user, err := s.adService.GetUser(ctx, request.GetUsername())
+	if err != nil {
+		return nil, serviceError(ctx, "GetUser", err)
+	}
+
+	return toUserData(user), nil
+}
+
+// ListGroupMembers retorna os usuários de um grupo, incluindo os de grupos aninhados, sem os grupos de
+// cada usuário
+func (s *Server) ListGroupMembers(ctx context.Context, request *pb.ListGroupMembersRequest) (*pb.ListGroupMembersResponse, error) {
+	if request.GetGroup() == "" {
+		return nil, status.Error(codes.InvalidArgument, "group é obrigatório")
+	}
+
+	users, err := s.adService.GetUsers(ctx, request.GetGroup())
+	if err != nil {
+		return nil, serviceError(ctx, "ListGroupMembers", err)
+	}
+
+	members := make([]*pb.UserData, 0, len(users))
+	for _, user := range users {
+		members = append(members, toUserData(user))
+	}
+
+	return &pb.ListGroupMembersResponse{Group: request.GetGroup(), Members: members}, nil
+}
+
+// IsMemberOf indica se o usuário pertence ao grupo, informado no formato de AD_GROUP_FORMAT. Os
+// grupos aninhados são sempre considerados, independentemente de AD_GROUP_RESOLUTION.
+func (s *Server) IsMemberOf(ctx context.Context, request *pb.IsMemberOfRequest) (*pb.IsMemberOfResponse, error) {
+	if request.GetGroup() == "" {
+		return nil, status.Error(codes.InvalidArgument, "group é obrigatório")
+	}
+
+	member, err := s.adService.IsMemberOf(ctx, request.GetUsername(), request.GetGroup())
+	if err != nil {
+		return nil, serviceError(ctx, "IsMemberOf", err)
+	}
+
+	return &pb.IsMemberOfResponse{Member: member}, nil
+}
+
+// toUserData converte os dados do usuário para a mensagem protobuf
+func toUserData(user models.UserData) *pb.UserData {
+	return &pb.UserData{
+		Username: user.Username,
+		Email:    user.Email,
+		Groups:   user.Groups,
+	}
+}
+
+// serviceError converte um erro do serviço do AD no status gRPC correspondente. Falhas sistêmicas
+// são registradas no log e retornadas como UNAVAILABLE, sem expor detalhes do AD ao cliente.
+// Parâmetros:
+//   - ctx: Contexto da chamada, usado na correlação dos logs
+//   - method: Nome da chamada, usado no log
+//   - err: Erro retornado pelo serviço
+//
+// Retorna:
+//   - error: Status gRPC
+func serviceError(ctx context.Context, method string, err error) error {
+	switch {
+	case errors.Is(err, models.ErrInvalidUsername):
+		return status.Error(codes.InvalidArgument, models.ErrInvalidUsername.Error())
+	case errors.Is(err, models.ErrUserNotFound):
+		return status.Error(codes.NotFound, models.ErrUserNotFound.Error())
+	case errors.Is(err, models.ErrGroupNotFound):
+		return status.Error(codes.NotFound, models.ErrGroupNotFound.Error())
+	case errors.Is(err, models.ErrGroupTooLarge):
+		return status.Error(codes.ResourceExhausted, models.ErrGroupTooLarge.Error())
+	}
+
+	logger.ErrorContext(ctx, "Falha sistêmica", "method", method, logging.AttrError, err)
+
+	return status.Error(codes.Unavailable, models.ErrDirectoryUnavailable.Error())
+}
+
+// peerHost retorna o endereço do cliente da conexão, sem a porta, ou vazio quando não houver
+func peerHost(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
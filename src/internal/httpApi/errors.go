@@ -0,0 +1,69 @@
+package httpApi
+
+import (
+	"auth-ad/src/internal/models"
+	"auth-ad/src/pkg/logging"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// Códigos de erro retornados no corpo das respostas de erro
+const (
+	codeInvalidRequest       = "invalid_request"        // Corpo ausente, malformado ou com campos inválidos
+	codeUnauthorized         = "unauthorized"           // Chave de acesso ausente ou inválida
+	codeNotFound             = "not_found"              // Rota inexistente
+	codeMethodNotAllowed     = "method_not_allowed"     // Método não suportado pela rota
+	codeUnsupportedMedia     = "unsupported_media_type" // Corpo que não é JSON
+	codeRequestTooLarge      = "request_too_large"      // Corpo maior que HTTP_MAX_BODY_BYTES
+	codeInvalidUsername      = "invalid_username"       // Nome de usuário vazio, longo demais ou com caracteres inválidos
+	codeUserNotFound         = string(models.ReasonUserNotFound)
+	codeGroupNotFound        = string(models.ReasonGroupNotFound)
+	codeGroupTooLarge        = string(models.ReasonGroupTooLarge)
+	codeDirectoryUnavailable = string(models.ReasonDirectoryUnavailable)
+)
+
+// errorBody é o corpo das respostas de erro
+type errorBody struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+// writeJSON envia uma resposta JSON com o status informado
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logger.Warn("Erro ao enviar a resposta HTTP", logging.AttrError, err)
+	}
+}
+
+// writeError envia uma resposta de erro com o código e a mensagem informados
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, errorBody{Error: code, Message: message})
+}
+
+// writeServiceError envia a resposta de erro correspondente a um erro do serviço do AD. Falhas
+// sistêmicas são registradas no log e respondidas com 503, sem expor detalhes do AD ao cliente.
+// Parâmetros:
+//   - ctx: Contexto da requisição, usado na correlação dos logs
+//   - w: Resposta HTTP
+//   - r: Requisição HTTP
+//   - err: Erro retornado pelo serviço
+func writeServiceError(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, models.ErrInvalidUsername):
+		writeError(w, http.StatusBadRequest, codeInvalidUsername, models.ErrInvalidUsername.Error())
+	case errors.Is(err, models.ErrUserNotFound):
+		writeError(w, http.StatusNotFound, codeUserNotFound, models.ErrUserNotFound.Error())
+	case errors.Is(err, models.ErrGroupNotFound):
+		writeError(w, http.StatusNotFound, codeGroupNotFound, models.ErrGroupNotFound.Error())
+	case errors.Is(err, models.ErrGroupTooLarge):
+		writeError(w, http.StatusUnprocessableEntity, codeGroupTooLarge, models.ErrGroupTooLarge.Error())
+	default:
+		logger.ErrorContext(ctx, "Falha sistêmica", "method", r.Method, "path", r.URL.Path, logging.AttrError, err)
+		writeError(w, http.StatusServiceUnavailable, codeDirectoryUnavailable, models.ErrDirectoryUnavailable.Error())
+	}
+}
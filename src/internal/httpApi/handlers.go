@@ -0,0 +1,205 @@
+package httpApi
+
+import (
+	"auth-ad/src/internal/models"
+	"auth-ad/src/pkg/logging"
+	"auth-ad/src/pkg/requestContext"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// requestIDHeader é o cabeçalho com o ID da requisição, informado pelo cliente ou gerado pelo servidor
+const requestIDHeader = "X-Request-ID"
+
+// authenticateRequest é o corpo de POST /v1/authenticate
+type authenticateRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Tenant   string `json:"tenant,omitempty"`
+	Source   string `json:"source,omitempty"` // Endereço do usuário final, repassado pelo chamador autenticado
+}
+
+// groupMembersResponse é o corpo da resposta de GET /v1/groups/{group}/members
+type groupMembersResponse struct {
+	Group   string            `json:"group"`
+	Members []models.UserData `json:"members"`
+}
+
+// handleAuthenticate valida as credenciais do corpo da requisição. O resultado da autenticação
+// segue o formato das respostas enviadas à API Smarket: 200 com success verdadeiro e os dados do
+// usuário, ou 200 com success falso e o motivo da falha. Apenas falhas sistêmicas respondem 503.
+func (s *Server) handleAuthenticate(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var body authenticateRequest
+	if !s.decodeBody(w, r, &body) {
+		return
+	}
+	if body.Username == "" || body.Password == "" {
+		writeError(w, http.StatusBadRequest, codeInvalidRequest, "username e password são obrigatórios")
+		return
+	}
+
+	ctx, cancel, requestID := s.requestContext(w, r, body.Tenant)
+	defer cancel()
+	defer s.adService.Unbind(ctx)
+
+	// A origem é o endereço do usuário final, repassado pelo chamador autenticado pela chave de
+	// acesso; o endereço da conexão identifica o chamador, compartilhado pelos usuários de um proxy
+	ctx = requestContext.WithSource(ctx, body.Source)
+	ctx = requestContext.WithCaller(ctx, remoteHost(r))
+
+	authenticated, err := s.adService.Authenticate(ctx, body.Username, body.Password)
+	if err == nil && !authenticated {
+		err = models.ErrInvalidCredentials
+	}
+
+	var user models.UserData
+	if err == nil {
+		user, err = s.adService.GetUser(ctx, body.Username)
+	}
+
+	if err != nil {
+		if !models.IsRequestError(err) {
+			writeServiceError(ctx, w, r, err)
+			return
+		}
+
+		logger.InfoContext(ctx, "Falha na autenticação", "reason", models.ReasonFor(err), "source", body.Source, logging.AttrError, err)
+		if retryAfter, ok := models.RetryAfter(err); ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		}
+		writeJSON(w, http.StatusOK, models.AuthResponse{
+			RequestID: requestID,
+			Success:   false,
+			Reason:    models.ReasonFor(err),
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.AuthResponse{
+		RequestID: requestID,
+		Success:   true,
+		UserData:  user,
+	})
+}
+
+// handleGetUser retorna os dados de um usuário
+func (s *Server) handleGetUser(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	ctx, cancel, _ := s.requestContext(w, r, "")
+	defer cancel()
+
+	user, err := s.adService.GetUser(ctx, r.PathValue("username"))
+	if err != nil {
+		writeServiceError(ctx, w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
+
+// handleGetGroupMembers retorna os usuários de um grupo, incluindo os membros de grupos aninhados
+func (s *Server) handleGetGroupMembers(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	ctx, cancel, _ := s.requestContext(w, r, "")
+	defer cancel()
+
+	group := r.PathValue("group")
+	members, err := s.adService.GetUsers(ctx, group)
+	if err != nil {
+		writeServiceError(ctx, w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, groupMembersResponse{Group: group, Members: members})
+}
+
+// allowMethod responde 405 caso a requisição não use o método da rota
+// Retorna: true caso o método seja o esperado
+func allowMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method {
+		return true
+	}
+
+	w.Header().Set("Allow", method)
+	writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "método não permitido: "+r.Method)
+
+	return false
+}
+
+// decodeBody lê o corpo JSON da requisição, limitado a config.MaxBodyBytes. Campos desconhecidos
+// e conteúdo após o objeto são rejeitados.
+// Retorna: false caso a resposta de erro já tenha sido enviada
+func (s *Server) decodeBody(w http.ResponseWriter, r *http.Request, body any) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, codeUnsupportedMedia, "o corpo deve ser application/json")
+		return false
+	}
+
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, s.config.MaxBodyBytes))
+	decoder.DisallowUnknownFields()
+
+	err = decoder.Decode(body)
+	if err == nil && decoder.Decode(&struct{}{}) != io.EOF {
+		err = errors.New("conteúdo após o objeto JSON")
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		writeError(w, http.StatusRequestEntityTooLarge, codeRequestTooLarge, "corpo maior que o limite permitido")
+		return false
+	case err != nil:
+		writeError(w, http.StatusBadRequest, codeInvalidRequest, "corpo inválido: "+err.Error())
+		return false
+	}
+
+	return true
+}
+
+// requestContext cria o contexto de uma requisição HTTP, com o ID da requisição, o tenant e o
+// prazo configurado. O ID vem do cabeçalho X-Request-ID ou é gerado, e é devolvido na resposta.
+// Retorna: o contexto, a função que o cancela e o ID da requisição
+func (s *Server) requestContext(w http.ResponseWriter, r *http.Request, tenant string) (context.Context, context.CancelFunc, string) {
+	requestID := r.Header.Get(requestIDHeader)
+	if !requestContext.ValidRequestID(requestID) {
+		requestID = requestContext.NewRequestID()
+	}
+	w.Header().Set(requestIDHeader, requestID)
+
+	ctx := requestContext.WithRequestID(r.Context(), requestID)
+	if tenant != "" {
+		ctx = requestContext.WithTenant(ctx, tenant)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.config.RequestTimeout)
+
+	return ctx, cancel, requestID
+}
+
+// remoteHost retorna o endereço do cliente da conexão, sem a porta
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
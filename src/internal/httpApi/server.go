@@ -0,0 +1,108 @@
+package httpApi
+
+import (
+	"auth-ad/src/internal/interfaces"
+	"auth-ad/src/pkg/apiKeys"
+	"auth-ad/src/pkg/configs"
+	"auth-ad/src/pkg/logging"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// readHeaderTimeout é o prazo para o cliente enviar os cabeçalhos de uma requisição
+const readHeaderTimeout = 10 * time.Second
+
+// logger registra os eventos da API HTTP
+var logger = logging.Component("httpApi")
+
+// Server expõe a autenticação e as consultas ao AD por uma API HTTP síncrona, para aplicações
+// que chamam o serviço diretamente em vez de enfileirar requisições na API Smarket
+type Server struct {
+	adService interfaces.IActiveDirectoryService
+	config    *configs.HttpApiConfig
+	keys      *apiKeys.KeySet
+}
+
+// NewServer cria o servidor da API HTTP
+// Parâmetros:
+//   - adService: Serviço do Active Directory usado pelas rotas
+//   - config: Configurações da API HTTP, com as chaves de acesso e os limites das requisições
+//
+// Retorna:
+//   - *Server: Servidor, iniciado por Serve
+func NewServer(adService interfaces.IActiveDirectoryService, config *configs.HttpApiConfig) *Server {
+	return &Server{
+		adService: adService,
+		config:    config,
+		keys:      apiKeys.NewKeySet(config.ApiKeys),
+	}
+}
+
+// Handler retorna o handler com as rotas da API, protegidas pela autenticação do cliente
+// Retorna:
+//   - http.Handler: Handler da API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/authenticate", s.handleAuthenticate)
+	mux.HandleFunc("/v1/users/{username}", s.handleGetUser)
+	mux.HandleFunc("/v1/groups/{group}/members", s.handleGetGroupMembers)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, codeNotFound, "rota não encontrada")
+	})
+
+	return s.authorize(mux)
+}
+
+// Serve atende as requisições recebidas em listener até o cancelamento de ctx. No cancelamento,
+// novas conexões são recusadas e as requisições em andamento têm até config.RequestTimeout
+// para terminar.
+// Parâmetros:
+//   - ctx: Contexto que encerra o servidor
+//   - listener: Listener em que o servidor escuta
+//
+// Retorna:
+//   - error: Erro em caso de falha no servidor
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	server := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+		// O prazo da escrita cobre o processamento da requisição e o envio da resposta
+		WriteTimeout: s.config.RequestTimeout + readHeaderTimeout,
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), s.config.RequestTimeout)
+		defer cancel()
+		done <- server.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info("API HTTP escutando", "addr", listener.Addr().String())
+
+	if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return <-done
+}
+
+// authorize exige do cliente uma das chaves de acesso configuradas no cabeçalho
+// Authorization: Bearer, respondendo 401 às requisições sem uma chave válida
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || !s.keys.Valid(token) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="auth-ad"`)
+			writeError(w, http.StatusUnauthorized, codeUnauthorized, "chave de acesso ausente ou inválida")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,253 @@
+package httpApi
+
+import (
+	"auth-ad/src/internal/interfaces/mocks"
+	"auth-ad/src/internal/models"
+	"auth-ad/src/pkg/configs"
+	"auth-ad/src/pkg/requestContext"
+	"auth-ad/src/pkg/throttle"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newTestServer cria o servidor com o serviço do AD simulado
+func newTestServer() (*Server, *mocks.IActiveDirectoryService) {
+	adService := new(mocks.IActiveDirectoryService)
+	adService.On("Unbind", mock.Anything).Return(nil).Maybe()
+
+	server := NewServer(adService, &configs.HttpApiConfig{
+		ApiKeys:        []string{"chave-1", "chave-2"},
+		MaxBodyBytes:   256,
+		RequestTimeout: time.Second,
+	})
+
+	return server, adService
+}
+
+// call executa uma requisição autenticada no handler do servidor
+func call(server *Server, method, path, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set("Authorization", "Bearer chave-2")
+	if body != "" {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	recorder := httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+// decodeError lê o código do corpo de uma resposta de erro
+func decodeError(t *testing.T, recorder *httptest.ResponseRecorder) string {
+	var body errorBody
+	if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+		t.Fatalf("Corpo de erro inválido: %v", err)
+	}
+
+	return body.Error
+}
+
+func TestAuthenticate(t *testing.T) {
+	server, adService := newTestServer()
+	user := models.UserData{Username: "user", Email: "user@example.com", Groups: []string{"Vendas"}}
+
+	// A origem da limitação de tentativas é o endereço do usuário final informado no corpo, e o
+	// chamador é o endereço da conexão
+	withSource := mock.MatchedBy(func(ctx context.Context) bool {
+		return requestContext.Source(ctx) == "10.0.0.1" && requestContext.Caller(ctx) == "192.0.2.1"
+	})
+	adService.On("Authenticate", withSource, "user", "pass").Return(true, nil)
+	adService.On("GetUser", mock.Anything, "user").Return(user, nil)
+
+	recorder := call(server, "POST", "/v1/authenticate", `{"username":"user","password":"pass","source":"10.0.0.1"}`)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var response models.AuthResponse
+	assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&response))
+	assert.True(t, response.Success)
+	assert.Equal(t, user, response.UserData)
+	assert.Equal(t, recorder.Header().Get("X-Request-ID"), response.RequestID)
+	adService.AssertCalled(t, "Unbind", mock.Anything)
+}
+
+func TestAuthenticate_Failures(t *testing.T) {
+	server, adService := newTestServer()
+
+	adService.On("Authenticate", mock.Anything, "user", "errada").Return(false, nil)
+	adService.On("Authenticate", mock.Anything, "locked", "pass").Return(false, &models.AuthError{Reason: models.ReasonAccountLocked, Err: models.ErrInvalidCredentials})
+	limited := &throttle.LimitedError{Scope: throttle.ScopeUser, Until: time.Now().Add(90 * time.Second), Blocked: true}
+	adService.On("Authenticate", mock.Anything, "blocked", "pass").Return(false, fmt.Errorf("%w: %w", models.ErrTooManyAttempts, limited))
+	adService.On("Authenticate", mock.Anything, "user", "pass").Return(false, errors.New("LDAP Result Code 200"))
+
+	// Credenciais recusadas são um resultado da autenticação, com o motivo no corpo
+	for body, reason := range map[string]models.FailureReason{
+		`{"username":"user","password":"errada"}`:  models.ReasonInvalidCredentials,
+		`{"username":"locked","password":"pass"}`:  models.ReasonAccountLocked,
+		`{"username":"blocked","password":"pass"}`: models.ReasonTooManyAttempts,
+	} {
+		recorder := call(server, "POST", "/v1/authenticate", body)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		var response models.AuthResponse
+		assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&response))
+		assert.False(t, response.Success)
+		assert.Equal(t, reason, response.Reason)
+	}
+
+	// A recusa pela limitação de tentativas informa a espera sugerida
+	recorder := call(server, "POST", "/v1/authenticate", `{"username":"blocked","password":"pass"}`)
+	assert.Equal(t, "90", recorder.Header().Get("Retry-After"))
+
+	// Falhas sistêmicas respondem 503 sem expor o erro do AD
+	recorder = call(server, "POST", "/v1/authenticate", `{"username":"user","password":"pass"}`)
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), "LDAP")
+	assert.Equal(t, "directory_unavailable", decodeError(t, recorder))
+}
+
+func TestAuthenticate_InvalidRequests(t *testing.T) {
+	server, adService := newTestServer()
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		status int
+		code   string
+	}{
+		{"método", "GET", "", http.StatusMethodNotAllowed, "method_not_allowed"},
+		{"JSON malformado", "POST", `{"username":`, http.StatusBadRequest, "invalid_request"},
+		{"campo desconhecido", "POST", `{"username":"user","password":"pass","admin":true}`, http.StatusBadRequest, "invalid_request"},
+		{"objetos extras", "POST", `{"username":"user","password":"pass"}{}`, http.StatusBadRequest, "invalid_request"},
+		{"senha ausente", "POST", `{"username":"user"}`, http.StatusBadRequest, "invalid_request"},
+		{"corpo grande demais", "POST", `{"username":"user","password":"` + strings.Repeat("a", 300) + `"}`, http.StatusRequestEntityTooLarge, "request_too_large"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := call(server, tt.method, "/v1/authenticate", tt.body)
+
+			assert.Equal(t, tt.status, recorder.Code)
+			assert.Equal(t, tt.code, decodeError(t, recorder))
+		})
+	}
+
+	// O corpo deve ser JSON
+	request := httptest.NewRequest("POST", "/v1/authenticate", strings.NewReader(`username=user`))
+	request.Header.Set("Authorization", "Bearer chave-1")
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusUnsupportedMediaType, recorder.Code)
+
+	adService.AssertNotCalled(t, "Authenticate", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAuthorize(t *testing.T) {
+	server, adService := newTestServer()
+
+	for _, header := range []string{"", "Bearer", "Bearer chave-3", "Basic chave-1"} {
+		request := httptest.NewRequest("GET", "/v1/users/user", nil)
+		if header != "" {
+			request.Header.Set("Authorization", header)
+		}
+		recorder := httptest.NewRecorder()
+		server.Handler().ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code, header)
+		assert.Equal(t, "unauthorized", decodeError(t, recorder))
+		assert.NotEmpty(t, recorder.Header().Get("WWW-Authenticate"))
+	}
+
+	adService.AssertNotCalled(t, "GetUser", mock.Anything, mock.Anything)
+}
+
+func TestGetUser(t *testing.T) {
+	server, adService := newTestServer()
+	user := models.UserData{Username: "user", Email: "user@example.com", Groups: []string{"Vendas"}}
+
+	adService.On("GetUser", mock.Anything, "user").Return(user, nil)
+	adService.On("GetUser", mock.Anything, "ghost").Return(models.UserData{}, models.ErrUserNotFound)
+	adService.On("GetUser", mock.Anything, "a*b").Return(models.UserData{}, fmt.Errorf("%w: a*b", models.ErrInvalidUsername))
+
+	recorder := call(server, "GET", "/v1/users/user", "")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var response models.UserData
+	assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&response))
+	assert.Equal(t, user, response)
+
+	recorder = call(server, "GET", "/v1/users/ghost", "")
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Equal(t, "user_not_found", decodeError(t, recorder))
+
+	recorder = call(server, "GET", "/v1/users/a*b", "")
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, "invalid_username", decodeError(t, recorder))
+}
+
+func TestGetGroupMembers(t *testing.T) {
+	server, adService := newTestServer()
+	members := []models.UserData{{Username: "user1"}, {Username: "user2"}}
+
+	adService.On("GetUsers", mock.Anything, "Vendas").Return(members, nil)
+	adService.On("GetUsers", mock.Anything, "Nenhum").Return([]models.UserData(nil), models.ErrGroupNotFound)
+	adService.On("GetUsers", mock.Anything, "Todos").Return([]models.UserData(nil), models.ErrGroupTooLarge)
+
+	recorder := call(server, "GET", "/v1/groups/Vendas/members", "")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var response groupMembersResponse
+	assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&response))
+	assert.Equal(t, groupMembersResponse{Group: "Vendas", Members: members}, response)
+
+	recorder = call(server, "GET", "/v1/groups/Nenhum/members", "")
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Equal(t, "group_not_found", decodeError(t, recorder))
+
+	recorder = call(server, "GET", "/v1/groups/Todos/members", "")
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	assert.Equal(t, "group_too_large", decodeError(t, recorder))
+
+	recorder = call(server, "GET", "/v1/groups/Vendas", "")
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Equal(t, "not_found", decodeError(t, recorder))
+}
+
+func TestServe_ShutsDownOnCancel(t *testing.T) {
+	server, _ := newTestServer()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Erro ao abrir o listener: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(ctx, listener) }()
+
+	resp, err := http.Get("http://" + listener.Addr().String() + "/v1/users/user")
+	if err != nil {
+		t.Fatalf("Erro na chamada ao servidor: %v", err)
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("O servidor não encerrou após o cancelamento")
+	}
+}
@@ -1,18 +1,24 @@
 package interfaces
 
-import "auth-ad/src/internal/models"
+import (
+	"auth-ad/src/internal/models"
+	"context"
+)
 
 type IActiveDirectoryRepository interface {
-	Authenticate(username, password string) (bool, error)
-	GetUser(username string) (*models.ADUser, error)
-	GetUsers(group string) ([]*models.ADUser, error)
-	Bind(username, password string) error
-	Unbind() error
+	Authenticate(ctx context.Context, username, password string) (bool, error)
+	GetUser(ctx context.Context, username string) (*models.ADUser, error)
+	GetUsers(ctx context.Context, group string) ([]*models.ADUser, error)
+	IsMemberOf(ctx context.Context, username, group string) (bool, error)
+	Bind(ctx context.Context, username, password string) error
+	Unbind(ctx context.Context) error
 	Close() error
 }
 
 type IActiveDirectoryService interface {
-	Authenticate(username, password string) (bool, error)
-	GetUser(username string) (models.UserData, error)
-	Unbind() error
+	Authenticate(ctx context.Context, username, password string) (bool, error)
+	GetUser(ctx context.Context, username string) (models.UserData, error)
+	GetUsers(ctx context.Context, group string) ([]models.UserData, error)
+	IsMemberOf(ctx context.Context, username, group string) (bool, error)
+	Unbind(ctx context.Context) error
 }
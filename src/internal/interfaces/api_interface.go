@@ -1,13 +1,22 @@
 package interfaces
 
-import "auth-ad/src/internal/models"
+import (
+	"auth-ad/src/internal/models"
+	"context"
+	"time"
+)
 
 type IApiRepository interface {
-	GetRequest() ([]models.AuthRequest, error)
-	SendResponse(requestId string, response models.AuthResponse) error
+	GetRequest(ctx context.Context) ([]models.AuthRequest, error)
+	SendResponse(ctx context.Context, requestId string, response models.AuthResponse) error
+	Claim(ctx context.Context, max int, ttl time.Duration) ([]models.ClaimedRequest, error)
+	Heartbeat(ctx context.Context, lease models.Lease, ttl time.Duration) (models.Lease, error)
+	Release(ctx context.Context, lease models.Lease) error
+	Ack(ctx context.Context, lease models.Lease, response models.AuthResponse) error
 }
 
 type IApiService interface {
-	GetRequest() ([]models.AuthRequest, error)
-	SendResponse(requestID string, response models.AuthResponse) error
+	GetRequest(ctx context.Context) ([]models.AuthRequest, error)
+	SendResponse(ctx context.Context, requestID string, response models.AuthResponse) error
+	Release(ctx context.Context, requestID string) error
 }
@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"auth-ad/src/internal/models"
+	"context"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -12,14 +13,14 @@ type IActiveDirectoryInterface struct {
 }
 
 // Authenticate é um mock para o método Authenticate
-func (m *IActiveDirectoryInterface) Authenticate(username, password string) (bool, error) {
-	args := m.Called(username, password)
+func (m *IActiveDirectoryInterface) Authenticate(ctx context.Context, username, password string) (bool, error) {
+	args := m.Called(ctx, username, password)
 	return args.Bool(0), args.Error(1)
 }
 
 // GetUser é um mock para o método GetUser
-func (m *IActiveDirectoryInterface) GetUser(username string) (*models.ADUser, error) {
-	args := m.Called(username)
+func (m *IActiveDirectoryInterface) GetUser(ctx context.Context, username string) (*models.ADUser, error) {
+	args := m.Called(ctx, username)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -27,23 +28,29 @@ func (m *IActiveDirectoryInterface) GetUser(username string) (*models.ADUser, er
 }
 
 // GetUsers é um mock para o método GetUsers
-func (m *IActiveDirectoryInterface) GetUsers(group string) ([]*models.ADUser, error) {
-	args := m.Called(group)
+func (m *IActiveDirectoryInterface) GetUsers(ctx context.Context, group string) ([]*models.ADUser, error) {
+	args := m.Called(ctx, group)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*models.ADUser), args.Error(1)
 }
 
+// IsMemberOf é um mock para o método IsMemberOf
+func (m *IActiveDirectoryInterface) IsMemberOf(ctx context.Context, username, group string) (bool, error) {
+	args := m.Called(ctx, username, group)
+	return args.Bool(0), args.Error(1)
+}
+
 // Bind é um mock para o método Bind
-func (m *IActiveDirectoryInterface) Bind(username, password string) error {
-	args := m.Called(username, password)
+func (m *IActiveDirectoryInterface) Bind(ctx context.Context, username, password string) error {
+	args := m.Called(ctx, username, password)
 	return args.Error(0)
 }
 
 // Unbind é um mock para o método Unbind
-func (m *IActiveDirectoryInterface) Unbind() error {
-	args := m.Called()
+func (m *IActiveDirectoryInterface) Unbind(ctx context.Context) error {
+	args := m.Called(ctx)
 	return args.Error(0)
 }
 
@@ -52,3 +59,38 @@ func (m *IActiveDirectoryInterface) Close() error {
 	args := m.Called()
 	return args.Error(0)
 }
+
+// IActiveDirectoryService é um mock para a interface IActiveDirectoryService
+type IActiveDirectoryService struct {
+	mock.Mock
+}
+
+// Authenticate é um mock para o método Authenticate
+func (m *IActiveDirectoryService) Authenticate(ctx context.Context, username, password string) (bool, error) {
+	args := m.Called(ctx, username, password)
+	return args.Bool(0), args.Error(1)
+}
+
+// GetUser é um mock para o método GetUser
+func (m *IActiveDirectoryService) GetUser(ctx context.Context, username string) (models.UserData, error) {
+	args := m.Called(ctx, username)
+	return args.Get(0).(models.UserData), args.Error(1)
+}
+
+// GetUsers é um mock para o método GetUsers
+func (m *IActiveDirectoryService) GetUsers(ctx context.Context, group string) ([]models.UserData, error) {
+	args := m.Called(ctx, group)
+	return args.Get(0).([]models.UserData), args.Error(1)
+}
+
+// IsMemberOf é um mock para o método IsMemberOf
+func (m *IActiveDirectoryService) IsMemberOf(ctx context.Context, username, group string) (bool, error) {
+	args := m.Called(ctx, username, group)
+	return args.Bool(0), args.Error(1)
+}
+
+// Unbind é um mock para o método Unbind
+func (m *IActiveDirectoryService) Unbind(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
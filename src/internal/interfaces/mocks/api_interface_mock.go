@@ -2,6 +2,8 @@ package mocks
 
 import (
 	"auth-ad/src/internal/models"
+	"context"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -10,12 +12,51 @@ type IApiRepository struct {
 	mock.Mock
 }
 
-func (a *IApiRepository) GetRequest() ([]models.AuthRequest, error) {
-	args := a.Called()
+func (a *IApiRepository) GetRequest(ctx context.Context) ([]models.AuthRequest, error) {
+	args := a.Called(ctx)
 	return args.Get(0).([]models.AuthRequest), args.Error(1)
 }
 
-func (a *IApiRepository) SendResponse(requestId string, response models.AuthResponse) error {
-	args := a.Called(requestId, response)
+func (a *IApiRepository) SendResponse(ctx context.Context, requestId string, response models.AuthResponse) error {
+	args := a.Called(ctx, requestId, response)
+	return args.Error(0)
+}
+
+func (a *IApiRepository) Claim(ctx context.Context, max int, ttl time.Duration) ([]models.ClaimedRequest, error) {
+	args := a.Called(ctx, max, ttl)
+	return args.Get(0).([]models.ClaimedRequest), args.Error(1)
+}
+
+func (a *IApiRepository) Heartbeat(ctx context.Context, lease models.Lease, ttl time.Duration) (models.Lease, error) {
+	args := a.Called(ctx, lease, ttl)
+	return args.Get(0).(models.Lease), args.Error(1)
+}
+
+func (a *IApiRepository) Release(ctx context.Context, lease models.Lease) error {
+	args := a.Called(ctx, lease)
+	return args.Error(0)
+}
+
+func (a *IApiRepository) Ack(ctx context.Context, lease models.Lease, response models.AuthResponse) error {
+	args := a.Called(ctx, lease, response)
+	return args.Error(0)
+}
+
+type IApiService struct {
+	mock.Mock
+}
+
+func (a *IApiService) GetRequest(ctx context.Context) ([]models.AuthRequest, error) {
+	args := a.Called(ctx)
+	return args.Get(0).([]models.AuthRequest), args.Error(1)
+}
+
+func (a *IApiService) SendResponse(ctx context.Context, requestID string, response models.AuthResponse) error {
+	args := a.Called(ctx, requestID, response)
+	return args.Error(0)
+}
+
+func (a *IApiService) Release(ctx context.Context, requestID string) error {
+	args := a.Called(ctx, requestID)
 	return args.Error(0)
 }
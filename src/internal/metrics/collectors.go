@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	authenticationsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "authentications_total"),
+		"Autenticações processadas, por resultado (success ou failure) e motivo da falha. Respostas reenviadas a duplicatas não são contadas.",
+		[]string{"result", "reason"}, nil,
+	)
+	receivedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "requests_received_total"),
+		"Requisições de autenticação recebidas nas consultas à API.",
+		nil, nil,
+	)
+	duplicatesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "duplicate_requests_total"),
+		"Requisições duplicadas, por tratamento: skipped (ainda na fila ou em processamento) ou replayed (respondidas com a resposta anterior).",
+		[]string{"action"}, nil,
+	)
+	poolConnectionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "ldap_pool", "connections"),
+		"Conexões abertas no pool LDAP, por estado (idle ou in_use).",
+		[]string{"state"}, nil,
+	)
+	poolMaxDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "ldap_pool", "max_connections"),
+		"Tamanho máximo do pool LDAP.",
+		nil, nil,
+	)
+)
+
+// authenticationCollector lê os contadores mantidos pelo processamento das requisições
+type authenticationCollector struct {
+	authentication Authentication
+}
+
+// Describe envia as descrições das métricas
+func (c *authenticationCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- authenticationsDesc
+	ch <- receivedDesc
+	ch <- duplicatesDesc
+}
+
+// Collect envia os valores atuais das métricas
+func (c *authenticationCollector) Collect(ch chan<- prometheus.Metric) {
+	outcomes := c.authentication.Outcomes()
+	ch <- prometheus.MustNewConstMetric(authenticationsDesc, prometheus.CounterValue, float64(outcomes.Succeeded), "success", "")
+	for reason, count := range outcomes.Failed {
+		ch <- prometheus.MustNewConstMetric(authenticationsDesc, prometheus.CounterValue, float64(count), "failure", string(reason))
+	}
+
+	dedup := c.authentication.DedupStats()
+	ch <- prometheus.MustNewConstMetric(receivedDesc, prometheus.CounterValue, float64(dedup.Received))
+	ch <- prometheus.MustNewConstMetric(duplicatesDesc, prometheus.CounterValue, float64(dedup.Skipped), "skipped")
+	ch <- prometheus.MustNewConstMetric(duplicatesDesc, prometheus.CounterValue, float64(dedup.Replayed), "replayed")
+}
+
+// poolCollector lê a ocupação do pool de conexões LDAP
+type poolCollector struct {
+	pool LDAPPool
+}
+
+// Describe envia as descrições das métricas
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolConnectionsDesc
+	ch <- poolMaxDesc
+}
+
+// Collect envia os valores atuais das métricas
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.pool.Stats()
+	ch <- prometheus.MustNewConstMetric(poolConnectionsDesc, prometheus.GaugeValue, float64(stats.Idle), "idle")
+	ch <- prometheus.MustNewConstMetric(poolConnectionsDesc, prometheus.GaugeValue, float64(stats.InUse), "in_use")
+	ch <- prometheus.MustNewConstMetric(poolMaxDesc, prometheus.GaugeValue, float64(stats.Max))
+}
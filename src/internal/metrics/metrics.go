@@ -0,0 +1,208 @@
+package metrics
+
+import (
+	"auth-ad/src/internal/authentication"
+	"auth-ad/src/internal/models"
+	"auth-ad/src/internal/repositories/microsoftActiveDirectory"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace é o prefixo dos nomes das métricas do serviço
+const namespace = "auth_ad"
+
+// Resultados de uma operação no AD, usados no rótulo outcome
+const (
+	OutcomeSuccess = "success" // Operação concluída
+	OutcomeFailure = "failure" // Recusada pelo AD: credenciais inválidas, usuário ou grupo inexistente
+	OutcomeError   = "error"   // Falha sistêmica: AD inacessível, tempo esgotado
+)
+
+// Authentication é a parte do processamento das requisições lida pelas métricas
+type Authentication interface {
+	QueueDepth() int
+	DedupStats() authentication.DedupStats
+	Outcomes() authentication.OutcomeStats
+}
+
+// LDAPPool é a parte do pool de conexões LDAP lida pelas métricas
+type LDAPPool interface {
+	Stats() microsoftActiveDirectory.PoolStats
+}
+
+// LDAPRoundTrip descreve uma operação concluída no AD
+type LDAPRoundTrip struct {
+	Operation string        // Operação executada: bind ou search
+	Outcome   string        // Resultado: success, failure ou error
+	Duration  time.Duration // Duração da operação
+	Time      time.Time     // Momento da conclusão
+}
+
+// Metrics reúne as métricas Prometheus do serviço em um registro próprio, exposto por Handler.
+// Contadores e gauges que já são mantidos pelos componentes (resultados das autenticações,
+// deduplicação, fila e pool) são lidos no momento da coleta.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	// ldapDuration é a latência das operações no AD, por operação e resultado
+	ldapDuration *prometheus.HistogramVec
+	// apiDuration é a latência das chamadas HTTP à API, por método e status
+	apiDuration *prometheus.HistogramVec
+	// lastLDAP é a última operação concluída no AD, exibida no estado do serviço
+	lastLDAP atomic.Pointer[LDAPRoundTrip]
+}
+
+// NewMetrics cria o registro de métricas com as métricas do runtime Go, do processo e a
+// identificação do build (auth_ad_build_info)
+// Retorna:
+//   - *Metrics: Métricas do serviço
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		ldapDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "ldap_operation_duration_seconds",
+			Help:      "Latência das operações no Active Directory, por operação (bind ou search) e resultado.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation", "outcome"}),
+		apiDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "api_request_duration_seconds",
+			Help:      "Latência das chamadas HTTP à API Smarket, por método e status da resposta (error quando não há resposta).",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "status"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		newBuildInfo(),
+		m.ldapDuration,
+		m.apiDuration,
+	)
+
+	return m
+}
+
+// Handler retorna o handler HTTP que expõe as métricas no formato de texto do Prometheus
+// Retorna:
+//   - http.Handler: Handler das métricas
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveLDAP registra a latência de uma operação no AD
+// Parâmetros:
+//   - operation: Operação executada (bind ou search)
+//   - duration: Duração da operação
+//   - err: Erro retornado pela operação
+func (m *Metrics) ObserveLDAP(operation string, duration time.Duration, err error) {
+	result := outcome(err)
+	m.ldapDuration.WithLabelValues(operation, result).Observe(duration.Seconds())
+	m.lastLDAP.Store(&LDAPRoundTrip{Operation: operation, Outcome: result, Duration: duration, Time: time.Now()})
+}
+
+// LastLDAP retorna a última operação concluída no AD
+// Retorna:
+//   - LDAPRoundTrip: Última operação
+//   - bool: Falso enquanto nenhuma operação foi concluída
+func (m *Metrics) LastLDAP() (LDAPRoundTrip, bool) {
+	last := m.lastLDAP.Load()
+	if last == nil {
+		return LDAPRoundTrip{}, false
+	}
+
+	return *last, true
+}
+
+// InstrumentTransport envolve um http.RoundTripper, registrando a latência de cada chamada
+// Parâmetros:
+//   - next: Transporte usado nas chamadas
+//
+// Retorna:
+//   - http.RoundTripper: Transporte instrumentado
+func (m *Metrics) InstrumentTransport(next http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+		started := time.Now()
+		response, err := next.RoundTrip(request)
+
+		status := "error"
+		if err == nil {
+			status = strconv.Itoa(response.StatusCode)
+		}
+		m.apiDuration.WithLabelValues(request.Method, status).Observe(time.Since(started).Seconds())
+
+		return response, err
+	})
+}
+
+// RegisterAuthentication expõe os resultados das autenticações por motivo, os contadores de
+// deduplicação e a profundidade da fila de requisições
+// Parâmetros:
+//   - authentication: Processamento das requisições de autenticação
+func (m *Metrics) RegisterAuthentication(authentication Authentication) {
+	m.registry.MustRegister(
+		&authenticationCollector{authentication: authentication},
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "queue_depth",
+			Help:      "Requisições de autenticação aguardando um worker livre.",
+		}, func() float64 { return float64(authentication.QueueDepth()) }),
+	)
+}
+
+// RegisterLDAPPool expõe a ocupação do pool de conexões LDAP
+// Parâmetros:
+//   - pool: Pool de conexões LDAP
+func (m *Metrics) RegisterLDAPPool(pool LDAPPool) {
+	m.registry.MustRegister(&poolCollector{pool: pool})
+}
+
+// outcome classifica o erro de uma operação no AD
+func outcome(err error) string {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case models.IsRequestError(err):
+		return OutcomeFailure
+	}
+
+	return OutcomeError
+}
+
+// roundTripperFunc adapta uma função a http.RoundTripper
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip executa a chamada
+func (f roundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+// newBuildInfo cria a métrica constante com a versão do módulo, a revisão do código e a versão
+// do Go, lidas das informações de build do binário
+func newBuildInfo() prometheus.Collector {
+	version, revision, goVersion := "unknown", "unknown", "unknown"
+	if info, ok := debug.ReadBuildInfo(); ok {
+		version = info.Main.Version
+		goVersion = info.GoVersion
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				revision = setting.Value
+			}
+		}
+	}
+
+	return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "build_info",
+		Help:        "Identificação do build do serviço; o valor é sempre 1.",
+		ConstLabels: prometheus.Labels{"version": version, "revision": revision, "go_version": goVersion},
+	}, func() float64 { return 1 })
+}
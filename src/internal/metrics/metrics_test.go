@@ -0,0 +1,169 @@
+package metrics
+
+import (
+	"auth-ad/src/internal/authentication"
+	"auth-ad/src/internal/models"
+	"auth-ad/src/internal/repositories/microsoftActiveDirectory"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAuthentication simula os contadores do processamento das requisições
+type fakeAuthentication struct{}
+
+func (fakeAuthentication) QueueDepth() int { return 3 }
+
+func (fakeAuthentication) DedupStats() authentication.DedupStats {
+	return authentication.DedupStats{Received: 10, Skipped: 2, Replayed: 1}
+}
+
+func (fakeAuthentication) Outcomes() authentication.OutcomeStats {
+	return authentication.OutcomeStats{
+		Succeeded: 5,
+		Failed: map[models.FailureReason]uint64{
+			models.ReasonInvalidCredentials:   3,
+			models.ReasonDirectoryUnavailable: 1,
+		},
+	}
+}
+
+// fakePool simula a ocupação do pool de conexões LDAP
+type fakePool struct{}
+
+func (fakePool) Stats() microsoftActiveDirectory.PoolStats {
+	return microsoftActiveDirectory.PoolStats{Open: 3, Idle: 1, InUse: 2, Max: 4}
+}
+
+func TestRegisterAuthentication(t *testing.T) {
+	m := NewMetrics()
+	m.RegisterAuthentication(fakeAuthentication{})
+
+	expected := `
+# HELP auth_ad_authentications_total Autenticações processadas, por resultado (success ou failure) e motivo da falha. Respostas reenviadas a duplicatas não são contadas.
+# TYPE auth_ad_authentications_total counter
+auth_ad_authentications_total{reason="",result="success"} 5
+auth_ad_authentications_total{reason="directory_unavailable",result="failure"} 1
+auth_ad_authentications_total{reason="invalid_credentials",result="failure"} 3
+# HELP auth_ad_duplicate_requests_total Requisições duplicadas, por tratamento: skipped (ainda na fila ou em processamento) ou replayed (respondidas com a resposta anterior).
+# TYPE auth_ad_duplicate_requests_total counter
+auth_ad_duplicate_requests_total{action="replayed"} 1
+auth_ad_duplicate_requests_total{action="skipped"} 2
+# HELP auth_ad_queue_depth Requisições de autenticação aguardando um worker livre.
+# TYPE auth_ad_queue_depth gauge
+auth_ad_queue_depth 3
+`
+	err := testutil.GatherAndCompare(m.registry, strings.NewReader(expected),
+		"auth_ad_authentications_total", "auth_ad_duplicate_requests_total", "auth_ad_queue_depth")
+	assert.NoError(t, err)
+}
+
+func TestRegisterLDAPPool(t *testing.T) {
+	m := NewMetrics()
+	m.RegisterLDAPPool(fakePool{})
+
+	expected := `
+# HELP auth_ad_ldap_pool_connections Conexões abertas no pool LDAP, por estado (idle ou in_use).
+# TYPE auth_ad_ldap_pool_connections gauge
+auth_ad_ldap_pool_connections{state="idle"} 1
+auth_ad_ldap_pool_connections{state="in_use"} 2
+# HELP auth_ad_ldap_pool_max_connections Tamanho máximo do pool LDAP.
+# TYPE auth_ad_ldap_pool_max_connections gauge
+auth_ad_ldap_pool_max_connections 4
+`
+	err := testutil.GatherAndCompare(m.registry, strings.NewReader(expected),
+		"auth_ad_ldap_pool_connections", "auth_ad_ldap_pool_max_connections")
+	assert.NoError(t, err)
+}
+
+func TestObserveLDAP(t *testing.T) {
+	m := NewMetrics()
+
+	_, ok := m.LastLDAP()
+	assert.False(t, ok)
+
+	m.ObserveLDAP("bind", time.Millisecond, nil)
+	m.ObserveLDAP("bind", time.Millisecond, models.ErrInvalidCredentials)
+	m.ObserveLDAP("search", time.Millisecond, errors.New("LDAP Result Code 200"))
+	m.ObserveLDAP("search", time.Millisecond, models.ErrUserNotFound)
+
+	assert.Equal(t, 4, testutil.CollectAndCount(m.ldapDuration))
+	assert.Equal(t, uint64(1), histogramCount(t, m, "auth_ad_ldap_operation_duration_seconds", map[string]string{"operation": "search", "outcome": OutcomeError}))
+	assert.Equal(t, uint64(1), histogramCount(t, m, "auth_ad_ldap_operation_duration_seconds", map[string]string{"operation": "bind", "outcome": OutcomeFailure}))
+
+	last, ok := m.LastLDAP()
+	assert.True(t, ok)
+	assert.Equal(t, "search", last.Operation)
+	assert.Equal(t, OutcomeFailure, last.Outcome)
+	assert.Equal(t, time.Millisecond, last.Duration)
+}
+
+func TestInstrumentTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	m := NewMetrics()
+	client := &http.Client{Transport: m.InstrumentTransport(http.DefaultTransport)}
+
+	response, err := client.Get(server.URL)
+	assert.NoError(t, err)
+	response.Body.Close()
+
+	// Sem resposta do servidor, o status é error
+	server.Close()
+	_, err = client.Post(server.URL, "application/json", nil)
+	assert.Error(t, err)
+
+	assert.Equal(t, uint64(1), histogramCount(t, m, "auth_ad_api_request_duration_seconds", map[string]string{"method": "GET", "status": "503"}))
+	assert.Equal(t, uint64(1), histogramCount(t, m, "auth_ad_api_request_duration_seconds", map[string]string{"method": "POST", "status": "error"}))
+}
+
+func TestHandler(t *testing.T) {
+	m := NewMetrics()
+	server := httptest.NewServer(m.Handler())
+	defer server.Close()
+
+	response, err := http.Get(server.URL)
+	assert.NoError(t, err)
+	defer response.Body.Close()
+
+	body, _ := io.ReadAll(response.Body)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Contains(t, string(body), "auth_ad_build_info{")
+	assert.Contains(t, string(body), "go_goroutines")
+}
+
+// histogramCount retorna a quantidade de observações da série do histograma com os rótulos informados
+func histogramCount(t *testing.T, m *Metrics, name string, labels map[string]string) uint64 {
+	families, err := m.registry.Gather()
+	if err != nil {
+		t.Fatalf("Erro ao coletar as métricas: %v", err)
+	}
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+
+	series:
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if labels[label.GetName()] != label.GetValue() {
+					continue series
+				}
+			}
+			return metric.GetHistogram().GetSampleCount()
+		}
+	}
+
+	return 0
+}
@@ -7,6 +7,7 @@ type ADUser struct {
 	DN                string
 	CN                string
 	SAMAccountName    string
+	SID               string
 	Groups            []string
 	UserPrincipalName string
 }
@@ -1,7 +1,29 @@
 package models
 
+import (
+	"fmt"
+	"log/slog"
+)
+
 type AuthRequest struct {
 	RequestID string `json:"request_id"`
 	Username  string `json:"username"`
 	Password  string `json:"password"`
+	Tenant    string `json:"tenant,omitempty"`
+	Source    string `json:"source,omitempty"` // Endereço do usuário final, repassado pelo chamador e usado como origem na limitação de tentativas
+}
+
+// LogValue representa a requisição nos logs sem a senha
+func (r AuthRequest) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("request_id", r.RequestID),
+		slog.String("username", r.Username),
+		slog.String("tenant", r.Tenant),
+		slog.String("source", r.Source),
+	)
+}
+
+// String representa a requisição em mensagens formatadas (%v, %+v) sem a senha
+func (r AuthRequest) String() string {
+	return fmt.Sprintf("{RequestID:%s Username:%s Tenant:%s Source:%s}", r.RequestID, r.Username, r.Tenant, r.Source)
 }
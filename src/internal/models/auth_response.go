@@ -1,7 +1,8 @@
 package models
 
 type AuthResponse struct {
-	RequestID string   `json:"request_id"`
-	Success   bool     `json:"success"`
-	UserData  UserData `json:"user_data"`
+	RequestID string        `json:"request_id"`
+	Success   bool          `json:"success"`
+	Reason    FailureReason `json:"reason,omitempty"`
+	UserData  UserData      `json:"user_data"`
 }
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Erros de uma requisição específica: a requisição deve ser respondida com falha e o
+// processamento das demais continua normalmente
+var (
+	// ErrInvalidUsername indica um nome de usuário vazio, longo demais ou com caracteres inválidos
+	ErrInvalidUsername = errors.New("nome de usuário inválido")
+	// ErrInvalidCredentials indica usuário ou senha incorretos
+	ErrInvalidCredentials = errors.New("credenciais inválidas")
+	// ErrUserNotFound indica que o usuário não existe no AD
+	ErrUserNotFound = errors.New("usuário não encontrado")
+	// ErrGroupNotFound indica que o grupo não existe no AD
+	ErrGroupNotFound = errors.New("grupo não encontrado")
+	// ErrGroupTooLarge indica que o grupo tem mais membros que o limite da consulta dos membros
+	ErrGroupTooLarge = errors.New("grupo com mais membros que o limite da consulta")
+	// ErrTooManyAttempts indica que o usuário ou a origem da requisição estão bloqueados ou devem
+	// aguardar por excesso de falhas, sem consulta ao AD. A espera sugerida é obtida por RetryAfter.
+	ErrTooManyAttempts = errors.New("excesso de tentativas de autenticação")
+)
+
+// ErrDirectoryUnavailable indica que o AD não pôde ser acessado. É uma falha sistêmica,
+// que afeta todas as requisições.
+var ErrDirectoryUnavailable = errors.New("diretório indisponível")
+
+// IsRequestError indica se o erro se refere apenas à requisição em processamento
+// (credenciais inválidas, usuário ou grupo inexistente, grupo grande demais, usuário inválido,
+// excesso de tentativas), e não a uma falha sistêmica
+// Parâmetros:
+//   - err: Erro a ser classificado
+//
+// Retorna:
+//   - bool: Verdadeiro para erros da requisição, falso para falhas sistêmicas
+func IsRequestError(err error) bool {
+	return err != nil && ReasonFor(err) != ReasonDirectoryUnavailable
+}
+
+// RetryAfter retorna a espera sugerida antes de uma nova tentativa, quando o erro a informa, como
+// nas recusas da limitação de tentativas
+// Parâmetros:
+//   - err: Erro retornado na autenticação
+//
+// Retorna:
+//   - time.Duration: Espera sugerida
+//   - bool: Verdadeiro caso o erro informe a espera
+func RetryAfter(err error) (time.Duration, bool) {
+	var hint interface{ RetryAfter() time.Duration }
+	if errors.As(err, &hint) {
+		return hint.RetryAfter(), true
+	}
+
+	return 0, false
+}
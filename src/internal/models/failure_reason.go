@@ -0,0 +1,79 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// FailureReason identifica, de forma legível por máquina, o motivo da falha de uma autenticação
+type FailureReason string
+
+const (
+	ReasonInvalidCredentials   FailureReason = "invalid_credentials"   // Usuário ou senha incorretos
+	ReasonAccountDisabled      FailureReason = "account_disabled"      // Conta desabilitada
+	ReasonAccountLocked        FailureReason = "account_locked"        // Conta bloqueada por excesso de tentativas
+	ReasonAccountExpired       FailureReason = "account_expired"       // Conta expirada
+	ReasonPasswordExpired      FailureReason = "password_expired"      // Senha expirada
+	ReasonMustChangePassword   FailureReason = "must_change_password"  // Senha deve ser alterada no próximo logon
+	ReasonLogonRestricted      FailureReason = "logon_restricted"      // Logon não permitido neste horário ou estação
+	ReasonUserNotFound         FailureReason = "user_not_found"        // Usuário inexistente
+	ReasonGroupNotFound        FailureReason = "group_not_found"       // Grupo inexistente, na consulta dos membros de um grupo
+	ReasonGroupTooLarge        FailureReason = "group_too_large"       // Grupo com mais membros que AD_GROUP_MEMBERS_LIMIT, na consulta dos membros
+	ReasonDirectoryUnavailable FailureReason = "directory_unavailable" // AD inacessível
+	ReasonTooManyAttempts      FailureReason = "too_many_attempts"     // Usuário ou origem bloqueados temporariamente por excesso de falhas
+)
+
+// AuthError é um erro de autenticação acompanhado do motivo da falha
+type AuthError struct {
+	Reason FailureReason
+	Err    error
+}
+
+// Error retorna a descrição do erro
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Reason, e.Err)
+}
+
+// Unwrap retorna o erro original
+func (e *AuthError) Unwrap() error {
+	return e.Err
+}
+
+// Is permite comparar o erro com ErrUserNotFound e ErrInvalidCredentials de acordo com o motivo
+func (e *AuthError) Is(target error) bool {
+	switch target {
+	case ErrUserNotFound:
+		return e.Reason == ReasonUserNotFound
+	case ErrInvalidCredentials:
+		return e.Reason != ReasonUserNotFound && e.Reason != ReasonDirectoryUnavailable && e.Reason != ReasonTooManyAttempts
+	}
+
+	return false
+}
+
+// ReasonFor determina o motivo de falha correspondente a um erro de autenticação.
+// Erros não reconhecidos são tratados como indisponibilidade do diretório.
+// Parâmetros:
+//   - err: Erro retornado na autenticação ou na busca do usuário
+//
+// Retorna:
+//   - FailureReason: Motivo da falha
+func ReasonFor(err error) FailureReason {
+	var authErr *AuthError
+	switch {
+	case errors.As(err, &authErr):
+		return authErr.Reason
+	case errors.Is(err, ErrInvalidCredentials), errors.Is(err, ErrInvalidUsername):
+		return ReasonInvalidCredentials
+	case errors.Is(err, ErrUserNotFound):
+		return ReasonUserNotFound
+	case errors.Is(err, ErrGroupNotFound):
+		return ReasonGroupNotFound
+	case errors.Is(err, ErrGroupTooLarge):
+		return ReasonGroupTooLarge
+	case errors.Is(err, ErrTooManyAttempts):
+		return ReasonTooManyAttempts
+	}
+
+	return ReasonDirectoryUnavailable
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrLeaseLost indica que o lease de uma requisição expirou ou foi assumido por outra instância.
+// A requisição não deve ser respondida por esta instância.
+var ErrLeaseLost = errors.New("lease da requisição perdido")
+
+// Lease é a reserva temporária de uma requisição para uma instância do serviço. Enquanto o lease
+// é renovado, a API não entrega a requisição a outras instâncias. Um lease sem ID é local: a API
+// não suporta leases e a requisição foi obtida pela consulta simples.
+type Lease struct {
+	ID        string    `json:"lease_id"`
+	RequestID string    `json:"request_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ClaimedRequest é uma requisição reservada com seu lease
+type ClaimedRequest struct {
+	Request AuthRequest `json:"request"`
+	Lease   Lease       `json:"lease"`
+}
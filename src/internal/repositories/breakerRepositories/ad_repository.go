@@ -0,0 +1,150 @@
+package breakerRepositories
+
+import (
+	"auth-ad/src/internal/interfaces"
+	"auth-ad/src/internal/models"
+	"auth-ad/src/pkg/circuitBreaker"
+	"context"
+	"fmt"
+)
+
+// ADRepository protege o repositório do Active Directory com um circuit breaker. Enquanto o
+// circuito está aberto, as chamadas falham imediatamente com models.ErrDirectoryUnavailable,
+// sem aguardar o timeout de conexão com o AD. Apenas falhas sistêmicas abrem o circuito;
+// credenciais inválidas e usuários inexistentes são respostas normais do AD.
+type ADRepository struct {
+	repository interfaces.IActiveDirectoryRepository
+	breaker    *circuitBreaker.CircuitBreaker
+}
+
+// NewADRepository cria o repositório do Active Directory protegido pelo circuit breaker
+// Params:
+//   - repository: Repositório do Active Directory
+//   - breaker: Circuit breaker do AD
+//
+// Returns:
+//   - interfaces.IActiveDirectoryRepository: Interface implementada do repositório
+func NewADRepository(repository interfaces.IActiveDirectoryRepository, breaker *circuitBreaker.CircuitBreaker) interfaces.IActiveDirectoryRepository {
+	return &ADRepository{repository: repository, breaker: breaker}
+}
+
+// Authenticate realiza a autenticação do usuário no Active Directory
+// Params:
+//   - ctx: Contexto da operação
+//   - username: Nome do usuário
+//   - password: Senha do usuário
+//
+// Returns:
+//   - bool: true se autenticação for bem sucedida
+//   - error: Erro em caso de falha na autenticação
+func (r *ADRepository) Authenticate(ctx context.Context, username, password string) (bool, error) {
+	var authenticated bool
+	err := r.guard(ctx, func() (err error) {
+		authenticated, err = r.repository.Authenticate(ctx, username, password)
+		return err
+	})
+
+	return authenticated, err
+}
+
+// GetUser busca um usuário no Active Directory
+// Params:
+//   - ctx: Contexto da operação
+//   - username: Nome do usuário
+//
+// Returns:
+//   - *models.ADUser: Usuário encontrado
+//   - error: Erro em caso de falha na busca
+func (r *ADRepository) GetUser(ctx context.Context, username string) (*models.ADUser, error) {
+	var user *models.ADUser
+	err := r.guard(ctx, func() (err error) {
+		user, err = r.repository.GetUser(ctx, username)
+		return err
+	})
+
+	return user, err
+}
+
+// GetUsers busca os usuários de um grupo no Active Directory
+// Params:
+//   - ctx: Contexto da operação
+//   - group: Nome do grupo
+//
+// Returns:
+//   - []*models.ADUser: Usuários encontrados
+//   - error: Erro em caso de falha na busca
+func (r *ADRepository) GetUsers(ctx context.Context, group string) ([]*models.ADUser, error) {
+	var users []*models.ADUser
+	err := r.guard(ctx, func() (err error) {
+		users, err = r.repository.GetUsers(ctx, group)
+		return err
+	})
+
+	return users, err
+}
+
+// IsMemberOf indica se o usuário pertence ao grupo no Active Directory
+// Params:
+//   - ctx: Contexto da operação
+//   - username: Nome do usuário
+//   - group: Grupo no formato configurado
+//
+// Returns:
+//   - bool: Verdadeiro se o usuário pertence ao grupo
+//   - error: Erro em caso de falha na busca
+func (r *ADRepository) IsMemberOf(ctx context.Context, username, group string) (bool, error) {
+	var member bool
+	err := r.guard(ctx, func() (err error) {
+		member, err = r.repository.IsMemberOf(ctx, username, group)
+		return err
+	})
+
+	return member, err
+}
+
+// Bind valida as credenciais do usuário no Active Directory
+// Params:
+//   - ctx: Contexto da operação
+//   - username: Nome do usuário
+//   - password: Senha do usuário
+//
+// Returns:
+//   - error: Erro em caso de falha na validação
+func (r *ADRepository) Bind(ctx context.Context, username, password string) error {
+	return r.guard(ctx, func() error {
+		return r.repository.Bind(ctx, username, password)
+	})
+}
+
+// Unbind encerra a sessão com o Active Directory, sem passar pelo circuit breaker
+// Params:
+//   - ctx: Contexto da operação
+//
+// Returns:
+//   - error: Erro em caso de falha ao encerrar a sessão
+func (r *ADRepository) Unbind(ctx context.Context) error {
+	return r.repository.Unbind(ctx)
+}
+
+// Close fecha as conexões com o Active Directory, sem passar pelo circuit breaker
+// Returns:
+//   - error: Erro em caso de falha ao fechar as conexões
+func (r *ADRepository) Close() error {
+	return r.repository.Close()
+}
+
+// guard executa a chamada ao AD pelo circuit breaker, traduzindo o circuito aberto para
+// models.ErrDirectoryUnavailable
+func (r *ADRepository) guard(ctx context.Context, call func() error) error {
+	err := guard(ctx, r.breaker, isDirectoryFailure, call)
+	if err == circuitBreaker.ErrOpen {
+		return fmt.Errorf("%w: %w", models.ErrDirectoryUnavailable, err)
+	}
+
+	return err
+}
+
+// isDirectoryFailure indica se o erro é uma falha sistêmica do AD
+func isDirectoryFailure(err error) bool {
+	return !models.IsRequestError(err)
+}
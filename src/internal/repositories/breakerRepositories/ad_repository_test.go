@@ -0,0 +1,106 @@
+package breakerRepositories
+
+import (
+	"auth-ad/src/internal/interfaces/mocks"
+	"auth-ad/src/internal/models"
+	"auth-ad/src/pkg/circuitBreaker"
+	"auth-ad/src/pkg/configs"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// testBreakerConfig abre o circuito após duas falhas consecutivas
+var testBreakerConfig = configs.BreakerConfig{
+	FailureThreshold: 2,
+	OpenTimeout:      time.Hour,
+	HalfOpenMaxCalls: 1,
+	SuccessThreshold: 1,
+}
+
+func TestADRepository_OpensOnDirectoryFailures(t *testing.T) {
+	mockRepo := new(mocks.IActiveDirectoryInterface)
+	repository := NewADRepository(mockRepo, circuitBreaker.NewCircuitBreaker("ad", testBreakerConfig))
+	ctx := context.Background()
+
+	unavailable := fmt.Errorf("%w: timeout", models.ErrDirectoryUnavailable)
+	mockRepo.On("Authenticate", mock.Anything, "user", "pass").Return(false, unavailable).Times(2)
+
+	repository.Authenticate(ctx, "user", "pass")
+	repository.Authenticate(ctx, "user", "pass")
+
+	// Com o circuito aberto, a chamada falha sem acessar o AD
+	_, err := repository.Authenticate(ctx, "user", "pass")
+	assert.ErrorIs(t, err, models.ErrDirectoryUnavailable)
+	assert.ErrorIs(t, err, circuitBreaker.ErrOpen)
+	mockRepo.AssertNumberOfCalls(t, "Authenticate", 2)
+
+	// Unbind e Close não passam pelo circuito
+	mockRepo.On("Unbind", mock.Anything).Return(nil)
+	assert.NoError(t, repository.Unbind(ctx))
+}
+
+func TestADRepository_IgnoresRequestErrors(t *testing.T) {
+	mockRepo := new(mocks.IActiveDirectoryInterface)
+	breaker := circuitBreaker.NewCircuitBreaker("ad", testBreakerConfig)
+	repository := NewADRepository(mockRepo, breaker)
+	ctx := context.Background()
+
+	mockRepo.On("Authenticate", mock.Anything, "user", "wrong").Return(false, models.ErrInvalidCredentials)
+	mockRepo.On("GetUser", mock.Anything, "ghost").Return(nil, models.ErrUserNotFound)
+
+	for i := 0; i < 3; i++ {
+		_, err := repository.Authenticate(ctx, "user", "wrong")
+		assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+		_, err = repository.GetUser(ctx, "ghost")
+		assert.ErrorIs(t, err, models.ErrUserNotFound)
+	}
+
+	assert.Equal(t, circuitBreaker.StateClosed, breaker.State())
+
+}
+
+func TestADRepository_IgnoresCanceledCalls(t *testing.T) {
+	mockRepo := new(mocks.IActiveDirectoryInterface)
+	breaker := circuitBreaker.NewCircuitBreaker("ad", testBreakerConfig)
+	repository := NewADRepository(mockRepo, breaker)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	canceled := fmt.Errorf("%w: %w", models.ErrDirectoryUnavailable, context.Canceled)
+	mockRepo.On("Bind", mock.Anything, "user", "pass").Return(canceled)
+
+	for i := 0; i < 3; i++ {
+		err := repository.Bind(ctx, "user", "pass")
+		assert.False(t, errors.Is(err, circuitBreaker.ErrOpen))
+	}
+
+	assert.Equal(t, circuitBreaker.StateClosed, breaker.State())
+
+	// No estado semiaberto, uma chamada de teste cancelada não fecha o circuito e libera a vaga
+	breaker = circuitBreaker.NewCircuitBreaker("ad", configs.BreakerConfig{
+		FailureThreshold: 1,
+		OpenTimeout:      time.Millisecond,
+		HalfOpenMaxCalls: 1,
+		SuccessThreshold: 1,
+	})
+	repository = NewADRepository(mockRepo, breaker)
+	mockRepo.On("Bind", mock.Anything, "user", "down").Return(fmt.Errorf("%w: timeout", models.ErrDirectoryUnavailable)).Once()
+	mockRepo.On("Bind", mock.Anything, "user", "ok").Return(nil).Once()
+
+	repository.Bind(context.Background(), "user", "down")
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, circuitBreaker.StateHalfOpen, breaker.State())
+
+	repository.Bind(ctx, "user", "pass")
+	assert.Equal(t, circuitBreaker.StateHalfOpen, breaker.State())
+
+	assert.NoError(t, repository.Bind(context.Background(), "user", "ok"))
+	assert.Equal(t, circuitBreaker.StateClosed, breaker.State())
+}
@@ -0,0 +1,142 @@
+package breakerRepositories
+
+import (
+	"auth-ad/src/internal/interfaces"
+	"auth-ad/src/internal/models"
+	"auth-ad/src/pkg/circuitBreaker"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ApiRepository protege o repositório da API com um circuit breaker. Enquanto o circuito está
+// aberto, as chamadas falham imediatamente, sem esgotar as novas tentativas contra uma API fora do ar.
+type ApiRepository struct {
+	repository interfaces.IApiRepository
+	breaker    *circuitBreaker.CircuitBreaker
+}
+
+// NewApiRepository cria o repositório da API protegido pelo circuit breaker
+// Params:
+//   - repository: Repositório da API
+//   - breaker: Circuit breaker da API
+//
+// Returns:
+//   - interfaces.IApiRepository: Interface implementada do repositório
+func NewApiRepository(repository interfaces.IApiRepository, breaker *circuitBreaker.CircuitBreaker) interfaces.IApiRepository {
+	return &ApiRepository{repository: repository, breaker: breaker}
+}
+
+// GetRequest busca as requisições de autenticação pendentes
+// Params:
+//   - ctx: Contexto da operação
+//
+// Returns:
+//   - []models.AuthRequest: Requisições pendentes
+//   - error: Erro em caso de falha na busca
+func (r *ApiRepository) GetRequest(ctx context.Context) ([]models.AuthRequest, error) {
+	var requests []models.AuthRequest
+	err := r.guard(ctx, func() (err error) {
+		requests, err = r.repository.GetRequest(ctx)
+		return err
+	})
+
+	return requests, err
+}
+
+// SendResponse envia a resposta de uma requisição de autenticação
+// Params:
+//   - ctx: Contexto da operação
+//   - requestId: ID da requisição
+//   - response: Resposta da autenticação
+//
+// Returns:
+//   - error: Erro em caso de falha no envio
+func (r *ApiRepository) SendResponse(ctx context.Context, requestId string, response models.AuthResponse) error {
+	return r.guard(ctx, func() error {
+		return r.repository.SendResponse(ctx, requestId, response)
+	})
+}
+
+// Claim reserva requisições pendentes com um lease
+// Params:
+//   - ctx: Contexto da operação
+//   - max: Quantidade máxima de requisições reservadas
+//   - ttl: Duração do lease
+//
+// Returns:
+//   - []models.ClaimedRequest: Requisições reservadas
+//   - error: Erro em caso de falha na reserva
+func (r *ApiRepository) Claim(ctx context.Context, max int, ttl time.Duration) ([]models.ClaimedRequest, error) {
+	var claimed []models.ClaimedRequest
+	err := r.guard(ctx, func() (err error) {
+		claimed, err = r.repository.Claim(ctx, max, ttl)
+		return err
+	})
+
+	return claimed, err
+}
+
+// Heartbeat renova um lease
+// Params:
+//   - ctx: Contexto da operação
+//   - lease: Lease a ser renovado
+//   - ttl: Nova duração do lease
+//
+// Returns:
+//   - models.Lease: Lease renovado
+//   - error: Erro em caso de falha na renovação
+func (r *ApiRepository) Heartbeat(ctx context.Context, lease models.Lease, ttl time.Duration) (models.Lease, error) {
+	renewed := lease
+	err := r.guard(ctx, func() (err error) {
+		renewed, err = r.repository.Heartbeat(ctx, lease, ttl)
+		return err
+	})
+
+	return renewed, err
+}
+
+// Release libera um lease sem responder a requisição
+// Params:
+//   - ctx: Contexto da operação
+//   - lease: Lease a ser liberado
+//
+// Returns:
+//   - error: Erro em caso de falha na liberação
+func (r *ApiRepository) Release(ctx context.Context, lease models.Lease) error {
+	return r.guard(ctx, func() error {
+		return r.repository.Release(ctx, lease)
+	})
+}
+
+// Ack responde a requisição reservada e encerra o lease
+// Params:
+//   - ctx: Contexto da operação
+//   - lease: Lease da requisição
+//   - response: Resposta da autenticação
+//
+// Returns:
+//   - error: Erro em caso de falha no envio
+func (r *ApiRepository) Ack(ctx context.Context, lease models.Lease, response models.AuthResponse) error {
+	return r.guard(ctx, func() error {
+		return r.repository.Ack(ctx, lease, response)
+	})
+}
+
+// guard executa a chamada à API pelo circuit breaker
+func (r *ApiRepository) guard(ctx context.Context, call func() error) error {
+	err := guard(ctx, r.breaker, isApiFailure, call)
+	if err == circuitBreaker.ErrOpen {
+		return fmt.Errorf("API indisponível: %w", err)
+	}
+
+	return err
+}
+
+// isApiFailure indica se o erro é uma falha da API. Todo erro do gateway já passou pelas
+// novas tentativas e indica que a API não está respondendo corretamente, exceto a perda de
+// um lease, que é uma resposta normal quando outra instância assume a requisição.
+func isApiFailure(err error) bool {
+	return !errors.Is(err, models.ErrLeaseLost)
+}
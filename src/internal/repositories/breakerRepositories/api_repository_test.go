@@ -0,0 +1,65 @@
+package breakerRepositories
+
+import (
+	"auth-ad/src/internal/interfaces/mocks"
+	"auth-ad/src/internal/models"
+	"auth-ad/src/pkg/circuitBreaker"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestApiRepository_OpensOnFailures(t *testing.T) {
+	mockRepo := new(mocks.IApiRepository)
+	breaker := circuitBreaker.NewCircuitBreaker("api", testBreakerConfig)
+	repository := NewApiRepository(mockRepo, breaker)
+	ctx := context.Background()
+
+	mockRepo.On("GetRequest", mock.Anything).Return([]models.AuthRequest(nil), errors.New("status 503"))
+
+	repository.GetRequest(ctx)
+	repository.GetRequest(ctx)
+
+	_, err := repository.GetRequest(ctx)
+	assert.ErrorIs(t, err, circuitBreaker.ErrOpen)
+	mockRepo.AssertNumberOfCalls(t, "GetRequest", 2)
+
+	// O circuito é compartilhado entre as operações da API
+	err = repository.SendResponse(ctx, "123", models.AuthResponse{})
+	assert.ErrorIs(t, err, circuitBreaker.ErrOpen)
+	mockRepo.AssertNotCalled(t, "SendResponse", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestApiRepository_SuccessKeepsClosed(t *testing.T) {
+	mockRepo := new(mocks.IApiRepository)
+	breaker := circuitBreaker.NewCircuitBreaker("api", testBreakerConfig)
+	repository := NewApiRepository(mockRepo, breaker)
+	ctx := context.Background()
+
+	mockRepo.On("SendResponse", mock.Anything, "1", mock.Anything).Return(errors.New("status 503")).Once()
+	mockRepo.On("SendResponse", mock.Anything, "2", mock.Anything).Return(nil)
+
+	repository.SendResponse(ctx, "1", models.AuthResponse{})
+	assert.NoError(t, repository.SendResponse(ctx, "2", models.AuthResponse{}))
+	mockRepo.On("SendResponse", mock.Anything, "1", mock.Anything).Return(errors.New("status 503"))
+	repository.SendResponse(ctx, "1", models.AuthResponse{})
+
+	assert.Equal(t, circuitBreaker.StateClosed, breaker.State())
+}
+
+func TestApiRepository_IgnoresLostLeases(t *testing.T) {
+	mockRepo := new(mocks.IApiRepository)
+	breaker := circuitBreaker.NewCircuitBreaker("api", testBreakerConfig)
+	repository := NewApiRepository(mockRepo, breaker)
+	lease := models.Lease{ID: "lease-1", RequestID: "1"}
+
+	mockRepo.On("Ack", mock.Anything, lease, mock.Anything).Return(models.ErrLeaseLost)
+
+	for i := 0; i < 3; i++ {
+		assert.ErrorIs(t, repository.Ack(context.Background(), lease, models.AuthResponse{}), models.ErrLeaseLost)
+	}
+	assert.Equal(t, circuitBreaker.StateClosed, breaker.State())
+}
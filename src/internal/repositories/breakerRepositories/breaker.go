@@ -0,0 +1,38 @@
+package breakerRepositories
+
+import (
+	"auth-ad/src/pkg/circuitBreaker"
+	"context"
+	"errors"
+)
+
+// guard executa a chamada protegida pelo circuit breaker
+// Params:
+//   - ctx: Contexto da operação
+//   - breaker: Circuit breaker da dependência
+//   - isFailure: Indica se o erro retornado pela chamada é uma falha da dependência
+//   - call: Chamada protegida
+//
+// Returns:
+//   - error: Erro da chamada, ou circuitBreaker.ErrOpen quando o circuito está aberto
+func guard(ctx context.Context, breaker *circuitBreaker.CircuitBreaker, isFailure func(error) bool, call func() error) error {
+	done, err := breaker.Allow()
+	if err != nil {
+		return err
+	}
+
+	err = call()
+
+	switch {
+	case err != nil && errors.Is(ctx.Err(), context.Canceled):
+		// Cancelamentos do chamador, como no encerramento do serviço, não indicam falha nem
+		// recuperação da dependência
+		done(circuitBreaker.ResultReleased)
+	case err != nil && isFailure(err):
+		done(circuitBreaker.ResultFailure)
+	default:
+		done(circuitBreaker.ResultSuccess)
+	}
+
+	return err
+}
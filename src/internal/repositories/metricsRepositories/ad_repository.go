@@ -0,0 +1,143 @@
+package metricsRepositories
+
+import (
+	"auth-ad/src/internal/interfaces"
+	"auth-ad/src/internal/metrics"
+	"auth-ad/src/internal/models"
+	"context"
+	"time"
+)
+
+// Operações no AD, usadas no rótulo operation da latência
+const (
+	operationBind   = "bind"
+	operationSearch = "search"
+)
+
+// ADRepository registra a latência das operações no Active Directory: a validação de
+// credenciais (bind) e as buscas de usuários e grupos (search)
+type ADRepository struct {
+	repository interfaces.IActiveDirectoryRepository
+	metrics    *metrics.Metrics
+}
+
+// NewADRepository cria o repositório do Active Directory instrumentado
+// Params:
+//   - repository: Repositório do Active Directory
+//   - metrics: Métricas do serviço
+//
+// Returns:
+//   - interfaces.IActiveDirectoryRepository: Interface implementada do repositório
+func NewADRepository(repository interfaces.IActiveDirectoryRepository, metrics *metrics.Metrics) interfaces.IActiveDirectoryRepository {
+	return &ADRepository{repository: repository, metrics: metrics}
+}
+
+// Authenticate realiza a autenticação do usuário no Active Directory
+// Params:
+//   - ctx: Contexto da operação
+//   - username: Nome do usuário
+//   - password: Senha do usuário
+//
+// Returns:
+//   - bool: true se autenticação for bem sucedida
+//   - error: Erro em caso de falha na autenticação
+func (r *ADRepository) Authenticate(ctx context.Context, username, password string) (bool, error) {
+	started := time.Now()
+	authenticated, err := r.repository.Authenticate(ctx, username, password)
+
+	// Credenciais recusadas sem erro são uma resposta normal do AD, como no erro correspondente
+	outcome := err
+	if outcome == nil && !authenticated {
+		outcome = models.ErrInvalidCredentials
+	}
+	r.observe(operationBind, started, outcome)
+
+	return authenticated, err
+}
+
+// GetUser busca um usuário no Active Directory
+// Params:
+//   - ctx: Contexto da operação
+//   - username: Nome do usuário
+//
+// Returns:
+//   - *models.ADUser: Usuário encontrado
+//   - error: Erro em caso de falha na busca
+func (r *ADRepository) GetUser(ctx context.Context, username string) (*models.ADUser, error) {
+	started := time.Now()
+	user, err := r.repository.GetUser(ctx, username)
+	r.observe(operationSearch, started, err)
+
+	return user, err
+}
+
+// GetUsers busca os usuários de um grupo no Active Directory
+// Params:
+//   - ctx: Contexto da operação
+//   - group: Nome do grupo
+//
+// Returns:
+//   - []*models.ADUser: Usuários encontrados
+//   - error: Erro em caso de falha na busca
+func (r *ADRepository) GetUsers(ctx context.Context, group string) ([]*models.ADUser, error) {
+	started := time.Now()
+	users, err := r.repository.GetUsers(ctx, group)
+	r.observe(operationSearch, started, err)
+
+	return users, err
+}
+
+// IsMemberOf indica se o usuário pertence ao grupo no Active Directory
+// Params:
+//   - ctx: Contexto da operação
+//   - username: Nome do usuário
+//   - group: Grupo no formato configurado
+//
+// Returns:
+//   - bool: Verdadeiro se o usuário pertence ao grupo
+//   - error: Erro em caso de falha na busca
+func (r *ADRepository) IsMemberOf(ctx context.Context, username, group string) (bool, error) {
+	started := time.Now()
+	member, err := r.repository.IsMemberOf(ctx, username, group)
+	r.observe(operationSearch, started, err)
+
+	return member, err
+}
+
+// Bind valida as credenciais do usuário no Active Directory
+// Params:
+//   - ctx: Contexto da operação
+//   - username: Nome do usuário
+//   - password: Senha do usuário
+//
+// Returns:
+//   - error: Erro em caso de falha na validação
+func (r *ADRepository) Bind(ctx context.Context, username, password string) error {
+	started := time.Now()
+	err := r.repository.Bind(ctx, username, password)
+	r.observe(operationBind, started, err)
+
+	return err
+}
+
+// Unbind encerra a sessão com o Active Directory
+// Params:
+//   - ctx: Contexto da operação
+//
+// Returns:
+//   - error: Erro em caso de falha ao encerrar a sessão
+func (r *ADRepository) Unbind(ctx context.Context) error {
+	return r.repository.Unbind(ctx)
+}
+
+// Close fecha as conexões com o Active Directory
+// Returns:
+//   - error: Erro em caso de falha ao fechar as conexões
+func (r *ADRepository) Close() error {
+	return r.repository.Close()
+}
+
+// observe registra a latência de uma operação iniciada em started
+func (r *ADRepository) observe(operation string, started time.Time, err error) {
+	r.metrics.ObserveLDAP(operation, time.Since(started), err)
+}
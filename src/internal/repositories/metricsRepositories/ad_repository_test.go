@@ -0,0 +1,56 @@
+package metricsRepositories
+
+import (
+	"auth-ad/src/internal/interfaces/mocks"
+	"auth-ad/src/internal/metrics"
+	"auth-ad/src/internal/models"
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// scrape retorna as métricas expostas no formato de texto do Prometheus
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	recorder := httptest.NewRecorder()
+	m.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	body, err := io.ReadAll(recorder.Result().Body)
+	if err != nil {
+		t.Fatalf("Erro ao ler as métricas: %v", err)
+	}
+	return string(body)
+}
+
+func TestADRepository_ObservesOperations(t *testing.T) {
+	mockRepo := new(mocks.IActiveDirectoryInterface)
+	m := metrics.NewMetrics()
+	repository := NewADRepository(mockRepo, m)
+	ctx := context.Background()
+
+	mockRepo.On("Authenticate", mock.Anything, "user", "pass").Return(true, nil)
+	mockRepo.On("Authenticate", mock.Anything, "user", "errada").Return(false, nil)
+	mockRepo.On("GetUser", mock.Anything, "user").Return(&models.ADUser{}, nil)
+	mockRepo.On("GetUser", mock.Anything, "ghost").Return(nil, models.ErrUserNotFound)
+	mockRepo.On("GetUsers", mock.Anything, "Vendas").Return(nil, errors.New("LDAP Result Code 200"))
+	mockRepo.On("Unbind", mock.Anything).Return(nil)
+
+	repository.Authenticate(ctx, "user", "pass")
+	repository.Authenticate(ctx, "user", "errada")
+	repository.GetUser(ctx, "user")
+	_, err := repository.GetUser(ctx, "ghost")
+	assert.ErrorIs(t, err, models.ErrUserNotFound)
+	repository.GetUsers(ctx, "Vendas")
+	assert.NoError(t, repository.Unbind(ctx))
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `auth_ad_ldap_operation_duration_seconds_count{operation="bind",outcome="success"} 1`)
+	assert.Contains(t, body, `auth_ad_ldap_operation_duration_seconds_count{operation="bind",outcome="failure"} 1`)
+	assert.Contains(t, body, `auth_ad_ldap_operation_duration_seconds_count{operation="search",outcome="success"} 1`)
+	assert.Contains(t, body, `auth_ad_ldap_operation_duration_seconds_count{operation="search",outcome="failure"} 1`)
+	assert.Contains(t, body, `auth_ad_ldap_operation_duration_seconds_count{operation="search",outcome="error"} 1`)
+}
@@ -0,0 +1,403 @@
+package microsoftActiveDirectory
+
+import (
+	"auth-ad/src/internal/models"
+	"auth-ad/src/pkg/configs"
+	"auth-ad/src/pkg/ldapFilter"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// matchingRuleInChain é o OID da regra LDAP_MATCHING_RULE_IN_CHAIN, que percorre grupos aninhados
+const matchingRuleInChain = "1.2.840.113556.1.4.1941"
+
+// groupSearchBatchSize limita a quantidade de valores combinados em um único filtro de busca de grupos
+const groupSearchBatchSize = 50
+
+// groupAttributes são os atributos lidos de cada grupo
+var groupAttributes = []string{"cn", "sAMAccountName", "objectSid", "distinguishedName"}
+
+// adGroup reúne os identificadores de um grupo do AD
+type adGroup struct {
+	DN             string
+	CN             string
+	SAMAccountName string
+	SID            string
+}
+
+// resolveGroups resolve os grupos de um usuário conforme a estratégia configurada,
+// incluindo o grupo primário indicado em primaryGroupID
+// Params:
+//   - ctx: Contexto da operação
+//   - user: Entrada LDAP do usuário, com memberOf, primaryGroupID e objectSid
+//
+// Returns:
+//   - []string: Grupos do usuário no formato configurado
+//   - error: Erro em caso de falha na busca
+func (r *ADRepository) resolveGroups(ctx context.Context, user *ldap.Entry) ([]string, error) {
+	var groups []adGroup
+	var err error
+
+	switch r.config.GroupResolution {
+	case configs.GroupResolutionDirect:
+		groups, err = r.directGroups(ctx, user)
+	case configs.GroupResolutionTokenGroups:
+		groups, err = r.tokenGroups(ctx, user)
+	default:
+		groups, err = r.searchGroups(ctx, []ldapFilter.Filter{
+			ldapFilter.Extensible("member", matchingRuleInChain, user.DN),
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar grupos do usuário: %w", translateError(err))
+	}
+
+	primaryGroup, err := r.primaryGroup(ctx, user)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar grupo primário do usuário: %w", translateError(err))
+	}
+	if primaryGroup != nil {
+		groups = append(groups, *primaryGroup)
+	}
+
+	seen := make(map[string]bool)
+	names := make([]string, 0, len(groups))
+	for _, group := range groups {
+		key := strings.ToLower(group.DN)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if name := r.formatGroup(group); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+// directGroups retorna os grupos diretos do usuário a partir do atributo memberOf.
+// Os grupos só são buscados no AD quando o formato configurado exige atributos além do DN.
+func (r *ADRepository) directGroups(ctx context.Context, user *ldap.Entry) ([]adGroup, error) {
+	memberOf := user.GetAttributeValues("memberOf")
+
+	if r.config.GroupFormat == configs.GroupFormatSAMAccountName || r.config.GroupFormat == configs.GroupFormatSID {
+		filters := make([]ldapFilter.Filter, 0, len(memberOf))
+		for _, dn := range memberOf {
+			filters = append(filters, ldapFilter.Equal("distinguishedName", dn))
+		}
+		return r.searchGroups(ctx, filters)
+	}
+
+	groups := make([]adGroup, 0, len(memberOf))
+	for _, dn := range memberOf {
+		groups = append(groups, adGroup{DN: dn, CN: commonName(dn)})
+	}
+
+	return groups, nil
+}
+
+// tokenGroups retorna os grupos de segurança diretos e aninhados do usuário a partir do atributo
+// construído tokenGroups, que só pode ser lido em uma busca de escopo base
+func (r *ADRepository) tokenGroups(ctx context.Context, user *ldap.Entry) ([]adGroup, error) {
+	searchRequest := ldap.NewSearchRequest(
+		user.DN,
+		ldap.ScopeBaseObject,
+		ldap.NeverDerefAliases,
+		0,
+		0,
+		false,
+		ldapFilter.Present("objectClass").String(),
+		[]string{"tokenGroups"},
+		nil,
+	)
+
+	result, err := r.conn.Search(ctx, searchRequest)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Entries) == 0 {
+		return nil, nil
+	}
+
+	sids := result.Entries[0].GetRawAttributeValues("tokenGroups")
+	filters := make([]ldapFilter.Filter, 0, len(sids))
+	for _, sid := range sids {
+		filters = append(filters, ldapFilter.EqualBytes("objectSid", sid))
+	}
+
+	return r.searchGroups(ctx, filters)
+}
+
+// IsMemberOf indica se o usuário pertence ao grupo, direta ou indiretamente, independentemente de
+// AD_GROUP_RESOLUTION: os grupos aninhados são percorridos pelo próprio AD com
+// LDAP_MATCHING_RULE_IN_CHAIN, e o grupo primário é comparado pelo SID
+// Params:
+//   - ctx: Contexto da operação
+//   - username: Nome do usuário
+//   - group: Grupo no formato configurado em AD_GROUP_FORMAT
+//
+// Returns:
+//   - bool: Verdadeiro se o usuário pertence ao grupo; falso também para grupos inexistentes
+//   - error: models.ErrUserNotFound para usuários inexistentes, ou erro em caso de falha na busca
+func (r *ADRepository) IsMemberOf(ctx context.Context, username, group string) (bool, error) {
+	if err := ValidateUsername(username); err != nil {
+		return false, err
+	}
+
+	userRequest := ldap.NewSearchRequest(
+		r.config.BaseDN,
+		ldap.ScopeWholeSubtree,
+		ldap.NeverDerefAliases,
+		0,
+		0,
+		false,
+		ldapFilter.And(ldapFilter.Equal("objectClass", "user"), ldapFilter.Equal("sAMAccountName", username)).String(),
+		[]string{"primaryGroupID", "objectSid"},
+		nil,
+	)
+
+	result, err := r.conn.Search(ctx, userRequest)
+	if err != nil {
+		return false, fmt.Errorf("erro ao buscar usuário: %w", translateError(err))
+	}
+	if len(result.Entries) == 0 {
+		return false, models.ErrUserNotFound
+	}
+	user := result.Entries[0]
+
+	filter, ok := r.groupFilter(group)
+	if !ok {
+		return false, nil
+	}
+	groups, err := r.searchGroups(ctx, []ldapFilter.Filter{filter})
+	if err != nil {
+		return false, fmt.Errorf("erro ao buscar grupo: %w", translateError(err))
+	}
+
+	primarySID, _ := primaryGroupSID(user)
+	for _, candidate := range groups {
+		if primarySID != "" && strings.EqualFold(candidate.SID, primarySID) {
+			return true, nil
+		}
+
+		memberRequest := ldap.NewSearchRequest(
+			user.DN,
+			ldap.ScopeBaseObject,
+			ldap.NeverDerefAliases,
+			0,
+			0,
+			false,
+			ldapFilter.Extensible("memberOf", matchingRuleInChain, candidate.DN).String(),
+			[]string{"distinguishedName"},
+			nil,
+		)
+
+		result, err := r.conn.Search(ctx, memberRequest)
+		if err != nil {
+			return false, fmt.Errorf("erro ao verificar grupos do usuário: %w", translateError(err))
+		}
+		if len(result.Entries) > 0 {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// groupFilter monta o filtro que encontra um grupo pelo identificador no formato configurado.
+// Retorna falso para um SID inválido, que não identifica nenhum grupo.
+func (r *ADRepository) groupFilter(group string) (ldapFilter.Filter, bool) {
+	switch r.config.GroupFormat {
+	case configs.GroupFormatDN:
+		return ldapFilter.Equal("distinguishedName", group), true
+	case configs.GroupFormatSAMAccountName:
+		return ldapFilter.Equal("sAMAccountName", group), true
+	case configs.GroupFormatSID:
+		sid, err := encodeSID(strings.ToUpper(group))
+		if err != nil {
+			return "", false
+		}
+		return ldapFilter.EqualBytes("objectSid", sid), true
+	default:
+		return ldapFilter.Equal("cn", group), true
+	}
+}
+
+// primaryGroupSID retorna o SID do grupo primário do usuário, formado pelo SID do domínio
+// (SID do usuário sem o último RID) seguido de primaryGroupID, ou vazio quando não informado
+func primaryGroupSID(user *ldap.Entry) (string, error) {
+	primaryGroupID := user.GetAttributeValue("primaryGroupID")
+	userSID := user.GetRawAttributeValue("objectSid")
+	if primaryGroupID == "" || len(userSID) == 0 {
+		return "", nil
+	}
+
+	rid, err := strconv.ParseUint(primaryGroupID, 10, 32)
+	if err != nil {
+		return "", fmt.Errorf("primaryGroupID inválido: %s", primaryGroupID)
+	}
+
+	sid, err := decodeSID(userSID)
+	if err != nil {
+		return "", err
+	}
+
+	return sid[:strings.LastIndex(sid, "-")+1] + strconv.FormatUint(rid, 10), nil
+}
+
+// primaryGroup busca o grupo primário do usuário, identificado por primaryGroupSID
+func (r *ADRepository) primaryGroup(ctx context.Context, user *ldap.Entry) (*adGroup, error) {
+	sid, err := primaryGroupSID(user)
+	if err != nil || sid == "" {
+		return nil, err
+	}
+
+	groupSID, err := encodeSID(sid)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := r.searchGroups(ctx, []ldapFilter.Filter{ldapFilter.EqualBytes("objectSid", groupSID)})
+	if err != nil || len(groups) == 0 {
+		return nil, err
+	}
+
+	return &groups[0], nil
+}
+
+// searchGroups busca grupos que atendam a qualquer um dos filtros informados,
+// combinando-os em lotes para não gerar filtros muito grandes
+func (r *ADRepository) searchGroups(ctx context.Context, filters []ldapFilter.Filter) ([]adGroup, error) {
+	groups := make([]adGroup, 0)
+
+	for start := 0; start < len(filters); start += groupSearchBatchSize {
+		end := start + groupSearchBatchSize
+		if end > len(filters) {
+			end = len(filters)
+		}
+
+		searchRequest := ldap.NewSearchRequest(
+			r.config.BaseDN,
+			ldap.ScopeWholeSubtree,
+			ldap.NeverDerefAliases,
+			0,
+			0,
+			false,
+			ldapFilter.And(ldapFilter.Equal("objectClass", "group"), ldapFilter.Or(filters[start:end]...)).String(),
+			groupAttributes,
+			nil,
+		)
+
+		result, err := r.conn.Search(ctx, searchRequest)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range result.Entries {
+			sid, _ := decodeSID(entry.GetRawAttributeValue("objectSid"))
+			groups = append(groups, adGroup{
+				DN:             entry.DN,
+				CN:             entry.GetAttributeValue("cn"),
+				SAMAccountName: entry.GetAttributeValue("sAMAccountName"),
+				SID:            sid,
+			})
+		}
+	}
+
+	return groups, nil
+}
+
+// formatGroup retorna o identificador do grupo no formato configurado
+func (r *ADRepository) formatGroup(group adGroup) string {
+	switch r.config.GroupFormat {
+	case configs.GroupFormatDN:
+		return group.DN
+	case configs.GroupFormatSAMAccountName:
+		return group.SAMAccountName
+	case configs.GroupFormatSID:
+		return group.SID
+	default:
+		if group.CN != "" {
+			return group.CN
+		}
+		return commonName(group.DN)
+	}
+}
+
+// commonName extrai o CN do primeiro RDN de um DN
+func commonName(dn string) string {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil || len(parsed.RDNs) == 0 {
+		return ""
+	}
+
+	for _, attribute := range parsed.RDNs[0].Attributes {
+		if strings.EqualFold(attribute.Type, "cn") {
+			return attribute.Value
+		}
+	}
+
+	return ""
+}
+
+// decodeSID converte um SID binário para o formato textual S-R-I-S1-S2-...
+func decodeSID(raw []byte) (string, error) {
+	if len(raw) < 8 || len(raw) != 8+4*int(raw[1]) {
+		return "", fmt.Errorf("SID binário inválido")
+	}
+
+	var authority uint64
+	for _, b := range raw[2:8] {
+		authority = authority<<8 | uint64(b)
+	}
+
+	var sid strings.Builder
+	fmt.Fprintf(&sid, "S-%d-%d", raw[0], authority)
+	for i := 0; i < int(raw[1]); i++ {
+		fmt.Fprintf(&sid, "-%d", binary.LittleEndian.Uint32(raw[8+4*i:]))
+	}
+
+	return sid.String(), nil
+}
+
+// encodeSID converte um SID no formato textual para o formato binário usado pelo AD
+func encodeSID(sid string) ([]byte, error) {
+	parts := strings.Split(sid, "-")
+	if len(parts) < 3 || parts[0] != "S" {
+		return nil, fmt.Errorf("SID inválido: %s", sid)
+	}
+
+	revision, err := strconv.ParseUint(parts[1], 10, 8)
+	if err != nil {
+		return nil, fmt.Errorf("SID inválido: %s", sid)
+	}
+	authority, err := strconv.ParseUint(parts[2], 10, 48)
+	if err != nil {
+		return nil, fmt.Errorf("SID inválido: %s", sid)
+	}
+
+	subAuthorities := parts[3:]
+	raw := make([]byte, 8+4*len(subAuthorities))
+	raw[0] = byte(revision)
+	raw[1] = byte(len(subAuthorities))
+	for i := 0; i < 6; i++ {
+		raw[7-i] = byte(authority >> (8 * i))
+	}
+
+	for i, part := range subAuthorities {
+		value, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("SID inválido: %s", sid)
+		}
+		binary.LittleEndian.PutUint32(raw[8+4*i:], uint32(value))
+	}
+
+	return raw, nil
+}
@@ -0,0 +1,249 @@
+package microsoftActiveDirectory
+
+import (
+	"auth-ad/src/internal/models"
+	"auth-ad/src/pkg/configs"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testDomainSID = "S-1-5-21-1004336348-1177238915-682003330"
+	testUserDN    = "CN=Test User,OU=Users,DC=example,DC=com"
+)
+
+// newGroupEntry cria uma entrada LDAP de grupo para os testes
+func newGroupEntry(t *testing.T, cn, sam string, rid string) *ldap.Entry {
+	sid, err := encodeSID(testDomainSID + "-" + rid)
+	if err != nil {
+		t.Fatalf("Erro ao codificar SID: %v", err)
+	}
+
+	entry := ldap.NewEntry("CN="+cn+",OU=Groups,DC=example,DC=com", map[string][]string{
+		"cn":             {cn},
+		"sAMAccountName": {sam},
+	})
+	entry.Attributes = append(entry.Attributes, &ldap.EntryAttribute{Name: "objectSid", ByteValues: [][]byte{sid}})
+
+	return entry
+}
+
+// newTestUserEntry cria uma entrada LDAP de usuário com memberOf, primaryGroupID e objectSid
+func newTestUserEntry(t *testing.T) *ldap.Entry {
+	sid, err := encodeSID(testDomainSID + "-1105")
+	if err != nil {
+		t.Fatalf("Erro ao codificar SID: %v", err)
+	}
+
+	entry := ldap.NewEntry(testUserDN, map[string][]string{
+		"sAMAccountName": {"testuser"},
+		"memberOf":       {"CN=Vendas,OU=Groups,DC=example,DC=com"},
+		"primaryGroupID": {"513"},
+	})
+	entry.Attributes = append(entry.Attributes, &ldap.EntryAttribute{Name: "objectSid", ByteValues: [][]byte{sid}})
+
+	return entry
+}
+
+// newGroupSearchConn simula as buscas de grupos no AD
+func newGroupSearchConn(t *testing.T, filters *[]string) *MockLDAPConn {
+	domainUsers := newGroupEntry(t, "Domain Users", "Domain Users", "513")
+	vendas := newGroupEntry(t, "Vendas", "vendas", "1201")
+	gerentes := newGroupEntry(t, "Gerentes", "gerentes", "1202")
+
+	return &MockLDAPConn{
+		SearchFunc: func(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
+			*filters = append(*filters, searchRequest.Filter)
+
+			switch {
+			case searchRequest.Scope == ldap.ScopeBaseObject:
+				tokenGroups := &ldap.EntryAttribute{Name: "tokenGroups", ByteValues: [][]byte{
+					domainUsers.GetRawAttributeValue("objectSid"),
+					vendas.GetRawAttributeValue("objectSid"),
+					gerentes.GetRawAttributeValue("objectSid"),
+				}}
+				entry := &ldap.Entry{DN: testUserDN, Attributes: []*ldap.EntryAttribute{tokenGroups}}
+				return &ldap.SearchResult{Entries: []*ldap.Entry{entry}}, nil
+
+			case strings.Contains(searchRequest.Filter, matchingRuleInChain):
+				return &ldap.SearchResult{Entries: []*ldap.Entry{vendas, gerentes}}, nil
+
+			case strings.Contains(searchRequest.Filter, "distinguishedName="):
+				return &ldap.SearchResult{Entries: []*ldap.Entry{vendas}}, nil
+
+			case strings.Count(searchRequest.Filter, "objectSid=") == 1:
+				return &ldap.SearchResult{Entries: []*ldap.Entry{domainUsers}}, nil
+
+			case strings.Contains(searchRequest.Filter, "objectSid="):
+				return &ldap.SearchResult{Entries: []*ldap.Entry{domainUsers, vendas, gerentes}}, nil
+			}
+
+			return &ldap.SearchResult{}, nil
+		},
+	}
+}
+
+func TestResolveGroups_DirectCN(t *testing.T) {
+	var filters []string
+	repo := &ADRepository{conn: newGroupSearchConn(t, &filters), config: &configs.ADConfig{
+		GroupResolution: configs.GroupResolutionDirect,
+		GroupFormat:     configs.GroupFormatCN,
+	}}
+
+	groups, err := repo.resolveGroups(context.Background(), newTestUserEntry(t))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Vendas", "Domain Users"}, groups)
+
+	// memberOf já fornece o CN, então apenas o grupo primário é buscado
+	assert.Len(t, filters, 1)
+}
+
+func TestResolveGroups_DirectSAMAccountName(t *testing.T) {
+	var filters []string
+	repo := &ADRepository{conn: newGroupSearchConn(t, &filters), config: &configs.ADConfig{
+		GroupResolution: configs.GroupResolutionDirect,
+		GroupFormat:     configs.GroupFormatSAMAccountName,
+	}}
+
+	groups, err := repo.resolveGroups(context.Background(), newTestUserEntry(t))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"vendas", "Domain Users"}, groups)
+	assert.Contains(t, filters[0], "(distinguishedName=CN=Vendas,OU=Groups,DC=example,DC=com)")
+}
+
+func TestResolveGroups_NestedDN(t *testing.T) {
+	var filters []string
+	repo := &ADRepository{conn: newGroupSearchConn(t, &filters), config: &configs.ADConfig{
+		GroupResolution: configs.GroupResolutionNested,
+		GroupFormat:     configs.GroupFormatDN,
+	}}
+
+	groups, err := repo.resolveGroups(context.Background(), newTestUserEntry(t))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"CN=Vendas,OU=Groups,DC=example,DC=com",
+		"CN=Gerentes,OU=Groups,DC=example,DC=com",
+		"CN=Domain Users,OU=Groups,DC=example,DC=com",
+	}, groups)
+	assert.Contains(t, filters[0], "(member:1.2.840.113556.1.4.1941:="+testUserDN+")")
+}
+
+func TestResolveGroups_TokenGroupsSID(t *testing.T) {
+	var filters []string
+	repo := &ADRepository{conn: newGroupSearchConn(t, &filters), config: &configs.ADConfig{
+		GroupResolution: configs.GroupResolutionTokenGroups,
+		GroupFormat:     configs.GroupFormatSID,
+	}}
+
+	groups, err := repo.resolveGroups(context.Background(), newTestUserEntry(t))
+	assert.NoError(t, err)
+
+	// O grupo primário já consta em tokenGroups e não é duplicado
+	assert.Equal(t, []string{
+		testDomainSID + "-513",
+		testDomainSID + "-1201",
+		testDomainSID + "-1202",
+	}, groups)
+}
+
+func TestResolveGroups_WithoutPrimaryGroup(t *testing.T) {
+	var filters []string
+	repo := &ADRepository{conn: newGroupSearchConn(t, &filters), config: &configs.ADConfig{
+		GroupResolution: configs.GroupResolutionDirect,
+	}}
+
+	user := ldap.NewEntry(testUserDN, map[string][]string{"memberOf": {"CN=Vendas,OU=Groups,DC=example,DC=com"}})
+
+	groups, err := repo.resolveGroups(context.Background(), user)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Vendas"}, groups)
+	assert.Empty(t, filters)
+}
+
+// newMembershipConn simula as buscas da verificação de pertinência: o usuário testuser pertence a
+// Gerentes por um grupo aninhado, a Domain Users como grupo primário e não pertence a Financeiro
+func newMembershipConn(t *testing.T) *MockLDAPConn {
+	groups := map[string]*ldap.Entry{
+		"Domain Users": newGroupEntry(t, "Domain Users", "Domain Users", "513"),
+		"Gerentes":     newGroupEntry(t, "Gerentes", "gerentes", "1202"),
+		"Financeiro":   newGroupEntry(t, "Financeiro", "financeiro", "1203"),
+	}
+
+	return &MockLDAPConn{
+		SearchFunc: func(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
+			switch {
+			case searchRequest.Scope == ldap.ScopeBaseObject:
+				if strings.Contains(searchRequest.Filter, matchingRuleInChain) && strings.Contains(searchRequest.Filter, "CN=Gerentes") {
+					return &ldap.SearchResult{Entries: []*ldap.Entry{ldap.NewEntry(testUserDN, nil)}}, nil
+				}
+				return &ldap.SearchResult{}, nil
+
+			case strings.Contains(searchRequest.Filter, "(objectClass=user)"):
+				if strings.Contains(searchRequest.Filter, "(sAMAccountName=testuser)") {
+					return &ldap.SearchResult{Entries: []*ldap.Entry{newTestUserEntry(t)}}, nil
+				}
+				return &ldap.SearchResult{}, nil
+			}
+
+			for cn, group := range groups {
+				if strings.Contains(searchRequest.Filter, "(cn="+cn+")") {
+					return &ldap.SearchResult{Entries: []*ldap.Entry{group}}, nil
+				}
+			}
+			return &ldap.SearchResult{}, nil
+		},
+	}
+}
+
+func TestIsMemberOf(t *testing.T) {
+	// Com a resolução direta, os grupos aninhados também são considerados
+	repo := &ADRepository{conn: newMembershipConn(t), config: &configs.ADConfig{
+		GroupResolution: configs.GroupResolutionDirect,
+		GroupFormat:     configs.GroupFormatCN,
+	}}
+	ctx := context.Background()
+
+	cases := map[string]bool{"Gerentes": true, "Domain Users": true, "Financeiro": false, "Inexistente": false}
+	for group, expected := range cases {
+		member, err := repo.IsMemberOf(ctx, "testuser", group)
+		assert.NoError(t, err, group)
+		assert.Equal(t, expected, member, group)
+	}
+
+	_, err := repo.IsMemberOf(ctx, "ghost", "Gerentes")
+	assert.ErrorIs(t, err, models.ErrUserNotFound)
+
+	_, err = repo.IsMemberOf(ctx, "a*b", "Gerentes")
+	assert.ErrorIs(t, err, models.ErrInvalidUsername)
+
+	repo.config.GroupFormat = configs.GroupFormatSID
+	member, err := repo.IsMemberOf(ctx, "testuser", "sid-inválido")
+	assert.NoError(t, err)
+	assert.False(t, member)
+}
+
+func TestSIDEncoding(t *testing.T) {
+	raw, err := encodeSID(testDomainSID + "-513")
+	assert.NoError(t, err)
+	assert.Len(t, raw, 28)
+
+	sid, err := decodeSID(raw)
+	assert.NoError(t, err)
+	assert.Equal(t, testDomainSID+"-513", sid)
+
+	_, err = decodeSID([]byte{1, 5, 0})
+	assert.Error(t, err)
+
+	_, err = encodeSID("X-1-5")
+	assert.Error(t, err)
+}
+
+func TestCommonName(t *testing.T) {
+	assert.Equal(t, "Vendas, Norte", commonName(`CN=Vendas\, Norte,OU=Groups,DC=example,DC=com`))
+	assert.Equal(t, "", commonName("OU=Groups,DC=example,DC=com"))
+	assert.Equal(t, "", commonName("inválido"))
+}
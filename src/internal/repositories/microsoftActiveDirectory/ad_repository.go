@@ -4,34 +4,54 @@ import (
 	"auth-ad/src/internal/interfaces"
 	"auth-ad/src/internal/models"
 	"auth-ad/src/pkg/configs"
+	"auth-ad/src/pkg/ldapFilter"
+	"auth-ad/src/pkg/logging"
+	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/go-ldap/ldap/v3"
 )
 
 // ILDAPConnection define a interface para operações LDAP
 type ILDAPConnection interface {
-	Bind(username, password string) error
-	Search(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error)
+	Bind(ctx context.Context, username, password string) error
+	Search(ctx context.Context, searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error)
 	Close() error
 	Unbind() error
 }
 
-// ADRepository implementa a interface IActiveDirectoryInterface para interação com o Active Directory
+// logger registra as operações no Active Directory
+var logger = logging.Component("activeDirectory")
+
+// membersPageSize é a quantidade de membros pedida em cada página da consulta dos membros de um grupo,
+// abaixo do MaxPageSize padrão do AD
+const membersPageSize = 500
+
+// userAttributes são os atributos lidos de cada usuário
+var userAttributes = []string{"cn", "mail", "sAMAccountName", "userPrincipalName", "distinguishedName", "department", "mailNickname", "title", "uid", "memberOf", "primaryGroupID", "objectSid"}
+
+// ADRepository implementa a interface IActiveDirectoryInterface para interação com o Active Directory.
+// As buscas usam sempre a conexão autenticada com a conta de serviço, enquanto as credenciais dos
+// usuários são validadas em conexões dedicadas e de curta duração.
 type ADRepository struct {
-	conn   ILDAPConnection
-	config *configs.ADConfig
+	conn     ILDAPConnection
+	dialBind DialFunc
+	config   *configs.ADConfig
 }
 
 // NewADRepository cria uma nova instância do repositório AD que implementa IActiveDirectoryInterface
 // Params:
 //   - config: Configurações de conexão com o Active Directory
+//   - conn: Conexão de busca, autenticada com a conta de serviço
+//   - dialBind: Função que abre as conexões dedicadas usadas na validação de credenciais
 //
 // Returns:
 //   - interfaces.IActiveDirectoryRepository: Interface implementada do repositório
 //   - error: Erro em caso de falha na conexão
-func NewADRepository(config *configs.ADConfig, conn ILDAPConnection) (interfaces.IActiveDirectoryRepository, error) {
-	return &ADRepository{conn: conn, config: config}, nil
+func NewADRepository(config *configs.ADConfig, conn ILDAPConnection, dialBind DialFunc) (interfaces.IActiveDirectoryRepository, error) {
+	return &ADRepository{conn: conn, dialBind: dialBind, config: config}, nil
 }
 
 // Close fecha a conexão com o Active Directory
@@ -43,49 +63,106 @@ func (r *ADRepository) Close() error {
 
 // Authenticate realiza a autenticação do usuário no Active Directory
 // Params:
+//   - ctx: Contexto da operação
 //   - username: Nome do usuário
 //   - password: Senha do usuário
 //
 // Returns:
 //   - bool: true se autenticação for bem sucedida
 //   - error: Erro em caso de falha na autenticação
-func (r *ADRepository) Authenticate(username, password string) (bool, error) {
-	userDN := fmt.Sprintf("%s@%s", username, r.config.Domain)
-	err := r.conn.Bind(userDN, password)
+func (r *ADRepository) Authenticate(ctx context.Context, username, password string) (bool, error) {
+	err := r.Bind(ctx, username, password)
 	if err != nil {
-		return false, fmt.Errorf("erro na autenticação: %v", err)
+		return false, fmt.Errorf("erro na autenticação: %w", err)
 	}
 
 	return true, nil
 }
 
-// Bind realiza a vinculação da conexão com as credenciais do usuário
+// Bind valida as credenciais do usuário em uma conexão dedicada, que é fechada em seguida.
+// A conexão de busca continua autenticada com a conta de serviço.
 // Params:
+//   - ctx: Contexto da operação
 //   - username: Nome do usuário
 //   - password: Senha do usuário
 //
 // Returns:
 //   - error: Erro em caso de falha no bind
-func (r *ADRepository) Bind(username, password string) error {
+func (r *ADRepository) Bind(ctx context.Context, username, password string) error {
+	if err := ValidateUsername(username); err != nil {
+		return err
+	}
+	if password == "" {
+		return fmt.Errorf("%w: senha vazia", models.ErrInvalidCredentials)
+	}
+
+	conn, err := r.dialBind(ctx)
+	if err != nil {
+		return fmt.Errorf("erro ao conectar ao AD: %w", translateError(err))
+	}
+	defer conn.Close()
+
 	userDN := fmt.Sprintf("%s@%s", username, r.config.Domain)
-	return r.conn.Bind(userDN, password)
+	if err := conn.Bind(ctx, userDN, password); err != nil {
+		return classifyBindError(err)
+	}
+
+	return nil
 }
 
-// Unbind remove a vinculação atual da conexão
+// Ping verifica se o AD está acessível e aceita as credenciais da conta de serviço, com um bind em
+// uma conexão dedicada. Sem conta de serviço configurada, a conexão é testada com uma leitura do RootDSE.
+// Params:
+//   - ctx: Contexto da operação
+//
+// Returns:
+//   - error: Erro em caso de AD inacessível ou credenciais da conta de serviço recusadas
+func (r *ADRepository) Ping(ctx context.Context) error {
+	conn, err := r.dialBind(ctx)
+	if err != nil {
+		return fmt.Errorf("erro ao conectar ao AD: %w", translateError(err))
+	}
+	defer conn.Close()
+
+	if r.config.Username == "" {
+		return translateError(probe(conn))
+	}
+
+	if err := conn.Bind(ctx, ServiceAccountName(r.config), r.config.Password); err != nil {
+		return fmt.Errorf("erro no bind da conta de serviço: %w", translateError(err))
+	}
+
+	return nil
+}
+
+// Unbind remove a vinculação atual da conexão de busca
+// Params:
+//   - ctx: Contexto da operação
+//
 // Returns:
 //   - error: Erro em caso de falha no unbind
-func (r *ADRepository) Unbind() error {
+func (r *ADRepository) Unbind(ctx context.Context) error {
 	return r.conn.Unbind()
 }
 
 // GetUser busca informações de um usuário específico no Active Directory
 // Params:
+//   - ctx: Contexto da operação
 //   - username: Nome do usuário a ser buscado
 //
 // Returns:
 //   - *models.ADUser: Dados do usuário encontrado
 //   - error: Erro em caso de falha na busca
-func (r *ADRepository) GetUser(username string) (*models.ADUser, error) {
+func (r *ADRepository) GetUser(ctx context.Context, username string) (*models.ADUser, error) {
+	if err := ValidateUsername(username); err != nil {
+		return nil, err
+	}
+
+	filter := ldapFilter.And(
+		ldapFilter.Equal("objectClass", "user"),
+		ldapFilter.Equal("sAMAccountName", username),
+	)
+
 	searchRequest := ldap.NewSearchRequest(
 		r.config.BaseDN,        // BaseDN
 		ldap.ScopeWholeSubtree, // Escopo
@@ -93,43 +170,43 @@ func (r *ADRepository) GetUser(username string) (*models.ADUser, error) {
 		0,                      // Limite de tamanho (0 = sem limite)
 		0,                      // Limite de tempo (0 = sem limite)
 		false,                  // Somente tipos
-		fmt.Sprintf("(&(objectClass=user)(sAMAccountName=%s))", username),                                                                // Filtro
-		[]string{"cn", "mail", "sAMAccountName", "userPrincipalName", "distinguishedName", "department", "mailNickname", "title", "uid"}, // Atributos que queremos retornar
+		filter.String(),        // Filtro
+		userAttributes,         // Atributos que queremos retornar
 		nil,
 	)
 
-	result, err := r.conn.Search(searchRequest)
+	result, err := r.conn.Search(ctx, searchRequest)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao buscar usuário: %v", err)
+		return nil, fmt.Errorf("erro ao buscar usuário: %w", translateError(err))
 	}
 
 	if len(result.Entries) == 0 {
-		return nil, fmt.Errorf("usuário não encontrado")
+		return nil, models.ErrUserNotFound
 	}
 
 	user := result.Entries[0]
 
-	user.PrettyPrint(4)
+	// Apenas o DN: os demais atributos da entrada são dados pessoais do usuário
+	logger.DebugContext(ctx, "Usuário encontrado no AD", "dn", user.DN, "entries", len(result.Entries))
 
-	return &models.ADUser{
-		UID:               user.GetAttributeValue("uid"),
-		DN:                user.DN,
-		CN:                user.GetAttributeValue("cn"),
-		Email:             user.GetAttributeValue("mail"),
-		SAMAccountName:    user.GetAttributeValue("sAMAccountName"),
-		UserPrincipalName: user.GetAttributeValue("userPrincipalName"),
-	}, nil
+	return r.newADUser(ctx, user)
 }
 
-// GetUsers busca todos os usuários pertencentes a um grupo específico
+// GetUsers busca todos os usuários pertencentes a um grupo específico, inclusive pelos grupos
+// aninhados. A busca é paginada e limitada a AD_GROUP_MEMBERS_LIMIT usuários, e os grupos de cada
+// membro não são resolvidos, evitando uma busca adicional por membro.
 // Params:
+//   - ctx: Contexto da operação
 //   - group: Nome do grupo a ser consultado
 //
 // Returns:
-//   - []*models.ADUser: Lista de usuários encontrados no grupo
-//   - error: Erro em caso de falha na busca
-func (r *ADRepository) GetUsers(group string) ([]*models.ADUser, error) {
-	groupFilter := fmt.Sprintf("(&(objectClass=group)(cn=%s))", group)
+//   - []*models.ADUser: Lista de usuários encontrados no grupo, sem os grupos de cada usuário
+//   - error: Erro em caso de falha na busca, ou models.ErrGroupTooLarge acima do limite
+func (r *ADRepository) GetUsers(ctx context.Context, group string) ([]*models.ADUser, error) {
+	groupFilter := ldapFilter.And(
+		ldapFilter.Equal("objectClass", "group"),
+		ldapFilter.Equal("cn", group),
+	)
 	searchRequest := ldap.NewSearchRequest(
 		r.config.BaseDN,
 		ldap.ScopeWholeSubtree,
@@ -137,51 +214,115 @@ func (r *ADRepository) GetUsers(group string) ([]*models.ADUser, error) {
 		0,
 		0,
 		false,
-		groupFilter,
+		groupFilter.String(),
 		[]string{"distinguishedName", "member"},
 		nil,
 	)
 
-	result, err := r.conn.Search(searchRequest)
+	result, err := r.conn.Search(ctx, searchRequest)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao buscar usuários: %v", err)
+		return nil, fmt.Errorf("erro ao buscar usuários: %w", translateError(err))
 	}
 
 	if len(result.Entries) == 0 {
-		return nil, fmt.Errorf("grupo não encontrado")
+		return nil, models.ErrGroupNotFound
 	}
 
 	groupDN := result.Entries[0].DN
 
-	userFilter := fmt.Sprintf("(&(objectClass=user)(objectCategory=person)(memberOf:1.2.840.113556.1.4.1941:=%s))", groupDN)
+	userFilter := ldapFilter.And(
+		ldapFilter.Equal("objectClass", "user"),
+		ldapFilter.Equal("objectCategory", "person"),
+		ldapFilter.Extensible("memberOf", matchingRuleInChain, groupDN),
+	)
 	userSearchRequest := ldap.NewSearchRequest(
 		r.config.BaseDN,
 		ldap.ScopeWholeSubtree,
 		ldap.NeverDerefAliases,
-		0,
+		r.config.GroupMembersLimit,
 		0,
 		false,
-		userFilter,
-		[]string{"cn", "mail", "sAMAccountName", "userPrincipalName", "distinguishedName", "department", "mailNickname", "title", "uid"},
-		nil,
+		userFilter.String(),
+		userAttributes,
+		[]ldap.Control{ldap.NewControlPaging(membersPageSize)},
 	)
 
-	userResult, err := r.conn.Search(userSearchRequest)
+	userResult, err := r.conn.Search(ctx, userSearchRequest)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return nil, fmt.Errorf("%w: %d", models.ErrGroupTooLarge, r.config.GroupMembersLimit)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("erro ao buscar usuários: %v", err)
+		return nil, fmt.Errorf("erro ao buscar usuários: %w", translateError(err))
 	}
 
-	users := make([]*models.ADUser, 0)
+	users := make([]*models.ADUser, 0, len(userResult.Entries))
 	for _, user := range userResult.Entries {
-		users = append(users, &models.ADUser{
-			UID:               user.GetAttributeValue("uid"),
-			DN:                user.DN,
-			CN:                user.GetAttributeValue("cn"),
-			Email:             user.GetAttributeValue("mail"),
-			SAMAccountName:    user.GetAttributeValue("sAMAccountName"),
-			UserPrincipalName: user.GetAttributeValue("userPrincipalName"),
-		})
+		users = append(users, entryToADUser(user, nil))
 	}
 
 	return users, nil
 }
+
+// newADUser converte uma entrada LDAP de usuário em models.ADUser, resolvendo seus grupos
+// Params:
+//   - ctx: Contexto da operação
+//   - user: Entrada LDAP lida com userAttributes
+//
+// Returns:
+//   - *models.ADUser: Dados do usuário com os grupos preenchidos
+//   - error: Erro em caso de falha na resolução dos grupos
+func (r *ADRepository) newADUser(ctx context.Context, user *ldap.Entry) (*models.ADUser, error) {
+	groups, err := r.resolveGroups(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return entryToADUser(user, groups), nil
+}
+
+// entryToADUser converte a entrada de um usuário, com os grupos já resolvidos
+func entryToADUser(user *ldap.Entry, groups []string) *models.ADUser {
+	sid, _ := decodeSID(user.GetRawAttributeValue("objectSid"))
+
+	return &models.ADUser{
+		UID:               user.GetAttributeValue("uid"),
+		DN:                user.DN,
+		CN:                user.GetAttributeValue("cn"),
+		Email:             user.GetAttributeValue("mail"),
+		SAMAccountName:    user.GetAttributeValue("sAMAccountName"),
+		SID:               sid,
+		Groups:            groups,
+		UserPrincipalName: user.GetAttributeValue("userPrincipalName"),
+	}
+}
+
+// ServiceAccountName retorna o nome usado no bind da conta de serviço. Nomes já qualificados
+// (UPN, DOMÍNIO\usuário ou DN) são mantidos; os demais recebem o sufixo do domínio.
+// Params:
+//   - config: Configurações de conexão com o Active Directory
+//
+// Returns:
+//   - string: Nome da conta de serviço para o bind
+func ServiceAccountName(config *configs.ADConfig) string {
+	if config.Username == "" || strings.ContainsAny(config.Username, "@\\=") {
+		return config.Username
+	}
+
+	return fmt.Sprintf("%s@%s", config.Username, config.Domain)
+}
+
+// translateError marca as falhas de conexão com o AD e as operações interrompidas pelo contexto
+// como models.ErrDirectoryUnavailable, mantendo o erro original na cadeia
+// Params:
+//   - err: Erro retornado pela conexão LDAP
+//
+// Returns:
+//   - error: Erro traduzido
+func translateError(err error) error {
+	if isConnectionError(err) || errors.Is(err, ErrPoolTimeout) || errors.Is(err, ErrPoolClosed) ||
+		errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return fmt.Errorf("%w: %w", models.ErrDirectoryUnavailable, err)
+	}
+
+	return err
+}
@@ -1,7 +1,11 @@
 package microsoftActiveDirectory
 
 import (
+	"auth-ad/src/internal/models"
 	"auth-ad/src/pkg/configs"
+	"context"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/go-ldap/ldap/v3"
@@ -16,11 +20,14 @@ type MockLDAPConn struct {
 	UnbindFunc func() error
 }
 
-func (m *MockLDAPConn) Bind(username, password string) error {
+func (m *MockLDAPConn) Bind(ctx context.Context, username, password string) error {
 	return m.BindFunc(username, password)
 }
 
-func (m *MockLDAPConn) Search(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
+func (m *MockLDAPConn) Search(ctx context.Context, searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	return m.SearchFunc(searchRequest)
 }
 
@@ -32,23 +39,61 @@ func (m *MockLDAPConn) Unbind() error {
 	return m.UnbindFunc()
 }
 
+// newBindDialer cria uma DialFunc de teste que conta as conexões dedicadas abertas e fechadas
+func newBindDialer(bindFunc func(username, password string) error) (DialFunc, *int, *int) {
+	opened, closed := 0, 0
+	dial := func(ctx context.Context) (ILDAPConnection, error) {
+		opened++
+		return &MockLDAPConn{
+			BindFunc: bindFunc,
+			CloseFunc: func() error {
+				closed++
+				return nil
+			},
+		}, nil
+	}
+
+	return dial, &opened, &closed
+}
+
+func validUserBind(username, password string) error {
+	if username == "validUser@domain.com" && password == "validPassword" {
+		return nil
+	}
+	return ldap.NewError(ldap.LDAPResultInvalidCredentials, nil)
+}
+
 func TestADRepository_Authenticate(t *testing.T) {
-	mockConn := &MockLDAPConn{
+	searchConn := &MockLDAPConn{
 		BindFunc: func(username, password string) error {
-			if username == "validUser@domain.com" && password == "validPassword" {
-				return nil
-			}
-			return ldap.NewError(ldap.LDAPResultInvalidCredentials, nil)
+			t.Error("A conexão de busca não deve ser usada para validar credenciais")
+			return nil
 		},
 	}
+	dialBind, opened, closed := newBindDialer(validUserBind)
 
-	repo := &ADRepository{conn: mockConn, config: &configs.ADConfig{Domain: "domain.com"}}
+	repo := &ADRepository{conn: searchConn, dialBind: dialBind, config: &configs.ADConfig{Domain: "domain.com"}}
 
-	success, err := repo.Authenticate("validUser", "validPassword")
+	success, err := repo.Authenticate(context.Background(), "validUser", "validPassword")
 	assert.NoError(t, err)
 	assert.True(t, success)
 
-	success, err = repo.Authenticate("invalidUser", "invalidPassword")
+	success, err = repo.Authenticate(context.Background(), "invalidUser", "invalidPassword")
+	assert.Error(t, err)
+	assert.False(t, success)
+
+	assert.Equal(t, 2, *opened)
+	assert.Equal(t, 2, *closed)
+}
+
+func TestADRepository_Authenticate_DialError(t *testing.T) {
+	dialBind := func(ctx context.Context) (ILDAPConnection, error) {
+		return nil, ldap.NewError(ldap.ErrorNetwork, nil)
+	}
+
+	repo := &ADRepository{dialBind: dialBind, config: &configs.ADConfig{Domain: "domain.com"}}
+
+	success, err := repo.Authenticate(context.Background(), "validUser", "validPassword")
 	assert.Error(t, err)
 	assert.False(t, success)
 }
@@ -62,23 +107,28 @@ func TestADRepository_GetUser(t *testing.T) {
 				"mail":              {"test@example.com"},
 				"sAMAccountName":    {"testuser"},
 				"userPrincipalName": {"testuser@example.com"},
+				"memberOf":          {"cn=Vendas,ou=Groups,dc=example,dc=com", "cn=Gerentes,ou=Groups,dc=example,dc=com"},
 			})
 			return &ldap.SearchResult{Entries: []*ldap.Entry{entry}}, nil
 		},
 	}
 
-	repo := &ADRepository{conn: mockConn, config: &configs.ADConfig{BaseDN: "dc=example,dc=com"}}
+	repo := &ADRepository{conn: mockConn, config: &configs.ADConfig{BaseDN: "dc=example,dc=com", GroupResolution: configs.GroupResolutionDirect}}
 
-	user, err := repo.GetUser("testuser")
+	user, err := repo.GetUser(context.Background(), "testuser")
 	assert.NoError(t, err)
 	assert.NotNil(t, user)
 	assert.Equal(t, "testuser", user.SAMAccountName)
 	assert.Equal(t, "test@example.com", user.Email)
+	assert.Equal(t, []string{"Vendas", "Gerentes"}, user.Groups)
 }
 
 func TestADRepository_GetUsers(t *testing.T) {
+	var searches []*ldap.SearchRequest
 	mockConn := &MockLDAPConn{
 		SearchFunc: func(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
+			searches = append(searches, searchRequest)
+
 			if searchRequest.Filter == "(&(objectClass=group)(cn=TestGroup))" {
 				entry := ldap.NewEntry("cn=TestGroup,dc=example,dc=com", nil)
 				return &ldap.SearchResult{Entries: []*ldap.Entry{entry}}, nil
@@ -104,13 +154,55 @@ func TestADRepository_GetUsers(t *testing.T) {
 		},
 	}
 
-	repo := &ADRepository{conn: mockConn, config: &configs.ADConfig{BaseDN: "dc=example,dc=com"}}
+	repo := &ADRepository{conn: mockConn, config: &configs.ADConfig{BaseDN: "dc=example,dc=com", GroupMembersLimit: 100}}
 
-	users, err := repo.GetUsers("TestGroup")
+	users, err := repo.GetUsers(context.Background(), "TestGroup")
 	assert.NoError(t, err)
 	assert.Len(t, users, 2)
 	assert.Equal(t, "user1", users[0].SAMAccountName)
 	assert.Equal(t, "user2", users[1].SAMAccountName)
+	assert.Empty(t, users[0].Groups)
+
+	// Apenas a busca do grupo e a busca paginada dos membros, sem resolver os grupos de cada membro
+	if assert.Len(t, searches, 2) {
+		assert.Equal(t, 100, searches[1].SizeLimit)
+		paging, ok := ldap.FindControl(searches[1].Controls, ldap.ControlTypePaging).(*ldap.ControlPaging)
+		assert.True(t, ok)
+		assert.Equal(t, uint32(membersPageSize), paging.PagingSize)
+	}
+}
+
+func TestADRepository_GetUsers_GroupTooLarge(t *testing.T) {
+	mockConn := &MockLDAPConn{
+		SearchFunc: func(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
+			if searchRequest.Filter == "(&(objectClass=group)(cn=Todos))" {
+				entry := ldap.NewEntry("cn=Todos,dc=example,dc=com", nil)
+				return &ldap.SearchResult{Entries: []*ldap.Entry{entry}}, nil
+			}
+
+			return nil, ldap.NewError(ldap.LDAPResultSizeLimitExceeded, errors.New("size limit exceeded"))
+		},
+	}
+
+	repo := &ADRepository{conn: mockConn, config: &configs.ADConfig{BaseDN: "dc=example,dc=com", GroupMembersLimit: 100}}
+
+	_, err := repo.GetUsers(context.Background(), "Todos")
+	assert.ErrorIs(t, err, models.ErrGroupTooLarge)
+	assert.True(t, models.IsRequestError(err))
+}
+
+func TestADRepository_GetUsers_GroupNotFound(t *testing.T) {
+	mockConn := &MockLDAPConn{
+		SearchFunc: func(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
+			return &ldap.SearchResult{}, nil
+		},
+	}
+
+	repo := &ADRepository{conn: mockConn, config: &configs.ADConfig{BaseDN: "dc=example,dc=com"}}
+
+	_, err := repo.GetUsers(context.Background(), "Inexistente")
+	assert.ErrorIs(t, err, models.ErrGroupNotFound)
+	assert.True(t, models.IsRequestError(err))
 }
 
 func TestADRepository_Close(t *testing.T) {
@@ -127,22 +219,42 @@ func TestADRepository_Close(t *testing.T) {
 }
 
 func TestADRepository_Bind(t *testing.T) {
-	mockConn := &MockLDAPConn{
-		BindFunc: func(username, password string) error {
-			if username == "validUser@domain.com" && password == "validPassword" {
-				return nil
-			}
-			return ldap.NewError(ldap.LDAPResultInvalidCredentials, nil)
-		},
-	}
+	dialBind, _, closed := newBindDialer(validUserBind)
 
-	repo := &ADRepository{conn: mockConn, config: &configs.ADConfig{Domain: "domain.com"}}
+	repo := &ADRepository{dialBind: dialBind, config: &configs.ADConfig{Domain: "domain.com"}}
 
-	err := repo.Bind("validUser", "validPassword")
+	err := repo.Bind(context.Background(), "validUser", "validPassword")
 	assert.NoError(t, err)
 
-	err = repo.Bind("invalidUser", "invalidPassword")
+	err = repo.Bind(context.Background(), "invalidUser", "invalidPassword")
 	assert.Error(t, err)
+	assert.Equal(t, 2, *closed)
+}
+
+func TestADRepository_Ping(t *testing.T) {
+	dialBind, opened, closed := newBindDialer(validUserBind)
+
+	repo := &ADRepository{dialBind: dialBind, config: &configs.ADConfig{Username: "validUser", Password: "validPassword", Domain: "domain.com"}}
+	assert.NoError(t, repo.Ping(context.Background()))
+
+	repo.config.Password = "expired"
+	err := repo.Ping(context.Background())
+	assert.True(t, ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials))
+	assert.Equal(t, 2, *opened)
+	assert.Equal(t, 2, *closed)
+
+	repo.dialBind = func(ctx context.Context) (ILDAPConnection, error) {
+		return nil, ldap.NewError(ldap.ErrorNetwork, nil)
+	}
+	assert.ErrorIs(t, repo.Ping(context.Background()), models.ErrDirectoryUnavailable)
+}
+
+func TestServiceAccountName(t *testing.T) {
+	assert.Equal(t, "svc@domain.com", ServiceAccountName(&configs.ADConfig{Username: "svc", Domain: "domain.com"}))
+	assert.Equal(t, "svc@other.com", ServiceAccountName(&configs.ADConfig{Username: "svc@other.com", Domain: "domain.com"}))
+	assert.Equal(t, `DOMAIN\svc`, ServiceAccountName(&configs.ADConfig{Username: `DOMAIN\svc`, Domain: "domain.com"}))
+	assert.Equal(t, "cn=svc,dc=domain,dc=com", ServiceAccountName(&configs.ADConfig{Username: "cn=svc,dc=domain,dc=com"}))
+	assert.Equal(t, "", ServiceAccountName(&configs.ADConfig{Domain: "domain.com"}))
 }
 
 func TestADRepository_Unbind(t *testing.T) {
@@ -154,6 +266,99 @@ func TestADRepository_Unbind(t *testing.T) {
 
 	repo := &ADRepository{conn: mockConn}
 
-	err := repo.Unbind()
+	err := repo.Unbind(context.Background())
 	assert.NoError(t, err)
 }
+
+func TestADRepository_GetUser_EscapesFilter(t *testing.T) {
+	var filter string
+	mockConn := &MockLDAPConn{
+		SearchFunc: func(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
+			filter = searchRequest.Filter
+			return &ldap.SearchResult{}, nil
+		},
+	}
+
+	repo := &ADRepository{conn: mockConn, config: &configs.ADConfig{BaseDN: "dc=example,dc=com"}}
+
+	_, err := repo.GetUser(context.Background(), "joão(admin)")
+	assert.Error(t, err)
+	assert.Equal(t, `(&(objectClass=user)(sAMAccountName=joão\28admin\29))`, filter)
+}
+
+func TestADRepository_RejectsInvalidUsername(t *testing.T) {
+	mockConn := &MockLDAPConn{
+		SearchFunc: func(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
+			t.Error("Nomes de usuário inválidos não devem chegar ao AD")
+			return nil, nil
+		},
+	}
+	dialBind := func(ctx context.Context) (ILDAPConnection, error) {
+		t.Error("Nomes de usuário inválidos não devem chegar ao AD")
+		return nil, nil
+	}
+
+	repo := &ADRepository{conn: mockConn, dialBind: dialBind, config: &configs.ADConfig{Domain: "domain.com"}}
+
+	_, err := repo.GetUser(context.Background(), "*)(objectClass=*")
+	assert.ErrorIs(t, err, models.ErrInvalidUsername)
+
+	success, err := repo.Authenticate(context.Background(), "admin*", "password")
+	assert.ErrorIs(t, err, models.ErrInvalidUsername)
+	assert.False(t, success)
+}
+
+func TestValidateUsername(t *testing.T) {
+	assert.NoError(t, ValidateUsername("jdoe"))
+	assert.NoError(t, ValidateUsername("joão.silva"))
+	assert.NoError(t, ValidateUsername("maria-souza_01"))
+
+	for _, username := range []string{"", "   ", "a*b", "a?b", "dom\\user", "a,b", "a=b", "a\x00b", "a\nb", strings.Repeat("a", 257)} {
+		assert.ErrorIs(t, ValidateUsername(username), models.ErrInvalidUsername, username)
+	}
+}
+
+func TestADRepository_ErrorClassification(t *testing.T) {
+	dialBind, _, _ := newBindDialer(validUserBind)
+	notFoundConn := &MockLDAPConn{
+		SearchFunc: func(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
+			return &ldap.SearchResult{}, nil
+		},
+	}
+
+	repo := &ADRepository{conn: notFoundConn, dialBind: dialBind, config: &configs.ADConfig{Domain: "domain.com"}}
+
+	_, err := repo.Authenticate(context.Background(), "validUser", "wrongPassword")
+	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+	assert.True(t, models.IsRequestError(err))
+
+	_, err = repo.GetUser(context.Background(), "ghost")
+	assert.ErrorIs(t, err, models.ErrUserNotFound)
+	assert.True(t, models.IsRequestError(err))
+
+	unreachable := &ADRepository{
+		conn: &MockLDAPConn{
+			SearchFunc: func(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
+				return nil, ldap.NewError(ldap.ErrorNetwork, errors.New("connection reset"))
+			},
+		},
+		dialBind: func(ctx context.Context) (ILDAPConnection, error) {
+			return nil, ldap.NewError(ldap.ErrorNetwork, errors.New("connection refused"))
+		},
+		config: &configs.ADConfig{Domain: "domain.com"},
+	}
+
+	_, err = unreachable.Authenticate(context.Background(), "validUser", "validPassword")
+	assert.ErrorIs(t, err, models.ErrDirectoryUnavailable)
+	assert.False(t, models.IsRequestError(err))
+
+	_, err = unreachable.GetUser(context.Background(), "validUser")
+	assert.ErrorIs(t, err, models.ErrDirectoryUnavailable)
+	assert.False(t, models.IsRequestError(err))
+
+	expired, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = repo.GetUser(expired, "validUser")
+	assert.ErrorIs(t, err, models.ErrDirectoryUnavailable)
+}
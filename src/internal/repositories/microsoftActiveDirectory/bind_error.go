@@ -0,0 +1,99 @@
+package microsoftActiveDirectory
+
+import (
+	"auth-ad/src/internal/models"
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// bindDiagnosticPattern extrai o sub-código da mensagem de diagnóstico do AD,
+// ex.: "80090308: LdapErr: DSID-0C09044E, comment: AcceptSecurityContext error, data 52e, v4563"
+var bindDiagnosticPattern = regexp.MustCompile(`(?i)\bdata ([0-9a-f]+)`)
+
+// bindFailureReasons mapeia os sub-códigos de falha de bind do AD para os motivos de falha
+var bindFailureReasons = map[string]models.FailureReason{
+	"525": models.ReasonUserNotFound,
+	"52e": models.ReasonInvalidCredentials,
+	"530": models.ReasonLogonRestricted,
+	"531": models.ReasonLogonRestricted,
+	"532": models.ReasonPasswordExpired,
+	"533": models.ReasonAccountDisabled,
+	"701": models.ReasonAccountExpired,
+	"773": models.ReasonMustChangePassword,
+	"775": models.ReasonAccountLocked,
+}
+
+// systemicBindResults são os resultados de bind causados pelo servidor, pela conexão ou pela
+// configuração do serviço, e não pelas credenciais enviadas, tratados como indisponibilidade do AD
+var systemicBindResults = map[uint16]bool{
+	ldap.LDAPResultOperationsError:              true,
+	ldap.LDAPResultProtocolError:                true,
+	ldap.LDAPResultTimeLimitExceeded:            true,
+	ldap.LDAPResultAuthMethodNotSupported:       true,
+	ldap.LDAPResultStrongAuthRequired:           true,
+	ldap.LDAPResultAdminLimitExceeded:           true,
+	ldap.LDAPResultUnavailableCriticalExtension: true,
+	ldap.LDAPResultConfidentialityRequired:      true,
+	ldap.LDAPResultBusy:                         true,
+	ldap.LDAPResultUnavailable:                  true,
+	ldap.LDAPResultLoopDetect:                   true,
+	ldap.LDAPResultOther:                        true,
+	ldap.LDAPResultServerDown:                   true,
+	ldap.LDAPResultLocalError:                   true,
+	ldap.LDAPResultEncodingError:                true,
+	ldap.LDAPResultDecodingError:                true,
+	ldap.LDAPResultTimeout:                      true,
+	ldap.LDAPResultConnectError:                 true,
+	ldap.ErrorNetwork:                           true,
+	ldap.ErrorUnexpectedMessage:                 true,
+	ldap.ErrorUnexpectedResponse:                true,
+}
+
+// classifyBindError converte a falha no bind de um usuário em erro da requisição ou em
+// indisponibilidade do AD. Apenas falhas de rede, do pool, de prazo e os resultados de
+// systemicBindResults são sistêmicos; os demais resultados LDAP (ex.: unwillingToPerform,
+// constraintViolation) recusam as credenciais enviadas.
+// Params:
+//   - err: Erro retornado no bind
+//
+// Returns:
+//   - error: *models.AuthError para as credenciais recusadas, ou o erro traduzido por translateError
+func classifyBindError(err error) error {
+	var ldapErr *ldap.Error
+	if !errors.As(err, &ldapErr) || systemicBindResults[ldapErr.ResultCode] ||
+		errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return translateError(err)
+	}
+
+	if ldapErr.ResultCode == ldap.LDAPResultInvalidCredentials {
+		return newBindError(err)
+	}
+
+	return &models.AuthError{Reason: models.ReasonInvalidCredentials, Err: err}
+}
+
+// newBindError converte uma falha de bind por credenciais inválidas (resultado 49) em
+// models.AuthError, identificando o motivo pelo sub-código de diagnóstico do AD
+// Params:
+//   - err: Erro retornado no bind
+//
+// Returns:
+//   - error: *models.AuthError com o motivo da falha
+func newBindError(err error) error {
+	reason := models.ReasonInvalidCredentials
+
+	var ldapErr *ldap.Error
+	if errors.As(err, &ldapErr) && ldapErr.Err != nil {
+		if match := bindDiagnosticPattern.FindStringSubmatch(ldapErr.Err.Error()); match != nil {
+			if mapped, ok := bindFailureReasons[strings.ToLower(match[1])]; ok {
+				reason = mapped
+			}
+		}
+	}
+
+	return &models.AuthError{Reason: reason, Err: err}
+}
@@ -0,0 +1,96 @@
+package microsoftActiveDirectory
+
+import (
+	"auth-ad/src/internal/models"
+	"auth-ad/src/pkg/configs"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+// newADBindError simula o erro retornado pelo AD em um bind com falha
+func newADBindError(subCode string) error {
+	diagnostic := fmt.Sprintf("80090308: LdapErr: DSID-0C09044E, comment: AcceptSecurityContext error, data %s, v4563", subCode)
+	return ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New(diagnostic))
+}
+
+func TestNewBindError(t *testing.T) {
+	cases := map[string]models.FailureReason{
+		"525": models.ReasonUserNotFound,
+		"52e": models.ReasonInvalidCredentials,
+		"52E": models.ReasonInvalidCredentials,
+		"530": models.ReasonLogonRestricted,
+		"531": models.ReasonLogonRestricted,
+		"532": models.ReasonPasswordExpired,
+		"533": models.ReasonAccountDisabled,
+		"701": models.ReasonAccountExpired,
+		"773": models.ReasonMustChangePassword,
+		"775": models.ReasonAccountLocked,
+		"999": models.ReasonInvalidCredentials,
+	}
+
+	for subCode, reason := range cases {
+		err := newBindError(newADBindError(subCode))
+		assert.Equal(t, reason, models.ReasonFor(err), subCode)
+		assert.True(t, models.IsRequestError(err), subCode)
+	}
+
+	assert.ErrorIs(t, newBindError(newADBindError("525")), models.ErrUserNotFound)
+	assert.ErrorIs(t, newBindError(newADBindError("775")), models.ErrInvalidCredentials)
+}
+
+func TestClassifyBindError(t *testing.T) {
+	requestErrors := []error{
+		ldap.NewError(ldap.LDAPResultUnwillingToPerform, errors.New("unwilling")),
+		ldap.NewError(ldap.LDAPResultConstraintViolation, errors.New("constraint")),
+		ldap.NewError(ldap.LDAPResultInappropriateAuthentication, errors.New("inappropriate")),
+		ldap.NewError(ldap.ErrorEmptyPassword, errors.New("empty password")),
+	}
+	for _, err := range requestErrors {
+		classified := classifyBindError(err)
+		assert.Equal(t, models.ReasonInvalidCredentials, models.ReasonFor(classified), err.Error())
+		assert.True(t, models.IsRequestError(classified), err.Error())
+	}
+
+	systemicErrors := []error{
+		ldap.NewError(ldap.ErrorNetwork, errors.New("connection reset")),
+		ldap.NewError(ldap.LDAPResultBusy, errors.New("busy")),
+		ldap.NewError(ldap.LDAPResultUnavailable, errors.New("unavailable")),
+		ErrPoolTimeout,
+		context.DeadlineExceeded,
+		errors.New("erro desconhecido"),
+	}
+	for _, err := range systemicErrors {
+		classified := classifyBindError(err)
+		assert.Equal(t, models.ReasonDirectoryUnavailable, models.ReasonFor(classified), err.Error())
+		assert.False(t, models.IsRequestError(classified), err.Error())
+	}
+
+	assert.Equal(t, models.ReasonAccountLocked, models.ReasonFor(classifyBindError(newADBindError("775"))))
+}
+
+func TestADRepository_Authenticate_EmptyPassword(t *testing.T) {
+	dialBind, opened, _ := newBindDialer(validUserBind)
+	repo := &ADRepository{dialBind: dialBind, config: &configs.ADConfig{Domain: "domain.com"}}
+
+	success, err := repo.Authenticate(context.Background(), "validUser", "")
+	assert.False(t, success)
+	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+	assert.Equal(t, 0, *opened)
+}
+
+func TestADRepository_Authenticate_FailureReason(t *testing.T) {
+	dialBind, _, _ := newBindDialer(func(username, password string) error {
+		return newADBindError("775")
+	})
+
+	repo := &ADRepository{dialBind: dialBind, config: &configs.ADConfig{Domain: "domain.com"}}
+
+	success, err := repo.Authenticate(context.Background(), "lockedUser", "password")
+	assert.False(t, success)
+	assert.Equal(t, models.ReasonAccountLocked, models.ReasonFor(err))
+}
@@ -0,0 +1,133 @@
+package microsoftActiveDirectory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// searchBufferSize é a quantidade de entradas recebidas do servidor que podem aguardar leitura
+const searchBufferSize = 16
+
+// ldapConn adapta *ldap.Conn para ILDAPConnection, respeitando o cancelamento e o prazo do contexto
+type ldapConn struct {
+	conn *ldap.Conn
+}
+
+// newLDAPConn envolve uma conexão do go-ldap em uma ILDAPConnection
+func newLDAPConn(conn *ldap.Conn) ILDAPConnection {
+	return &ldapConn{conn: conn}
+}
+
+// Bind autentica a conexão. O bind do go-ldap não aceita contexto, então a conexão é fechada
+// caso o contexto seja cancelado antes da resposta do servidor.
+// Params:
+//   - ctx: Contexto da operação
+//   - username: Nome do usuário
+//   - password: Senha do usuário
+//
+// Returns:
+//   - error: Erro em caso de falha no bind ou cancelamento do contexto
+func (c *ldapConn) Bind(ctx context.Context, username, password string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	stop := context.AfterFunc(ctx, func() { c.conn.Close() })
+	err := c.conn.Bind(username, password)
+	if !stop() {
+		return fmt.Errorf("bind interrompido: %w", ctx.Err())
+	}
+
+	return err
+}
+
+// Search executa uma busca que é abandonada caso o contexto seja cancelado. Quando a requisição
+// traz um controle de paginação (ldap.ControlPaging), todas as páginas são lidas nesta conexão,
+// que o AD exige para continuar a busca. A paginação usa uma cópia do controle, sempre iniciada
+// sem cookie, para que a requisição do chamador não seja alterada e possa ser repetida em outra
+// conexão após uma falha no meio da paginação.
+// Params:
+//   - ctx: Contexto da operação
+//   - searchRequest: Requisição de busca
+//
+// Returns:
+//   - *ldap.SearchResult: Resultado da busca
+//   - error: Erro em caso de falha na busca ou cancelamento do contexto
+func (c *ldapConn) Search(ctx context.Context, searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	original, ok := ldap.FindControl(searchRequest.Controls, ldap.ControlTypePaging).(*ldap.ControlPaging)
+	if !ok {
+		return c.search(ctx, searchRequest)
+	}
+
+	paging := ldap.NewControlPaging(original.PagingSize)
+	request := *searchRequest
+	request.Controls = make([]ldap.Control, 0, len(searchRequest.Controls))
+	for _, control := range searchRequest.Controls {
+		if control == original {
+			control = paging
+		}
+		request.Controls = append(request.Controls, control)
+	}
+
+	result := &ldap.SearchResult{}
+	for {
+		page, err := c.search(ctx, &request)
+		if err != nil {
+			return nil, err
+		}
+		result.Entries = append(result.Entries, page.Entries...)
+		result.Referrals = append(result.Referrals, page.Referrals...)
+
+		next, ok := ldap.FindControl(page.Controls, ldap.ControlTypePaging).(*ldap.ControlPaging)
+		if !ok || len(next.Cookie) == 0 {
+			return result, nil
+		}
+		paging.SetCookie(next.Cookie)
+	}
+}
+
+// search executa uma única requisição de busca, abandonada caso o contexto seja cancelado
+func (c *ldapConn) search(ctx context.Context, searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &ldap.SearchResult{}
+	response := c.conn.SearchAsync(ctx, searchRequest, searchBufferSize)
+	for response.Next() {
+		if entry := response.Entry(); entry != nil {
+			result.Entries = append(result.Entries, entry)
+		}
+		if referral := response.Referral(); referral != "" {
+			result.Referrals = append(result.Referrals, referral)
+		}
+		if controls := response.Controls(); len(controls) > 0 {
+			result.Controls = append(result.Controls, controls...)
+		}
+	}
+
+	if err := response.Err(); err != nil {
+		// A queda da conexão durante a busca é informada pelo go-ldap sem o código ErrorNetwork
+		if c.conn.IsClosing() && !ldap.IsErrorWithCode(err, ldap.ErrorNetwork) {
+			return nil, ldap.NewError(ldap.ErrorNetwork, err)
+		}
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("busca interrompida: %w", err)
+	}
+
+	return result, nil
+}
+
+// Unbind encerra a sessão LDAP e fecha a conexão
+func (c *ldapConn) Unbind() error {
+	return c.conn.Unbind()
+}
+
+// Close fecha a conexão
+func (c *ldapConn) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,130 @@
+package microsoftActiveDirectory
+
+import (
+	"auth-ad/src/pkg/configs"
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePagingServer responde buscas paginadas com uma entrada por página, em duas páginas. A
+// primeira conexão é encerrada ao receber o pedido da segunda página.
+type fakePagingServer struct {
+	mu      sync.Mutex
+	conns   int
+	cookies [][]string // Cookies recebidos em cada conexão
+}
+
+// dial abre uma conexão com o servidor por um net.Pipe
+func (s *fakePagingServer) dial(ctx context.Context) (ILDAPConnection, error) {
+	client, server := net.Pipe()
+
+	s.mu.Lock()
+	index := s.conns
+	s.conns++
+	s.cookies = append(s.cookies, nil)
+	s.mu.Unlock()
+
+	go s.serve(server, index)
+
+	conn := ldap.NewConn(client, false)
+	conn.Start()
+	return newLDAPConn(conn), nil
+}
+
+// serve atende as requisições de uma conexão
+func (s *fakePagingServer) serve(conn net.Conn, index int) {
+	defer conn.Close()
+
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		messageID := packet.Children[0].Value.(int64)
+		if packet.Children[1].Tag != ldap.ApplicationSearchRequest {
+			return
+		}
+
+		cookie := ""
+		if len(packet.Children) > 2 {
+			for _, child := range packet.Children[2].Children {
+				if paging, err := ldap.DecodeControl(child); err == nil {
+					if paging, ok := paging.(*ldap.ControlPaging); ok {
+						cookie = string(paging.Cookie)
+					}
+				}
+			}
+		}
+
+		s.mu.Lock()
+		s.cookies[index] = append(s.cookies[index], cookie)
+		s.mu.Unlock()
+
+		if cookie != "" && index == 0 {
+			// Falha de conexão no meio da paginação
+			return
+		}
+
+		next := ldap.NewControlPaging(1)
+		dn := "CN=membro1,DC=domain,DC=com"
+		if cookie != "" {
+			dn = "CN=membro2,DC=domain,DC=com"
+		} else {
+			next.SetCookie([]byte("pagina-2"))
+		}
+
+		entry := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+		entry.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, dn, "DN"))
+		entry.AppendChild(ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes"))
+
+		done := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultDone, nil, "Search Result Done")
+		done.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(ldap.LDAPResultSuccess), "Result Code"))
+		done.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+		done.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Diagnostic Message"))
+
+		controls := ber.Encode(ber.ClassContext, ber.TypeConstructed, 0, nil, "Controls")
+		controls.AppendChild(next.Encode())
+
+		for _, op := range []*ber.Packet{entry, done} {
+			message := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+			message.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "Message ID"))
+			message.AppendChild(op)
+			if op == done {
+				message.AppendChild(controls)
+			}
+			if _, err := conn.Write(message.Bytes()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func TestLDAPPool_Search_RetriesPagingFromStart(t *testing.T) {
+	server := &fakePagingServer{}
+	pool, err := NewLDAPPool(&configs.ADConfig{PoolMaxSize: 1}, server.dial)
+	if err != nil {
+		t.Fatalf("Erro ao criar pool: %v", err)
+	}
+	defer pool.Close()
+
+	request := ldap.NewSearchRequest("DC=domain,DC=com", ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		"(objectClass=user)", []string{"cn"}, []ldap.Control{ldap.NewControlPaging(1)})
+
+	// A falha na segunda página é repetida em uma nova conexão, que recomeça a paginação sem cookie
+	result, err := pool.Search(context.Background(), request)
+	assert.NoError(t, err)
+	if assert.NotNil(t, result) {
+		assert.Len(t, result.Entries, 2)
+	}
+	assert.Equal(t, [][]string{{"", "pagina-2"}, {"", "pagina-2"}}, server.cookies)
+
+	// A requisição do chamador não é alterada
+	paging := ldap.FindControl(request.Controls, ldap.ControlTypePaging).(*ldap.ControlPaging)
+	assert.Empty(t, paging.Cookie)
+}
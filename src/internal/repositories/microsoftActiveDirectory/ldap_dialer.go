@@ -0,0 +1,202 @@
+package microsoftActiveDirectory
+
+import (
+	"auth-ad/src/pkg/configs"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// tlsVersions mapeia as versões aceitas na configuração para as constantes do crypto/tls
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// DialFunc abre uma nova conexão LDAP, desistindo quando o contexto é cancelado
+type DialFunc func(ctx context.Context) (ILDAPConnection, error)
+
+// NewDialFunc cria uma DialFunc que abre conexões com o Active Directory usando Dial
+// Params:
+//   - config: Configurações de conexão com o Active Directory
+//
+// Returns:
+//   - DialFunc: Função de conexão
+func NewDialFunc(config *configs.ADConfig) DialFunc {
+	return func(ctx context.Context) (ILDAPConnection, error) {
+		conn, err := Dial(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+
+		return newLDAPConn(conn), nil
+	}
+}
+
+// Dial abre uma nova conexão com o Active Directory usando o modo de transporte configurado. A
+// conexão TCP e a negociação TLS são limitadas por config.DialTimeout e interrompidas quando o
+// contexto é cancelado.
+// Params:
+//   - ctx: Contexto da operação que precisa da conexão
+//   - config: Configurações de conexão com o Active Directory
+//
+// Returns:
+//   - *ldap.Conn: Conexão estabelecida
+//   - error: Erro em caso de falha na conexão ou na negociação TLS
+func Dial(ctx context.Context, config *configs.ADConfig) (*ldap.Conn, error) {
+	var tlsConfig *tls.Config
+	switch config.TransportMode {
+	case "", configs.TransportPlain:
+	case configs.TransportStartTLS, configs.TransportLDAPS:
+		var err error
+		if tlsConfig, err = NewTLSConfig(config); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("modo de transporte inválido: %s", config.TransportMode)
+	}
+
+	dialer := &net.Dialer{Timeout: config.DialTimeout}
+	raw, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(config.Server, strconv.Itoa(config.Port)))
+	if err != nil {
+		return nil, ldap.NewError(ldap.ErrorNetwork, err)
+	}
+
+	switch config.TransportMode {
+	case configs.TransportLDAPS:
+		tlsConn := tls.Client(raw, tlsConfig)
+		if err := negotiate(ctx, raw, config.DialTimeout, tlsConn.Handshake); err != nil {
+			raw.Close()
+			return nil, ldap.NewError(ldap.ErrorNetwork, fmt.Errorf("erro na negociação TLS: %w", err))
+		}
+
+		conn := ldap.NewConn(tlsConn, true)
+		conn.Start()
+		return conn, nil
+
+	case configs.TransportStartTLS:
+		conn := ldap.NewConn(raw, false)
+		conn.Start()
+
+		if err := negotiate(ctx, raw, config.DialTimeout, func() error { return conn.StartTLS(tlsConfig) }); err != nil {
+			conn.Close()
+			return nil, ldap.NewError(ldap.ErrorNetwork, fmt.Errorf("erro ao iniciar StartTLS: %w", err))
+		}
+
+		return conn, nil
+	}
+
+	conn := ldap.NewConn(raw, false)
+	conn.Start()
+	return conn, nil
+}
+
+// negotiate executa a negociação TLS de uma conexão recém-aberta dentro do timeout, encerrando-a
+// pelo prazo da conexão quando o contexto é cancelado
+func negotiate(ctx context.Context, raw net.Conn, timeout time.Duration, handshake func() error) error {
+	if timeout > 0 {
+		raw.SetDeadline(time.Now().Add(timeout))
+	}
+	stop := context.AfterFunc(ctx, func() {
+		raw.SetDeadline(time.Unix(1, 0))
+	})
+
+	err := handshake()
+	if !stop() {
+		return ctx.Err()
+	}
+	raw.SetDeadline(time.Time{})
+
+	return err
+}
+
+// NewTLSConfig monta a configuração TLS usada nos modos StartTLS e LDAPS
+// Params:
+//   - config: Configurações de conexão com o Active Directory
+//
+// Returns:
+//   - *tls.Config: Configuração TLS com CAs, pinning, nome do servidor e versão mínima
+//   - error: Erro em caso de configuração inválida
+func NewTLSConfig(config *configs.ADConfig) (*tls.Config, error) {
+	minVersion, ok := tlsVersions[config.TLSMinVersion]
+	if config.TLSMinVersion == "" {
+		minVersion, ok = tls.VersionTLS12, true
+	}
+	if !ok {
+		return nil, fmt.Errorf("versão mínima do TLS inválida: %s", config.TLSMinVersion)
+	}
+
+	serverName := config.TLSServerName
+	if serverName == "" {
+		serverName = config.Server
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName: serverName,
+		MinVersion: minVersion,
+	}
+
+	if config.TLSCAFile != "" {
+		pem, err := os.ReadFile(config.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler bundle de CAs: %v", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("nenhum certificado válido encontrado em %s", config.TLSCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if len(config.TLSPinnedKeys) > 0 {
+		pins := make(map[string]bool, len(config.TLSPinnedKeys))
+		for _, pin := range config.TLSPinnedKeys {
+			pins[normalizeFingerprint(pin)] = true
+		}
+
+		tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+			return verifyPinnedKey(state, pins)
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// PublicKeyFingerprint calcula o fingerprint SHA-256 (hex) da chave pública de um certificado,
+// no formato esperado em AD_TLS_PINNED_SHA256
+func PublicKeyFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return hex.EncodeToString(sum[:])
+}
+
+// verifyPinnedKey confere se o certificado apresentado pelo servidor possui uma das chaves fixadas
+func verifyPinnedKey(state tls.ConnectionState, pins map[string]bool) error {
+	if len(state.PeerCertificates) == 0 {
+		return fmt.Errorf("servidor não apresentou certificado")
+	}
+
+	fingerprint := PublicKeyFingerprint(state.PeerCertificates[0])
+	if !pins[fingerprint] {
+		return fmt.Errorf("chave pública do servidor não confere com o pinning configurado: %s", fingerprint)
+	}
+
+	return nil
+}
+
+// normalizeFingerprint remove separadores e padroniza o fingerprint em minúsculas
+func normalizeFingerprint(fingerprint string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(fingerprint), ":", ""))
+}
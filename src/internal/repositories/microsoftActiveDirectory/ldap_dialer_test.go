@@ -0,0 +1,171 @@
+package microsoftActiveDirectory
+
+import (
+	"auth-ad/src/pkg/configs"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestCertificate gera um certificado autoassinado para os testes de TLS
+func newTestCertificate(t *testing.T) (*x509.Certificate, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Erro ao gerar chave: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "dc01.example.com"},
+		DNSNames:     []string{"dc01.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Erro ao criar certificado: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Erro ao ler certificado: %v", err)
+	}
+
+	return cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestNewTLSConfig_Defaults(t *testing.T) {
+	tlsConfig, err := NewTLSConfig(&configs.ADConfig{Server: "dc01.example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, "dc01.example.com", tlsConfig.ServerName)
+	assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+	assert.Nil(t, tlsConfig.RootCAs)
+	assert.Nil(t, tlsConfig.VerifyConnection)
+}
+
+func TestNewTLSConfig_CustomOptions(t *testing.T) {
+	_, certPEM := newTestCertificate(t)
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, certPEM, 0600); err != nil {
+		t.Fatalf("Erro ao gravar CA: %v", err)
+	}
+
+	tlsConfig, err := NewTLSConfig(&configs.ADConfig{
+		Server:        "10.0.0.1",
+		TLSServerName: "dc01.example.com",
+		TLSMinVersion: "1.3",
+		TLSCAFile:     caFile,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "dc01.example.com", tlsConfig.ServerName)
+	assert.Equal(t, uint16(tls.VersionTLS13), tlsConfig.MinVersion)
+	assert.NotNil(t, tlsConfig.RootCAs)
+}
+
+func TestNewTLSConfig_InvalidOptions(t *testing.T) {
+	_, err := NewTLSConfig(&configs.ADConfig{TLSMinVersion: "0.9"})
+	assert.Error(t, err)
+
+	_, err = NewTLSConfig(&configs.ADConfig{TLSCAFile: filepath.Join(t.TempDir(), "inexistente.pem")})
+	assert.Error(t, err)
+
+	invalidCA := filepath.Join(t.TempDir(), "invalido.pem")
+	os.WriteFile(invalidCA, []byte("não é um certificado"), 0600)
+	_, err = NewTLSConfig(&configs.ADConfig{TLSCAFile: invalidCA})
+	assert.Error(t, err)
+}
+
+func TestNewTLSConfig_PinnedKeys(t *testing.T) {
+	cert, _ := newTestCertificate(t)
+	otherCert, _ := newTestCertificate(t)
+
+	fingerprint := PublicKeyFingerprint(cert)
+	var formatted []string
+	for i := 0; i < len(fingerprint); i += 2 {
+		formatted = append(formatted, strings.ToUpper(fingerprint[i:i+2]))
+	}
+
+	tlsConfig, err := NewTLSConfig(&configs.ADConfig{TLSPinnedKeys: []string{strings.Join(formatted, ":")}})
+	assert.NoError(t, err)
+	assert.NotNil(t, tlsConfig.VerifyConnection)
+
+	err = tlsConfig.VerifyConnection(tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}})
+	assert.NoError(t, err)
+
+	err = tlsConfig.VerifyConnection(tls.ConnectionState{PeerCertificates: []*x509.Certificate{otherCert}})
+	assert.Error(t, err)
+
+	err = tlsConfig.VerifyConnection(tls.ConnectionState{})
+	assert.Error(t, err)
+}
+
+func TestDial_InvalidTransport(t *testing.T) {
+	_, err := Dial(context.Background(), &configs.ADConfig{TransportMode: "telnet"})
+	assert.Error(t, err)
+}
+
+// newSilentListener aceita conexões TCP sem nunca respondê-las
+func newSilentListener(t *testing.T) (string, int) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Erro ao abrir listener: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port
+}
+
+func TestDial_TLSNegotiationTimeout(t *testing.T) {
+	server, port := newSilentListener(t)
+
+	for _, mode := range []string{configs.TransportStartTLS, configs.TransportLDAPS} {
+		config := &configs.ADConfig{Server: server, Port: port, TransportMode: mode, DialTimeout: 100 * time.Millisecond}
+
+		start := time.Now()
+		_, err := Dial(context.Background(), config)
+		assert.True(t, ldap.IsErrorWithCode(err, ldap.ErrorNetwork), "%s: %v", mode, err)
+		assert.Less(t, time.Since(start), 5*time.Second, mode)
+	}
+}
+
+func TestDial_ContextCanceled(t *testing.T) {
+	server, port := newSilentListener(t)
+	config := &configs.ADConfig{Server: server, Port: port, TransportMode: configs.TransportLDAPS, DialTimeout: time.Minute}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := Dial(ctx, config)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, ldap.IsErrorWithCode(err, ldap.ErrorNetwork))
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
@@ -0,0 +1,452 @@
+package microsoftActiveDirectory
+
+import (
+	"auth-ad/src/pkg/configs"
+	"auth-ad/src/pkg/ldapFilter"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// ErrPoolClosed é retornado ao usar um pool já encerrado
+var ErrPoolClosed = fmt.Errorf("pool de conexões LDAP encerrado")
+
+// ErrPoolTimeout é retornado quando nenhuma conexão fica disponível dentro do tempo limite
+var ErrPoolTimeout = fmt.Errorf("tempo esgotado aguardando conexão LDAP livre")
+
+// probeTimeout é o tempo máximo da leitura do RootDSE usada na verificação de saúde
+const probeTimeout = 5 * time.Second
+
+// pooledConn guarda uma conexão do pool e seus metadados
+type pooledConn struct {
+	conn        ILDAPConnection
+	identity    int
+	lastUsed    time.Time
+	lastChecked time.Time
+}
+
+// PoolStats reúne a ocupação do pool de conexões LDAP
+type PoolStats struct {
+	Open  int // Conexões abertas, em uso ou ociosas
+	Idle  int // Conexões ociosas, disponíveis para uso
+	InUse int // Conexões em uso por uma operação
+	Max   int // Tamanho máximo do pool
+}
+
+// LDAPPool implementa ILDAPConnection sobre um conjunto de conexões reutilizáveis,
+// com verificação de saúde, descarte de conexões ociosas e reconexão automática.
+// Quando há conta de serviço configurada, toda conexão aberta é autenticada com ela.
+type LDAPPool struct {
+	dial   DialFunc
+	config *configs.ADConfig
+
+	idle   chan *pooledConn
+	tokens chan struct{}
+	done   chan struct{}
+
+	mu       sync.Mutex
+	closed   bool
+	identity int
+	username string
+	password string
+}
+
+// NewLDAPPool cria um pool de conexões LDAP e abre as conexões mínimas configuradas
+// Params:
+//   - config: Configurações do Active Directory, incluindo a conta de serviço e os parâmetros do pool
+//   - dial: Função usada para abrir novas conexões
+//
+// Returns:
+//   - *LDAPPool: Pool de conexões
+//   - error: Erro em caso de falha ao abrir as conexões iniciais
+func NewLDAPPool(config *configs.ADConfig, dial DialFunc) (*LDAPPool, error) {
+	maxSize := config.PoolMaxSize
+	if maxSize < 1 {
+		maxSize = 1
+	}
+
+	p := &LDAPPool{
+		dial:   dial,
+		config: config,
+		idle:   make(chan *pooledConn, maxSize),
+		tokens: make(chan struct{}, maxSize),
+		done:   make(chan struct{}),
+	}
+
+	if config.Username != "" {
+		p.identity = 1
+		p.username = ServiceAccountName(config)
+		p.password = config.Password
+	}
+
+	if err := p.fill(); err != nil {
+		p.Close()
+		return nil, err
+	}
+
+	go p.maintain()
+
+	return p, nil
+}
+
+// Bind autentica uma conexão do pool e passa a usar essas credenciais em todas as conexões
+// Params:
+//   - ctx: Contexto da operação
+//   - username: Nome do usuário
+//   - password: Senha do usuário
+//
+// Returns:
+//   - error: Erro em caso de falha no bind
+func (p *LDAPPool) Bind(ctx context.Context, username, password string) error {
+	return p.withConn(ctx, func(pc *pooledConn) error {
+		if err := pc.conn.Bind(ctx, username, password); err != nil {
+			pc.identity = -1
+			return err
+		}
+
+		p.mu.Lock()
+		p.identity++
+		p.username = username
+		p.password = password
+		pc.identity = p.identity
+		p.mu.Unlock()
+
+		return nil
+	})
+}
+
+// Search executa uma busca em uma conexão do pool
+// Params:
+//   - ctx: Contexto da operação, que também limita a espera por uma conexão livre
+//   - searchRequest: Requisição de busca
+//
+// Returns:
+//   - *ldap.SearchResult: Resultado da busca
+//   - error: Erro em caso de falha na busca
+func (p *LDAPPool) Search(ctx context.Context, searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	var result *ldap.SearchResult
+	err := p.withConn(ctx, func(pc *pooledConn) error {
+		if err := p.ensureIdentity(ctx, pc); err != nil {
+			return err
+		}
+
+		var err error
+		result, err = pc.conn.Search(ctx, searchRequest)
+		return err
+	})
+
+	return result, err
+}
+
+// Unbind não encerra as conexões do pool, pois no go-ldap o unbind fecha a conexão.
+// As conexões permanecem abertas e são gerenciadas pelo próprio pool.
+// Returns:
+//   - error: Sempre nil
+func (p *LDAPPool) Unbind() error {
+	return nil
+}
+
+// Close encerra o pool e fecha todas as conexões ociosas.
+// Conexões em uso são fechadas assim que forem devolvidas.
+// Returns:
+//   - error: Erro em caso de falha ao fechar alguma conexão
+func (p *LDAPPool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.done)
+	p.mu.Unlock()
+
+	var lastErr error
+	for {
+		select {
+		case pc := <-p.idle:
+			if err := p.discard(pc); err != nil {
+				lastErr = err
+			}
+		default:
+			return lastErr
+		}
+	}
+}
+
+// Stats retorna a ocupação atual do pool
+// Returns:
+//   - PoolStats: Quantidade de conexões abertas, ociosas e em uso
+func (p *LDAPPool) Stats() PoolStats {
+	open, idle := len(p.tokens), len(p.idle)
+
+	// As duas leituras não são atômicas: uma conexão devolvida entre elas pode ser contada duas vezes
+	inUse := open - idle
+	if inUse < 0 {
+		inUse = 0
+	}
+
+	return PoolStats{Open: open, Idle: idle, InUse: inUse, Max: cap(p.tokens)}
+}
+
+// withConn executa uma operação em uma conexão do pool, reconectando e repetindo
+// uma vez caso a conexão tenha caído. Conexões usadas em operações interrompidas pelo contexto
+// são descartadas, pois podem ter sido fechadas ou ainda receber a resposta abandonada.
+func (p *LDAPPool) withConn(ctx context.Context, operation func(pc *pooledConn) error) error {
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		var pc *pooledConn
+		pc, err = p.acquire(ctx)
+		if err != nil {
+			return err
+		}
+
+		err = operation(pc)
+		if ctx.Err() != nil {
+			p.discard(pc)
+			return err
+		}
+		if isConnectionError(err) {
+			p.discard(pc)
+			continue
+		}
+
+		p.release(pc)
+		return err
+	}
+
+	return err
+}
+
+// acquire obtém uma conexão ociosa saudável ou abre uma nova, respeitando o tamanho máximo do pool
+// e o cancelamento do contexto
+func (p *LDAPPool) acquire(ctx context.Context) (*pooledConn, error) {
+	timer := time.NewTimer(p.acquireTimeout())
+	defer timer.Stop()
+
+	for {
+		if p.isClosed() {
+			return nil, ErrPoolClosed
+		}
+
+		select {
+		case pc := <-p.idle:
+			if p.healthy(pc) {
+				return pc, nil
+			}
+			p.discard(pc)
+			continue
+		default:
+		}
+
+		select {
+		case pc := <-p.idle:
+			if p.healthy(pc) {
+				return pc, nil
+			}
+			p.discard(pc)
+		case p.tokens <- struct{}{}:
+			pc, err := p.open(ctx)
+			if err != nil {
+				<-p.tokens
+				return nil, err
+			}
+			return pc, nil
+		case <-p.done:
+			return nil, ErrPoolClosed
+		case <-timer.C:
+			return nil, ErrPoolTimeout
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// release devolve uma conexão ao pool
+func (p *LDAPPool) release(pc *pooledConn) {
+	pc.lastUsed = time.Now()
+
+	if p.isClosed() {
+		p.discard(pc)
+		return
+	}
+
+	select {
+	case p.idle <- pc:
+	default:
+		p.discard(pc)
+	}
+}
+
+// discard fecha uma conexão e libera sua vaga no pool
+func (p *LDAPPool) discard(pc *pooledConn) error {
+	<-p.tokens
+	return pc.conn.Close()
+}
+
+// open abre uma nova conexão. O chamador deve ter reservado uma vaga em tokens.
+func (p *LDAPPool) open(ctx context.Context) (*pooledConn, error) {
+	conn, err := p.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	pc := &pooledConn{conn: conn, identity: 0, lastUsed: now, lastChecked: now}
+	if err := p.ensureIdentity(ctx, pc); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return pc, nil
+}
+
+// ensureIdentity refaz o bind da conexão caso ela não esteja autenticada com as credenciais atuais do pool
+func (p *LDAPPool) ensureIdentity(ctx context.Context, pc *pooledConn) error {
+	p.mu.Lock()
+	identity, username, password := p.identity, p.username, p.password
+	p.mu.Unlock()
+
+	if pc.identity == identity || username == "" {
+		return nil
+	}
+
+	if err := pc.conn.Bind(ctx, username, password); err != nil {
+		pc.identity = -1
+		return err
+	}
+	pc.identity = identity
+
+	return nil
+}
+
+// healthy verifica se uma conexão ociosa ainda pode ser usada. Conexões ociosas há mais
+// tempo que o limite são descartadas e, após o intervalo de verificação, a conexão é
+// testada com uma leitura do RootDSE.
+func (p *LDAPPool) healthy(pc *pooledConn) bool {
+	now := time.Now()
+	if p.config.PoolIdleTimeout > 0 && now.Sub(pc.lastUsed) > p.config.PoolIdleTimeout {
+		return false
+	}
+
+	if p.config.PoolHealthCheckInterval > 0 && now.Sub(pc.lastChecked) > p.config.PoolHealthCheckInterval {
+		if err := probe(pc.conn); err != nil {
+			return false
+		}
+		pc.lastChecked = now
+	}
+
+	return true
+}
+
+// fill abre conexões até atingir o tamanho mínimo do pool
+func (p *LDAPPool) fill() error {
+	for len(p.tokens) < p.config.PoolMinSize {
+		select {
+		case p.tokens <- struct{}{}:
+		default:
+			return nil
+		}
+
+		pc, err := p.open(context.Background())
+		if err != nil {
+			<-p.tokens
+			return fmt.Errorf("erro ao abrir conexão do pool: %v", err)
+		}
+		p.release(pc)
+	}
+
+	return nil
+}
+
+// maintain descarta periodicamente as conexões ociosas inválidas e repõe o tamanho mínimo
+func (p *LDAPPool) maintain() {
+	interval := p.maintenanceInterval()
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			p.evict()
+			p.fill()
+		}
+	}
+}
+
+// evict verifica as conexões ociosas no momento, descartando as que não estiverem saudáveis
+func (p *LDAPPool) evict() {
+	count := len(p.idle)
+	for i := 0; i < count; i++ {
+		select {
+		case pc := <-p.idle:
+			if p.healthy(pc) {
+				p.release(pc)
+			} else {
+				p.discard(pc)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// maintenanceInterval calcula o intervalo da rotina de manutenção a partir dos tempos configurados
+func (p *LDAPPool) maintenanceInterval() time.Duration {
+	interval := p.config.PoolHealthCheckInterval
+	if p.config.PoolIdleTimeout > 0 && (interval <= 0 || p.config.PoolIdleTimeout < interval) {
+		interval = p.config.PoolIdleTimeout
+	}
+
+	return interval
+}
+
+// acquireTimeout retorna o tempo máximo de espera por uma conexão livre
+func (p *LDAPPool) acquireTimeout() time.Duration {
+	if p.config.PoolAcquireTimeout > 0 {
+		return p.config.PoolAcquireTimeout
+	}
+
+	return 10 * time.Second
+}
+
+// isClosed indica se o pool já foi encerrado
+func (p *LDAPPool) isClosed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.closed
+}
+
+// probe testa a conexão lendo o RootDSE
+func probe(conn ILDAPConnection) error {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	_, err := conn.Search(ctx, ldap.NewSearchRequest(
+		"",
+		ldap.ScopeBaseObject,
+		ldap.NeverDerefAliases,
+		0,
+		5,
+		false,
+		ldapFilter.Present("objectClass").String(),
+		[]string{"defaultNamingContext"},
+		nil,
+	))
+
+	return err
+}
+
+// isConnectionError indica se o erro representa perda da conexão com o servidor
+func isConnectionError(err error) bool {
+	return err != nil && ldap.IsErrorWithCode(err, ldap.ErrorNetwork)
+}
@@ -0,0 +1,279 @@
+package microsoftActiveDirectory
+
+import (
+	"auth-ad/src/pkg/configs"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePoolConn simula uma conexão LDAP registrando as operações executadas
+type fakePoolConn struct {
+	mu      sync.Mutex
+	broken  bool
+	closed  bool
+	binds   []string
+	bindErr error
+}
+
+func (c *fakePoolConn) setBroken(broken bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.broken = broken
+}
+
+func (c *fakePoolConn) setBindErr(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.bindErr = err
+}
+
+func (c *fakePoolConn) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+func (c *fakePoolConn) Bind(ctx context.Context, username, password string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.broken {
+		return ldap.NewError(ldap.ErrorNetwork, errors.New("ldap: connection closed"))
+	}
+	c.binds = append(c.binds, username)
+	return c.bindErr
+}
+
+func (c *fakePoolConn) Search(ctx context.Context, searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.broken {
+		return nil, ldap.NewError(ldap.ErrorNetwork, errors.New("ldap: connection closed"))
+	}
+	return &ldap.SearchResult{}, nil
+}
+
+func (c *fakePoolConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return nil
+}
+
+func (c *fakePoolConn) Unbind() error {
+	return c.Close()
+}
+
+// fakeDialer registra as conexões abertas pelo pool
+type fakeDialer struct {
+	mu    sync.Mutex
+	conns []*fakePoolConn
+	err   error
+}
+
+func (d *fakeDialer) dial(ctx context.Context) (ILDAPConnection, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.err != nil {
+		return nil, d.err
+	}
+	conn := &fakePoolConn{}
+	d.conns = append(d.conns, conn)
+	return conn, nil
+}
+
+func (d *fakeDialer) count() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.conns)
+}
+
+func newTestPoolConfig() *configs.ADConfig {
+	return &configs.ADConfig{
+		PoolMinSize:        1,
+		PoolMaxSize:        2,
+		PoolAcquireTimeout: 50 * time.Millisecond,
+	}
+}
+
+func TestLDAPPool_OpensMinimumConnections(t *testing.T) {
+	dialer := &fakeDialer{}
+	config := newTestPoolConfig()
+	config.PoolMinSize = 2
+
+	pool, err := NewLDAPPool(config, dialer.dial)
+	assert.NoError(t, err)
+	defer pool.Close()
+
+	assert.Equal(t, 2, dialer.count())
+}
+
+func TestLDAPPool_DialError(t *testing.T) {
+	dialer := &fakeDialer{err: errors.New("servidor indisponível")}
+
+	_, err := NewLDAPPool(newTestPoolConfig(), dialer.dial)
+	assert.Error(t, err)
+}
+
+func TestLDAPPool_ReusesConnections(t *testing.T) {
+	dialer := &fakeDialer{}
+	pool, err := NewLDAPPool(newTestPoolConfig(), dialer.dial)
+	assert.NoError(t, err)
+	defer pool.Close()
+
+	for i := 0; i < 3; i++ {
+		_, err := pool.Search(context.Background(), &ldap.SearchRequest{})
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 1, dialer.count())
+}
+
+func TestLDAPPool_RedialsBrokenConnection(t *testing.T) {
+	dialer := &fakeDialer{}
+	pool, err := NewLDAPPool(newTestPoolConfig(), dialer.dial)
+	assert.NoError(t, err)
+	defer pool.Close()
+
+	dialer.conns[0].setBroken(true)
+
+	_, err = pool.Search(context.Background(), &ldap.SearchRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, dialer.count())
+	assert.True(t, dialer.conns[0].isClosed())
+}
+
+func TestLDAPPool_RebindsWithCurrentIdentity(t *testing.T) {
+	dialer := &fakeDialer{}
+	pool, err := NewLDAPPool(newTestPoolConfig(), dialer.dial)
+	assert.NoError(t, err)
+	defer pool.Close()
+
+	assert.NoError(t, pool.Bind(context.Background(), "svc@domain.com", "secret"))
+
+	dialer.conns[0].setBroken(true)
+	_, err = pool.Search(context.Background(), &ldap.SearchRequest{})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"svc@domain.com"}, dialer.conns[1].binds)
+}
+
+func TestLDAPPool_BindsServiceAccount(t *testing.T) {
+	dialer := &fakeDialer{}
+	config := newTestPoolConfig()
+	config.Username = "svc"
+	config.Password = "secret"
+	config.Domain = "domain.com"
+
+	pool, err := NewLDAPPool(config, dialer.dial)
+	assert.NoError(t, err)
+	defer pool.Close()
+
+	_, err = pool.Search(context.Background(), &ldap.SearchRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"svc@domain.com"}, dialer.conns[0].binds)
+}
+
+func TestLDAPPool_FailedBindKeepsIdentity(t *testing.T) {
+	dialer := &fakeDialer{}
+	pool, err := NewLDAPPool(newTestPoolConfig(), dialer.dial)
+	assert.NoError(t, err)
+	defer pool.Close()
+
+	assert.NoError(t, pool.Bind(context.Background(), "svc@domain.com", "secret"))
+
+	dialer.conns[0].setBindErr(ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("invalid")))
+	assert.Error(t, pool.Bind(context.Background(), "user@domain.com", "wrong"))
+
+	dialer.conns[0].setBindErr(nil)
+	_, err = pool.Search(context.Background(), &ldap.SearchRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"svc@domain.com", "user@domain.com", "svc@domain.com"}, dialer.conns[0].binds)
+}
+
+func TestLDAPPool_AcquireTimeout(t *testing.T) {
+	dialer := &fakeDialer{}
+	config := newTestPoolConfig()
+	config.PoolMaxSize = 1
+
+	pool, err := NewLDAPPool(config, dialer.dial)
+	assert.NoError(t, err)
+	defer pool.Close()
+
+	pc, err := pool.acquire(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, PoolStats{Open: 1, Idle: 0, InUse: 1, Max: 1}, pool.Stats())
+
+	_, err = pool.acquire(context.Background())
+	assert.ErrorIs(t, err, ErrPoolTimeout)
+
+	pool.release(pc)
+	assert.Equal(t, PoolStats{Open: 1, Idle: 1, InUse: 0, Max: 1}, pool.Stats())
+
+	pc, err = pool.acquire(context.Background())
+	assert.NoError(t, err)
+	pool.release(pc)
+}
+
+func TestLDAPPool_AcquireCanceledByContext(t *testing.T) {
+	dialer := &fakeDialer{}
+	config := newTestPoolConfig()
+	config.PoolMaxSize = 1
+	config.PoolAcquireTimeout = time.Minute
+
+	pool, err := NewLDAPPool(config, dialer.dial)
+	assert.NoError(t, err)
+	defer pool.Close()
+
+	pc, err := pool.acquire(context.Background())
+	assert.NoError(t, err)
+	defer pool.release(pc)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err = pool.Search(ctx, &ldap.SearchRequest{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestLDAPPool_DiscardsIdleAndUnhealthyConnections(t *testing.T) {
+	dialer := &fakeDialer{}
+	config := newTestPoolConfig()
+	config.PoolIdleTimeout = time.Hour
+	config.PoolHealthCheckInterval = time.Millisecond
+
+	pool, err := NewLDAPPool(config, dialer.dial)
+	assert.NoError(t, err)
+	defer pool.Close()
+
+	dialer.conns[0].setBroken(true)
+	time.Sleep(5 * time.Millisecond)
+
+	pc, err := pool.acquire(context.Background())
+	assert.NoError(t, err)
+	assert.Same(t, dialer.conns[1], pc.conn)
+
+	pc.lastUsed = time.Now().Add(-2 * time.Hour)
+	assert.False(t, pool.healthy(pc))
+	pool.release(pc)
+}
+
+func TestLDAPPool_UnbindKeepsConnectionsOpen(t *testing.T) {
+	dialer := &fakeDialer{}
+	pool, err := NewLDAPPool(newTestPoolConfig(), dialer.dial)
+	assert.NoError(t, err)
+
+	assert.NoError(t, pool.Unbind())
+	assert.False(t, dialer.conns[0].isClosed())
+
+	assert.NoError(t, pool.Close())
+	assert.True(t, dialer.conns[0].isClosed())
+
+	_, err = pool.Search(context.Background(), &ldap.SearchRequest{})
+	assert.ErrorIs(t, err, ErrPoolClosed)
+}
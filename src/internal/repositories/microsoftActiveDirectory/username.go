@@ -0,0 +1,39 @@
+package microsoftActiveDirectory
+
+import (
+	"auth-ad/src/internal/models"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// maxUsernameLength é o tamanho máximo de sAMAccountName definido no schema do AD
+const maxUsernameLength = 256
+
+// invalidUsernameChars são os caracteres não permitidos em sAMAccountName
+const invalidUsernameChars = "\"/\\[]:;|=,+*?<>"
+
+// ValidateUsername rejeita nomes de usuário que não podem ser um sAMAccountName válido,
+// antes que cheguem ao AD
+// Params:
+//   - username: Nome do usuário
+//
+// Returns:
+//   - error: models.ErrInvalidUsername caso o nome seja inválido
+func ValidateUsername(username string) error {
+	if strings.TrimSpace(username) == "" {
+		return fmt.Errorf("%w: vazio", models.ErrInvalidUsername)
+	}
+
+	if len(username) > maxUsernameLength {
+		return fmt.Errorf("%w: excede %d caracteres", models.ErrInvalidUsername, maxUsernameLength)
+	}
+
+	for _, r := range username {
+		if unicode.IsControl(r) || strings.ContainsRune(invalidUsernameChars, r) {
+			return fmt.Errorf("%w: caractere %q não permitido", models.ErrInvalidUsername, r)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,453 @@
+package natsGateway
+
+import (
+	"auth-ad/src/internal/models"
+	"auth-ad/src/pkg/configs"
+	"auth-ad/src/pkg/logging"
+	"auth-ad/src/pkg/requestContext"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// requestIDHeader é o cabeçalho das respostas publicadas com o ID da requisição respondida
+const requestIDHeader = "Request-Id"
+
+// logger registra os eventos da conexão e das mensagens do NATS
+var logger = logging.Component("natsGateway")
+
+// NatsGateway implementa a interface IApiRepository sobre o NATS JetStream. As requisições são
+// consumidas de um stream por um consumer durável compartilhado pelas instâncias, e cada mensagem
+// entregue é mantida como um lease até ser respondida (ack), devolvida (nak) ou expirar o AckWait
+// do consumer, quando o JetStream a entrega novamente. As respostas são publicadas no JetStream
+// com o ID da requisição como Nats-Msg-Id, para que reenvios sejam descartados como duplicatas.
+type NatsGateway struct {
+	conn     *nats.Conn
+	js       jetstream.JetStream
+	consumer jetstream.Consumer
+	config   *configs.NatsConfig
+	// ackWait é o prazo do consumer para a resposta de uma mensagem entregue, usado como duração dos leases
+	ackWait   time.Duration
+	batchSize int
+	now       func() time.Time
+
+	mu sync.Mutex
+	// held guarda as mensagens entregues e ainda não respondidas, por ID do lease
+	held map[string]*heldMsg
+	// byRequest liga o ID de cada requisição ao lease de sua entrega mais recente
+	byRequest map[string]string
+}
+
+// heldMsg é uma mensagem entregue a esta instância, com o lease correspondente
+type heldMsg struct {
+	msg   jetstream.Msg
+	lease models.Lease
+}
+
+// NewNatsGateway conecta ao NATS e cria ou atualiza o consumer durável das requisições. O stream
+// deve existir; o AckWait do consumer é a duração dos leases (API_LEASE_TTL).
+// Parâmetros:
+//   - apiConfig: Configurações da API, com a identificação da instância, a duração dos leases e o tamanho das reservas
+//   - config: Configurações do NATS
+//
+// Retorna:
+//   - *NatsGateway: Gateway conectado, encerrado por Close
+//   - error: Erro em caso de falha na conexão ou na criação do consumer
+func NewNatsGateway(apiConfig *configs.ApiConfig, config *configs.NatsConfig) (*NatsGateway, error) {
+	options := []nats.Option{
+		nats.Name("auth-ad " + apiConfig.InstanceID),
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				logger.Warn("Conexão com o NATS perdida", logging.AttrError, err)
+			}
+		}),
+		nats.ReconnectHandler(func(conn *nats.Conn) {
+			logger.Info("Conexão com o NATS restabelecida", "url", conn.ConnectedUrlRedacted())
+		}),
+	}
+	if config.CredsFile != "" {
+		options = append(options, nats.UserCredentials(config.CredsFile))
+	}
+	if config.Token != "" {
+		options = append(options, nats.Token(config.Token))
+	}
+
+	conn, err := nats.Connect(config.Url, options...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao conectar ao NATS: %w", err)
+	}
+
+	gateway, err := newNatsGateway(conn, apiConfig, config)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return gateway, nil
+}
+
+// newNatsGateway cria o gateway sobre uma conexão já estabelecida
+func newNatsGateway(conn *nats.Conn, apiConfig *configs.ApiConfig, config *configs.NatsConfig) (*NatsGateway, error) {
+	js, err := jetstream.New(conn)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao acessar o JetStream: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), apiConfig.Timeout)
+	defer cancel()
+
+	consumer, err := js.CreateOrUpdateConsumer(ctx, config.Stream, jetstream.ConsumerConfig{
+		Durable:       config.Consumer,
+		FilterSubject: config.RequestSubject,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       apiConfig.LeaseTTL,
+		MaxDeliver:    config.MaxDeliver,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar o consumer %s no stream %s: %w", config.Consumer, config.Stream, err)
+	}
+
+	return &NatsGateway{
+		conn:      conn,
+		js:        js,
+		consumer:  consumer,
+		config:    config,
+		ackWait:   apiConfig.LeaseTTL,
+		batchSize: apiConfig.ClaimBatchSize,
+		now:       time.Now,
+		held:      make(map[string]*heldMsg),
+		byRequest: make(map[string]string),
+	}, nil
+}
+
+// GetRequest consome as requisições de autenticação disponíveis. As mensagens ficam pendentes
+// até que SendResponse publique a resposta da requisição.
+// Parâmetros:
+//   - ctx: Contexto da chamada
+//
+// Retorna:
+//   - []models.AuthRequest: Lista de requisições de autenticação
+//   - error: Erro em caso de falha no consumo
+func (g *NatsGateway) GetRequest(ctx context.Context) ([]models.AuthRequest, error) {
+	claimed, err := g.Claim(ctx, g.batchSize, g.ackWait)
+	if err != nil {
+		return nil, err
+	}
+
+	requests := make([]models.AuthRequest, 0, len(claimed))
+	for _, claim := range claimed {
+		requests = append(requests, claim.Request)
+	}
+
+	return requests, nil
+}
+
+// SendResponse publica a resposta de uma requisição e confirma a mensagem pendente da requisição,
+// caso exista. É usado também no reenvio de respostas guardadas no spool.
+// Parâmetros:
+//   - ctx: Contexto da chamada
+//   - requestId: ID da requisição respondida
+//   - response: Dados da resposta de autenticação
+//
+// Retorna:
+//   - error: Erro em caso de falha na publicação
+func (g *NatsGateway) SendResponse(ctx context.Context, requestId string, response models.AuthResponse) error {
+	if err := g.publish(ctx, requestId, response); err != nil {
+		return err
+	}
+
+	if held, ok := g.takeRequest(requestId); ok {
+		g.ack(ctx, held)
+	}
+
+	return nil
+}
+
+// Claim consome até max requisições, cada uma com um lease que dura o AckWait do consumer. Caso
+// não haja requisições disponíveis, aguarda a chegada de uma por até NatsConfig.FetchWait.
+// Mensagens que não são requisições válidas são descartadas (term) sem nova entrega.
+// Parâmetros:
+//   - ctx: Contexto da chamada
+//   - max: Quantidade máxima de requisições reservadas
+//   - ttl: Ignorado; a duração dos leases é o AckWait do consumer
+//
+// Retorna:
+//   - []models.ClaimedRequest: Requisições reservadas
+//   - error: Erro em caso de falha no consumo
+func (g *NatsGateway) Claim(ctx context.Context, max int, ttl time.Duration) ([]models.ClaimedRequest, error) {
+	msgs, err := g.fetch(ctx, max)
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make([]models.ClaimedRequest, 0, len(msgs))
+	for _, msg := range msgs {
+		request, lease, err := g.hold(msg)
+		if err != nil {
+			logger.WarnContext(ctx, "Mensagem inválida descartada", "subject", msg.Subject(), logging.AttrError, err)
+			if err := msg.Term(); err != nil {
+				logger.ErrorContext(ctx, "Erro ao descartar a mensagem", "subject", msg.Subject(), logging.AttrError, err)
+			}
+			continue
+		}
+
+		claimed = append(claimed, models.ClaimedRequest{Request: request, Lease: lease})
+	}
+
+	return claimed, nil
+}
+
+// Heartbeat renova um lease, reiniciando o AckWait da mensagem (in progress)
+// Parâmetros:
+//   - ctx: Contexto da chamada
+//   - lease: Lease a ser renovado
+//   - ttl: Ignorado; a duração dos leases é o AckWait do consumer
+//
+// Retorna:
+//   - models.Lease: Lease renovado
+//   - error: models.ErrLeaseLost caso o lease tenha expirado, ou erro em caso de falha na renovação
+func (g *NatsGateway) Heartbeat(ctx context.Context, lease models.Lease, ttl time.Duration) (models.Lease, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	held, ok := g.held[lease.ID]
+	if !ok || g.expired(held) {
+		g.forget(lease.ID)
+		return models.Lease{}, models.ErrLeaseLost
+	}
+
+	if err := held.msg.InProgress(); err != nil {
+		return models.Lease{}, fmt.Errorf("erro ao renovar o lease da requisição %s: %w", lease.RequestID, err)
+	}
+	held.lease.ExpiresAt = g.now().Add(g.ackWait)
+
+	return held.lease, nil
+}
+
+// Release devolve uma requisição sem respondê-la (nak), para que seja entregue novamente
+// Parâmetros:
+//   - ctx: Contexto da chamada
+//   - lease: Lease a ser liberado
+//
+// Retorna:
+//   - error: Erro em caso de falha na devolução
+func (g *NatsGateway) Release(ctx context.Context, lease models.Lease) error {
+	held, ok := g.take(lease.ID)
+	if !ok {
+		return nil
+	}
+
+	if err := held.msg.Nak(); err != nil {
+		return fmt.Errorf("erro ao devolver a requisição %s: %w", lease.RequestID, err)
+	}
+
+	return nil
+}
+
+// Ack publica a resposta da requisição reservada e confirma a mensagem. Com o lease expirado, a
+// mensagem já foi ou será entregue novamente, e a resposta não é publicada.
+// Parâmetros:
+//   - ctx: Contexto da chamada
+//   - lease: Lease da requisição
+//   - response: Dados da resposta de autenticação
+//
+// Retorna:
+//   - error: models.ErrLeaseLost caso o lease tenha expirado, ou erro em caso de falha na publicação
+func (g *NatsGateway) Ack(ctx context.Context, lease models.Lease, response models.AuthResponse) error {
+	held, ok := g.take(lease.ID)
+	if !ok || g.expired(held) {
+		return models.ErrLeaseLost
+	}
+
+	if err := g.publish(ctx, lease.RequestID, response); err != nil {
+		return err
+	}
+	g.ack(ctx, held)
+
+	return nil
+}
+
+// Ping verifica se a conexão com o NATS está ativa e se o consumer das requisições está acessível
+// Parâmetros:
+//   - ctx: Contexto da consulta ao consumer
+//
+// Retorna:
+//   - error: Erro em caso de conexão perdida ou consumer inacessível
+func (g *NatsGateway) Ping(ctx context.Context) error {
+	if status := g.conn.Status(); status != nats.CONNECTED {
+		return fmt.Errorf("conexão com o NATS inativa: %s", status)
+	}
+
+	if _, err := g.consumer.Info(ctx); err != nil {
+		return fmt.Errorf("erro ao consultar o consumer: %w", err)
+	}
+
+	return nil
+}
+
+// Close encerra a conexão com o NATS, aguardando o envio das confirmações pendentes
+// Retorna:
+//   - error: Erro em caso de falha no encerramento
+func (g *NatsGateway) Close() error {
+	return g.conn.Drain()
+}
+
+// fetch consome até max mensagens. As mensagens já disponíveis são entregues imediatamente; sem
+// nenhuma disponível, aguarda a primeira por até NatsConfig.FetchWait.
+func (g *NatsGateway) fetch(ctx context.Context, max int) ([]jetstream.Msg, error) {
+	batch, err := g.consumer.FetchNoWait(max)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consumir requisições: %w", err)
+	}
+
+	msgs, err := collect(batch)
+	if err != nil || len(msgs) > 0 {
+		return msgs, err
+	}
+
+	wait := g.config.FetchWait
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+		wait = time.Until(deadline)
+	}
+	if wait <= 0 {
+		return nil, ctx.Err()
+	}
+
+	batch, err = g.consumer.Fetch(1, jetstream.FetchMaxWait(wait))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consumir requisições: %w", err)
+	}
+
+	return collect(batch)
+}
+
+// collect lê todas as mensagens de um lote
+func collect(batch jetstream.MessageBatch) ([]jetstream.Msg, error) {
+	msgs := make([]jetstream.Msg, 0)
+	for msg := range batch.Messages() {
+		msgs = append(msgs, msg)
+	}
+
+	if err := batch.Error(); err != nil {
+		return msgs, fmt.Errorf("erro ao consumir requisições: %w", err)
+	}
+
+	return msgs, nil
+}
+
+// hold decodifica uma mensagem entregue e registra seu lease. O ID do lease identifica a entrega:
+// a sequência da mensagem no stream e o número da entrega.
+// Retorna: a requisição, o lease e um erro caso a mensagem não seja uma requisição válida
+func (g *NatsGateway) hold(msg jetstream.Msg) (models.AuthRequest, models.Lease, error) {
+	var request models.AuthRequest
+	if err := json.Unmarshal(msg.Data(), &request); err != nil {
+		return models.AuthRequest{}, models.Lease{}, err
+	}
+	if request.RequestID == "" {
+		return models.AuthRequest{}, models.Lease{}, fmt.Errorf("request_id ausente")
+	}
+
+	metadata, err := msg.Metadata()
+	if err != nil {
+		return models.AuthRequest{}, models.Lease{}, err
+	}
+
+	lease := models.Lease{
+		ID:        fmt.Sprintf("%d.%d", metadata.Sequence.Stream, metadata.NumDelivered),
+		RequestID: request.RequestID,
+		ExpiresAt: g.now().Add(g.ackWait),
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// Uma entrega anterior da mesma requisição expirou e é substituída
+	if previous, ok := g.byRequest[request.RequestID]; ok {
+		delete(g.held, previous)
+	}
+	g.held[lease.ID] = &heldMsg{msg: msg, lease: lease}
+	g.byRequest[request.RequestID] = lease.ID
+
+	return request, lease, nil
+}
+
+// take remove uma mensagem pendente pelo ID do lease
+func (g *NatsGateway) take(leaseID string) (*heldMsg, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	held, ok := g.held[leaseID]
+	if ok {
+		g.forget(leaseID)
+	}
+
+	return held, ok
+}
+
+// takeRequest remove a mensagem pendente de uma requisição
+func (g *NatsGateway) takeRequest(requestID string) (*heldMsg, bool) {
+	g.mu.Lock()
+	leaseID, ok := g.byRequest[requestID]
+	g.mu.Unlock()
+
+	if !ok {
+		return nil, false
+	}
+
+	return g.take(leaseID)
+}
+
+// forget remove o registro de um lease; deve ser chamado com g.mu travado
+func (g *NatsGateway) forget(leaseID string) {
+	held, ok := g.held[leaseID]
+	if !ok {
+		return
+	}
+
+	delete(g.held, leaseID)
+	if g.byRequest[held.lease.RequestID] == leaseID {
+		delete(g.byRequest, held.lease.RequestID)
+	}
+}
+
+// expired indica se o AckWait da mensagem terminou sem renovação
+func (g *NatsGateway) expired(held *heldMsg) bool {
+	return !g.now().Before(held.lease.ExpiresAt)
+}
+
+// publish publica a resposta de uma requisição no JetStream, com o ID da requisição como
+// Nats-Msg-Id para que o stream descarte respostas repetidas e o contexto de trace nos cabeçalhos
+// W3C traceparent e tracestate
+func (g *NatsGateway) publish(ctx context.Context, requestID string, response models.AuthResponse) error {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(g.config.ResponseSubject)
+	msg.Header.Set(requestIDHeader, requestID)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(msg.Header))
+	msg.Data = data
+
+	if _, err := g.js.PublishMsg(ctx, msg, jetstream.WithMsgID(requestID)); err != nil {
+		return fmt.Errorf("erro ao publicar a resposta da requisição %s: %w", requestID, err)
+	}
+
+	return nil
+}
+
+// ack confirma uma mensagem respondida. A resposta já foi publicada: caso a confirmação falhe, a
+// mensagem é entregue novamente e a nova resposta é descartada como duplicata pelo stream.
+func (g *NatsGateway) ack(ctx context.Context, held *heldMsg) {
+	if err := held.msg.DoubleAck(ctx); err != nil {
+		logger.WarnContext(requestContext.WithRequestID(ctx, held.lease.RequestID), "Erro ao confirmar a mensagem", logging.AttrError, err)
+	}
+}
@@ -0,0 +1,319 @@
+package natsGateway
+
+import (
+	"auth-ad/src/internal/models"
+	"auth-ad/src/pkg/configs"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// testEnv é um servidor NATS embutido com os streams de requisições e de respostas
+type testEnv struct {
+	gateway *NatsGateway
+	js      jetstream.JetStream
+	conn    *nats.Conn
+}
+
+// newTestEnv inicia um servidor NATS com JetStream em memória e cria o gateway sobre ele
+func newTestEnv(t *testing.T, ackWait time.Duration) *testEnv {
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	if err != nil {
+		t.Fatalf("Erro ao criar o servidor NATS: %v", err)
+	}
+	srv.Start()
+	if !srv.ReadyForConnections(5 * time.Second) {
+		t.Fatal("Servidor NATS não iniciou")
+	}
+	t.Cleanup(srv.Shutdown)
+
+	conn, err := nats.Connect(srv.ClientURL())
+	if err != nil {
+		t.Fatalf("Erro ao conectar ao NATS: %v", err)
+	}
+	t.Cleanup(conn.Close)
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		t.Fatalf("Erro ao acessar o JetStream: %v", err)
+	}
+
+	ctx := context.Background()
+	for name, subject := range map[string]string{"AUTH_REQUESTS": "auth.requests", "AUTH_RESPONSES": "auth.responses"} {
+		if _, err := js.CreateStream(ctx, jetstream.StreamConfig{Name: name, Subjects: []string{subject}, Storage: jetstream.MemoryStorage}); err != nil {
+			t.Fatalf("Erro ao criar o stream %s: %v", name, err)
+		}
+	}
+
+	gateway, err := NewNatsGateway(&configs.ApiConfig{
+		InstanceID:     "test",
+		Timeout:        5 * time.Second,
+		LeaseTTL:       ackWait,
+		ClaimBatchSize: 10,
+	}, &configs.NatsConfig{
+		Url:             srv.ClientURL(),
+		Stream:          "AUTH_REQUESTS",
+		Consumer:        "auth-ad",
+		RequestSubject:  "auth.requests",
+		ResponseSubject: "auth.responses",
+		MaxDeliver:      -1,
+		FetchWait:       200 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Erro ao criar o gateway: %v", err)
+	}
+	t.Cleanup(func() { gateway.Close() })
+
+	return &testEnv{gateway: gateway, js: js, conn: conn}
+}
+
+// publishRequest publica uma requisição de autenticação no stream de requisições
+func (e *testEnv) publishRequest(t *testing.T, request models.AuthRequest) {
+	data, _ := json.Marshal(request)
+	if _, err := e.js.Publish(context.Background(), "auth.requests", data); err != nil {
+		t.Fatalf("Erro ao publicar a requisição: %v", err)
+	}
+}
+
+// responses retorna as respostas publicadas no stream de respostas
+func (e *testEnv) responses(t *testing.T) []models.AuthResponse {
+	stream, err := e.js.Stream(context.Background(), "AUTH_RESPONSES")
+	if err != nil {
+		t.Fatalf("Erro ao acessar o stream de respostas: %v", err)
+	}
+	info, err := stream.Info(context.Background())
+	if err != nil {
+		t.Fatalf("Erro ao consultar o stream de respostas: %v", err)
+	}
+
+	responses := make([]models.AuthResponse, 0)
+	for seq := info.State.FirstSeq; seq > 0 && seq <= info.State.LastSeq; seq++ {
+		msg, err := stream.GetMsg(context.Background(), seq)
+		if err != nil {
+			t.Fatalf("Erro ao ler a resposta %d: %v", seq, err)
+		}
+		if msg.Header.Get(requestIDHeader) == "" {
+			t.Errorf("Resposta %d sem o cabeçalho %s", seq, requestIDHeader)
+		}
+
+		var response models.AuthResponse
+		json.Unmarshal(msg.Data, &response)
+		responses = append(responses, response)
+	}
+
+	return responses
+}
+
+// claim reserva as requisições disponíveis, falhando o teste em caso de erro
+func (e *testEnv) claim(t *testing.T) []models.ClaimedRequest {
+	claimed, err := e.gateway.Claim(context.Background(), 10, 0)
+	if err != nil {
+		t.Fatalf("Erro inesperado no Claim: %v", err)
+	}
+	return claimed
+}
+
+func TestClaimAndAck(t *testing.T) {
+	env := newTestEnv(t, 5*time.Second)
+	env.publishRequest(t, models.AuthRequest{RequestID: "req-1", Username: "user", Password: "pass"})
+
+	claimed := env.claim(t)
+	if len(claimed) != 1 {
+		t.Fatalf("Esperada 1 requisição, recebidas %d", len(claimed))
+	}
+	if claimed[0].Request.Username != "user" || claimed[0].Lease.RequestID != "req-1" || claimed[0].Lease.ID == "" {
+		t.Errorf("Requisição reservada incorreta: %+v", claimed[0])
+	}
+
+	response := models.AuthResponse{RequestID: "req-1", Success: true}
+	if err := env.gateway.Ack(context.Background(), claimed[0].Lease, response); err != nil {
+		t.Fatalf("Erro inesperado no Ack: %v", err)
+	}
+
+	responses := env.responses(t)
+	if len(responses) != 1 || responses[0].RequestID != "req-1" || !responses[0].Success {
+		t.Errorf("Respostas publicadas incorretas: %+v", responses)
+	}
+
+	// A mensagem confirmada não é entregue novamente
+	if claimed := env.claim(t); len(claimed) != 0 {
+		t.Errorf("Esperada nenhuma requisição após o Ack, recebidas %d", len(claimed))
+	}
+
+	// O lease confirmado não pode ser usado novamente
+	if err := env.gateway.Ack(context.Background(), claimed[0].Lease, response); !errors.Is(err, models.ErrLeaseLost) {
+		t.Errorf("Esperado ErrLeaseLost, recebido %v", err)
+	}
+}
+
+func TestGetRequestAndSendResponse(t *testing.T) {
+	env := newTestEnv(t, 5*time.Second)
+	env.publishRequest(t, models.AuthRequest{RequestID: "req-1", Username: "user", Password: "pass"})
+	env.publishRequest(t, models.AuthRequest{RequestID: "req-2", Username: "other", Password: "pass"})
+
+	requests, err := env.gateway.GetRequest(context.Background())
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if len(requests) != 2 || requests[0].RequestID != "req-1" || requests[1].RequestID != "req-2" {
+		t.Fatalf("Requisições incorretas: %+v", requests)
+	}
+
+	for _, request := range requests {
+		if err := env.gateway.SendResponse(context.Background(), request.RequestID, models.AuthResponse{RequestID: request.RequestID}); err != nil {
+			t.Fatalf("Erro inesperado no SendResponse: %v", err)
+		}
+	}
+
+	// O reenvio de uma resposta, como no spool, é descartado como duplicata pelo stream
+	if err := env.gateway.SendResponse(context.Background(), "req-1", models.AuthResponse{RequestID: "req-1"}); err != nil {
+		t.Fatalf("Erro inesperado no reenvio: %v", err)
+	}
+
+	if responses := env.responses(t); len(responses) != 2 {
+		t.Errorf("Esperadas 2 respostas, recebidas %d", len(responses))
+	}
+	if claimed := env.claim(t); len(claimed) != 0 {
+		t.Errorf("Esperada nenhuma requisição após as respostas, recebidas %d", len(claimed))
+	}
+}
+
+func TestRelease(t *testing.T) {
+	env := newTestEnv(t, 5*time.Second)
+	env.publishRequest(t, models.AuthRequest{RequestID: "req-1", Username: "user", Password: "pass"})
+
+	first := env.claim(t)
+	if len(first) != 1 {
+		t.Fatalf("Esperada 1 requisição, recebidas %d", len(first))
+	}
+	if err := env.gateway.Release(context.Background(), first[0].Lease); err != nil {
+		t.Fatalf("Erro inesperado no Release: %v", err)
+	}
+
+	// A requisição devolvida é entregue novamente, com um novo lease
+	second := env.claim(t)
+	if len(second) != 1 || second[0].Request.RequestID != "req-1" {
+		t.Fatalf("Esperada a nova entrega de req-1, recebido %+v", second)
+	}
+	if second[0].Lease.ID == first[0].Lease.ID {
+		t.Errorf("Esperado um novo lease, recebido %s", second[0].Lease.ID)
+	}
+
+	// O lease da entrega anterior não é mais válido
+	if err := env.gateway.Ack(context.Background(), first[0].Lease, models.AuthResponse{RequestID: "req-1"}); !errors.Is(err, models.ErrLeaseLost) {
+		t.Errorf("Esperado ErrLeaseLost, recebido %v", err)
+	}
+	if responses := env.responses(t); len(responses) != 0 {
+		t.Errorf("Esperada nenhuma resposta, recebidas %d", len(responses))
+	}
+
+	// Liberar um lease desconhecido não é um erro
+	if err := env.gateway.Release(context.Background(), first[0].Lease); err != nil {
+		t.Errorf("Erro inesperado: %v", err)
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	env := newTestEnv(t, time.Second)
+	now := time.Now()
+	env.gateway.now = func() time.Time { return now }
+	env.publishRequest(t, models.AuthRequest{RequestID: "req-1", Username: "user", Password: "pass"})
+
+	claimed := env.claim(t)
+	if len(claimed) != 1 {
+		t.Fatalf("Esperada 1 requisição, recebidas %d", len(claimed))
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	lease, err := env.gateway.Heartbeat(context.Background(), claimed[0].Lease, 0)
+	if err != nil {
+		t.Fatalf("Erro inesperado no Heartbeat: %v", err)
+	}
+	if !lease.ExpiresAt.Equal(now.Add(time.Second)) {
+		t.Errorf("Expiração incorreta: %v", lease.ExpiresAt)
+	}
+
+	// Sem renovação, o lease expira e a resposta não é publicada
+	now = now.Add(time.Second)
+	if _, err := env.gateway.Heartbeat(context.Background(), lease, 0); !errors.Is(err, models.ErrLeaseLost) {
+		t.Errorf("Esperado ErrLeaseLost no Heartbeat, recebido %v", err)
+	}
+	if err := env.gateway.Ack(context.Background(), lease, models.AuthResponse{RequestID: "req-1"}); !errors.Is(err, models.ErrLeaseLost) {
+		t.Errorf("Esperado ErrLeaseLost no Ack, recebido %v", err)
+	}
+}
+
+func TestInvalidMessageIsTerminated(t *testing.T) {
+	env := newTestEnv(t, 5*time.Second)
+	if _, err := env.js.Publish(context.Background(), "auth.requests", []byte("não é json")); err != nil {
+		t.Fatalf("Erro ao publicar: %v", err)
+	}
+	env.publishRequest(t, models.AuthRequest{Username: "user", Password: "pass"})
+	env.publishRequest(t, models.AuthRequest{RequestID: "req-1", Username: "user", Password: "pass"})
+
+	claimed := env.claim(t)
+	if len(claimed) != 1 || claimed[0].Request.RequestID != "req-1" {
+		t.Fatalf("Esperada apenas req-1, recebido %+v", claimed)
+	}
+
+	// As mensagens inválidas não são entregues novamente
+	consumer, err := env.js.Consumer(context.Background(), "AUTH_REQUESTS", "auth-ad")
+	if err != nil {
+		t.Fatalf("Erro ao acessar o consumer: %v", err)
+	}
+	info, err := consumer.Info(context.Background())
+	if err != nil {
+		t.Fatalf("Erro ao consultar o consumer: %v", err)
+	}
+	if info.NumAckPending != 1 || info.NumPending != 0 {
+		t.Errorf("Esperada apenas req-1 pendente, pendentes %d e %d", info.NumAckPending, info.NumPending)
+	}
+}
+
+func TestMissingStream(t *testing.T) {
+	env := newTestEnv(t, 5*time.Second)
+
+	_, err := newNatsGateway(env.conn, &configs.ApiConfig{Timeout: time.Second, LeaseTTL: time.Second}, &configs.NatsConfig{
+		Stream:         "MISSING",
+		Consumer:       "auth-ad",
+		RequestSubject: "auth.requests",
+		MaxDeliver:     -1,
+	})
+	if !errors.Is(err, jetstream.ErrStreamNotFound) {
+		t.Errorf("Esperado ErrStreamNotFound, recebido %v", err)
+	}
+}
+
+func TestPing(t *testing.T) {
+	env := newTestEnv(t, 5*time.Second)
+
+	if err := env.gateway.Ping(context.Background()); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	// Sem o consumer, as requisições não podem ser consumidas
+	if err := env.js.DeleteConsumer(context.Background(), "AUTH_REQUESTS", "auth-ad"); err != nil {
+		t.Fatalf("Erro ao remover o consumer: %v", err)
+	}
+	if err := env.gateway.Ping(context.Background()); err == nil {
+		t.Error("Esperado erro sem o consumer")
+	}
+
+	env.gateway.Close()
+	if err := env.gateway.Ping(context.Background()); err == nil {
+		t.Error("Esperado erro com a conexão encerrada")
+	}
+}
@@ -0,0 +1,246 @@
+package smarketAPIGateway
+
+import (
+	"auth-ad/src/pkg/configs"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuthStrategy define como o gateway se autentica nas chamadas à API
+type AuthStrategy interface {
+	// Authorize adiciona as credenciais à requisição
+	Authorize(ctx context.Context, request *http.Request) error
+	// Invalidate descarta as credenciais atuais após a API rejeitá-las com 401
+	Invalidate()
+}
+
+// NewAuthStrategy cria a estratégia de autenticação configurada
+// Parâmetros:
+//   - config: Configurações da API
+//   - httpClient: Cliente HTTP usado para obter tokens OAuth2
+//
+// Retorna:
+//   - AuthStrategy: Estratégia de autenticação
+//   - error: Erro em caso de configuração incompleta
+func NewAuthStrategy(config *configs.ApiConfig, httpClient *http.Client) (AuthStrategy, error) {
+	switch config.AuthMode {
+	case "", configs.ApiAuthToken:
+		if config.TokenFile != "" {
+			return NewFileTokenAuth(config.TokenFile), nil
+		}
+		if config.Token == "" {
+			return nil, fmt.Errorf("token da API não configurado: defina API_TOKEN ou API_TOKEN_FILE")
+		}
+		return NewStaticTokenAuth(config.Token), nil
+
+	case configs.ApiAuthOAuth2:
+		if config.OAuthTokenUrl == "" || config.OAuthClientID == "" || config.OAuthClientSecret == "" {
+			return nil, fmt.Errorf("OAuth2 incompleto: defina API_OAUTH_TOKEN_URL, API_OAUTH_CLIENT_ID e API_OAUTH_CLIENT_SECRET")
+		}
+		return NewClientCredentialsAuth(config, httpClient), nil
+	}
+
+	return nil, fmt.Errorf("estratégia de autenticação da API inválida: %s", config.AuthMode)
+}
+
+// StaticTokenAuth envia sempre o mesmo bearer token
+type StaticTokenAuth struct {
+	token string
+}
+
+// NewStaticTokenAuth cria uma estratégia com bearer token fixo
+// Parâmetros:
+//   - token: Token de autenticação para a API
+//
+// Retorna:
+//   - *StaticTokenAuth: Estratégia de autenticação
+func NewStaticTokenAuth(token string) *StaticTokenAuth {
+	return &StaticTokenAuth{token: token}
+}
+
+// Authorize adiciona o bearer token à requisição
+func (a *StaticTokenAuth) Authorize(ctx context.Context, request *http.Request) error {
+	setBearer(request, a.token)
+	return nil
+}
+
+// Invalidate não tem efeito, pois o token fixo não pode ser renovado
+func (a *StaticTokenAuth) Invalidate() {}
+
+// FileTokenAuth lê o bearer token de um arquivo, permitindo a rotação do token sem reiniciar o
+// serviço. O arquivo é lido no primeiro uso e novamente sempre que a API rejeita o token.
+type FileTokenAuth struct {
+	path string
+
+	mu    sync.Mutex
+	token string
+}
+
+// NewFileTokenAuth cria uma estratégia com bearer token lido de arquivo
+// Parâmetros:
+//   - path: Caminho do arquivo com o token
+//
+// Retorna:
+//   - *FileTokenAuth: Estratégia de autenticação
+func NewFileTokenAuth(path string) *FileTokenAuth {
+	return &FileTokenAuth{path: path}
+}
+
+// Authorize adiciona o token lido do arquivo à requisição
+func (a *FileTokenAuth) Authorize(ctx context.Context, request *http.Request) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token == "" {
+		content, err := os.ReadFile(a.path)
+		if err != nil {
+			return fmt.Errorf("erro ao ler token da API: %w", err)
+		}
+
+		a.token = strings.TrimSpace(string(content))
+		if a.token == "" {
+			return fmt.Errorf("arquivo de token da API vazio: %s", a.path)
+		}
+	}
+
+	setBearer(request, a.token)
+	return nil
+}
+
+// Invalidate força a releitura do arquivo na próxima chamada
+func (a *FileTokenAuth) Invalidate() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.token = ""
+}
+
+// tokenResponse é a resposta do endpoint de tokens OAuth2 (RFC 6749, seção 5.1)
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// ClientCredentialsAuth obtém tokens com o fluxo OAuth2 client credentials. O token fica em
+// cache e é renovado com a antecedência configurada, antes de expirar. A antecedência é limitada
+// à metade da validade do token, para que tokens de curta duração não sejam renovados a cada uso.
+type ClientCredentialsAuth struct {
+	httpClient    *http.Client
+	tokenUrl      string
+	clientID      string
+	clientSecret  string
+	scopes        []string
+	refreshBefore time.Duration
+	now           func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	refreshAt time.Time
+}
+
+// NewClientCredentialsAuth cria uma estratégia OAuth2 client credentials
+// Parâmetros:
+//   - config: Configurações da API com os dados do cliente OAuth2
+//   - httpClient: Cliente HTTP usado para obter os tokens
+//
+// Retorna:
+//   - *ClientCredentialsAuth: Estratégia de autenticação
+func NewClientCredentialsAuth(config *configs.ApiConfig, httpClient *http.Client) *ClientCredentialsAuth {
+	return &ClientCredentialsAuth{
+		httpClient:    httpClient,
+		tokenUrl:      config.OAuthTokenUrl,
+		clientID:      config.OAuthClientID,
+		clientSecret:  config.OAuthClientSecret,
+		scopes:        config.OAuthScopes,
+		refreshBefore: config.OAuthRefreshBefore,
+		now:           time.Now,
+	}
+}
+
+// Authorize adiciona o access token à requisição, obtendo um novo quando não houver token em
+// cache ou quando ele estiver próximo de expirar
+func (a *ClientCredentialsAuth) Authorize(ctx context.Context, request *http.Request) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token == "" || (!a.refreshAt.IsZero() && !a.now().Before(a.refreshAt)) {
+		if err := a.fetch(ctx); err != nil {
+			return err
+		}
+	}
+
+	setBearer(request, a.token)
+	return nil
+}
+
+// Invalidate descarta o token em cache, forçando a obtenção de um novo
+func (a *ClientCredentialsAuth) Invalidate() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.token = ""
+	a.refreshAt = time.Time{}
+}
+
+// fetch obtém um novo access token. O chamador deve deter a trava.
+func (a *ClientCredentialsAuth) fetch(ctx context.Context) error {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(a.scopes) > 0 {
+		form.Set("scope", strings.Join(a.scopes, " "))
+	}
+
+	request, err := http.NewRequestWithContext(ctx, "POST", a.tokenUrl, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	request.SetBasicAuth(url.QueryEscape(a.clientID), url.QueryEscape(a.clientSecret))
+
+	resp, err := a.httpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("erro ao obter token OAuth2: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("erro ao obter token OAuth2: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("erro ao obter token OAuth2: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var token tokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return fmt.Errorf("resposta de token OAuth2 inválida: %w", err)
+	}
+	if token.AccessToken == "" {
+		return fmt.Errorf("resposta de token OAuth2 sem access_token")
+	}
+
+	a.token = token.AccessToken
+	a.refreshAt = time.Time{}
+	if token.ExpiresIn > 0 {
+		lifetime := time.Duration(token.ExpiresIn) * time.Second
+		a.refreshAt = a.now().Add(lifetime - min(a.refreshBefore, lifetime/2))
+	}
+
+	return nil
+}
+
+// setBearer define o cabeçalho Authorization com o bearer token
+func setBearer(request *http.Request, token string) {
+	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+}
@@ -0,0 +1,195 @@
+package smarketAPIGateway
+
+import (
+	"auth-ad/src/pkg/configs"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTokenServer cria um servidor OAuth2 de teste que emite tokens numerados
+func newTokenServer(t *testing.T, expiresIn int, issued *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Verificar grant e credenciais do cliente
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "client_credentials" {
+			t.Errorf("Esperado grant_type client_credentials, recebido %v", r.PostForm)
+		}
+		if r.PostForm.Get("scope") != "auth.read auth.write" {
+			t.Errorf("Escopo incorreto: %s", r.PostForm.Get("scope"))
+		}
+		if id, secret, ok := r.BasicAuth(); !ok || id != "client" || secret != "secret" {
+			t.Error("Credenciais do cliente inválidas")
+		}
+
+		count := atomic.AddInt32(issued, 1)
+		json.NewEncoder(w).Encode(tokenResponse{
+			AccessToken: fmt.Sprintf("token-%d", count),
+			TokenType:   "Bearer",
+			ExpiresIn:   expiresIn,
+		})
+	}))
+}
+
+// newOAuthConfig cria a configuração OAuth2 apontando para o servidor de tokens de teste
+func newOAuthConfig(tokenUrl string) *configs.ApiConfig {
+	return &configs.ApiConfig{
+		AuthMode:           configs.ApiAuthOAuth2,
+		OAuthTokenUrl:      tokenUrl,
+		OAuthClientID:      "client",
+		OAuthClientSecret:  "secret",
+		OAuthScopes:        []string{"auth.read", "auth.write"},
+		OAuthRefreshBefore: time.Minute,
+	}
+}
+
+// authorization retorna o cabeçalho Authorization definido pela estratégia
+func authorization(t *testing.T, auth AuthStrategy) string {
+	request, _ := http.NewRequest("GET", "http://api", nil)
+	if err := auth.Authorize(context.Background(), request); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	return request.Header.Get("Authorization")
+}
+
+func TestClientCredentialsAuth_CachesToken(t *testing.T) {
+	var issued int32
+	server := newTokenServer(t, 3600, &issued)
+	defer server.Close()
+
+	auth := NewClientCredentialsAuth(newOAuthConfig(server.URL), server.Client())
+
+	// O token é reutilizado enquanto não estiver próximo de expirar
+	if got := authorization(t, auth); got != "Bearer token-1" {
+		t.Errorf("Esperado Bearer token-1, recebido %s", got)
+	}
+	if got := authorization(t, auth); got != "Bearer token-1" {
+		t.Errorf("Esperado Bearer token-1, recebido %s", got)
+	}
+	if atomic.LoadInt32(&issued) != 1 {
+		t.Errorf("Esperado 1 token emitido, recebido %d", issued)
+	}
+
+	// Após invalidar, um novo token é obtido
+	auth.Invalidate()
+	if got := authorization(t, auth); got != "Bearer token-2" {
+		t.Errorf("Esperado Bearer token-2, recebido %s", got)
+	}
+}
+
+func TestClientCredentialsAuth_RefreshesBeforeExpiry(t *testing.T) {
+	var issued int32
+	server := newTokenServer(t, 3600, &issued)
+	defer server.Close()
+
+	now := time.Now()
+	auth := NewClientCredentialsAuth(newOAuthConfig(server.URL), server.Client())
+	auth.now = func() time.Time { return now }
+
+	// O token é renovado com a antecedência de 1 minuto
+	authorization(t, auth)
+	now = now.Add(58 * time.Minute)
+	if got := authorization(t, auth); got != "Bearer token-1" {
+		t.Errorf("Esperado Bearer token-1, recebido %s", got)
+	}
+	now = now.Add(time.Minute)
+	if got := authorization(t, auth); got != "Bearer token-2" {
+		t.Errorf("Esperado Bearer token-2, recebido %s", got)
+	}
+}
+
+func TestClientCredentialsAuth_ShortLivedToken(t *testing.T) {
+	var issued int32
+	// O token expira em 30s, menos que a antecedência de renovação de 1 minuto
+	server := newTokenServer(t, 30, &issued)
+	defer server.Close()
+
+	now := time.Now()
+	auth := NewClientCredentialsAuth(newOAuthConfig(server.URL), server.Client())
+	auth.now = func() time.Time { return now }
+
+	// A antecedência é limitada à metade da validade: o token é reutilizado por 15s
+	for range 3 {
+		if got := authorization(t, auth); got != "Bearer token-1" {
+			t.Errorf("Esperado Bearer token-1, recebido %s", got)
+		}
+	}
+	now = now.Add(14 * time.Second)
+	if got := authorization(t, auth); got != "Bearer token-1" {
+		t.Errorf("Esperado Bearer token-1, recebido %s", got)
+	}
+	now = now.Add(time.Second)
+	if got := authorization(t, auth); got != "Bearer token-2" {
+		t.Errorf("Esperado Bearer token-2, recebido %s", got)
+	}
+	if atomic.LoadInt32(&issued) != 2 {
+		t.Errorf("Esperado 2 tokens emitidos, recebido %d", issued)
+	}
+}
+
+func TestClientCredentialsAuth_TokenError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":"invalid_client"}`))
+	}))
+	defer server.Close()
+
+	auth := NewClientCredentialsAuth(newOAuthConfig(server.URL), server.Client())
+
+	request, _ := http.NewRequest("GET", "http://api", nil)
+	if err := auth.Authorize(context.Background(), request); err == nil {
+		t.Fatal("Esperado erro, recebido nil")
+	}
+}
+
+func TestFileTokenAuth_RereadsAfterInvalidate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	os.WriteFile(path, []byte("token-1\n"), 0600)
+
+	auth := NewFileTokenAuth(path)
+	if got := authorization(t, auth); got != "Bearer token-1" {
+		t.Errorf("Esperado Bearer token-1, recebido %s", got)
+	}
+
+	// O token rotacionado só é lido após a API rejeitar o token atual
+	os.WriteFile(path, []byte("token-2"), 0600)
+	if got := authorization(t, auth); got != "Bearer token-1" {
+		t.Errorf("Esperado Bearer token-1, recebido %s", got)
+	}
+
+	auth.Invalidate()
+	if got := authorization(t, auth); got != "Bearer token-2" {
+		t.Errorf("Esperado Bearer token-2, recebido %s", got)
+	}
+}
+
+func TestNewAuthStrategy(t *testing.T) {
+	if _, err := NewAuthStrategy(&configs.ApiConfig{AuthMode: configs.ApiAuthToken}, http.DefaultClient); err == nil {
+		t.Error("Esperado erro sem token configurado")
+	}
+	if _, err := NewAuthStrategy(&configs.ApiConfig{AuthMode: configs.ApiAuthOAuth2}, http.DefaultClient); err == nil {
+		t.Error("Esperado erro com OAuth2 incompleto")
+	}
+
+	auth, err := NewAuthStrategy(&configs.ApiConfig{AuthMode: configs.ApiAuthToken, Token: "abc"}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if _, ok := auth.(*StaticTokenAuth); !ok {
+		t.Errorf("Esperado StaticTokenAuth, recebido %T", auth)
+	}
+
+	auth, err = NewAuthStrategy(&configs.ApiConfig{AuthMode: configs.ApiAuthToken, TokenFile: "/run/secrets/api-token"}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if _, ok := auth.(*FileTokenAuth); !ok {
+		t.Errorf("Esperado FileTokenAuth, recebido %T", auth)
+	}
+}
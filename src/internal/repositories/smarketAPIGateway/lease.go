@@ -0,0 +1,233 @@
+package smarketAPIGateway
+
+import (
+	"auth-ad/src/internal/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// claimRequest é o corpo da reserva de requisições
+type claimRequest struct {
+	InstanceID   string `json:"instance_id"`
+	Max          int    `json:"max"`
+	LeaseSeconds int    `json:"lease_seconds"`
+}
+
+// heartbeatRequest é o corpo da renovação de um lease
+type heartbeatRequest struct {
+	LeaseSeconds int `json:"lease_seconds"`
+}
+
+// Claim reserva até max requisições pendentes com um lease de duração ttl. Caso a API não suporte
+// leases (404, 405 ou 501 na reserva), a consulta simples passa a ser usada e as requisições são
+// retornadas com leases locais, sem ID. Com o long polling ativo, a API pode segurar a reserva
+// por até longPollWait aguardando novas requisições.
+// Parâmetros:
+//   - ctx: Contexto da chamada, usado para cancelamento e prazo
+//   - max: Quantidade máxima de requisições reservadas
+//   - ttl: Duração do lease
+//
+// Retorna:
+//   - []models.ClaimedRequest: Requisições reservadas com seus leases
+//   - error: Erro em caso de falha na reserva
+func (s *SmarketGateway) Claim(ctx context.Context, max int, ttl time.Duration) ([]models.ClaimedRequest, error) {
+	if s.leasesUnsupported.Load() {
+		return s.claimByPolling(ctx)
+	}
+
+	body, err := json.Marshal(claimRequest{InstanceID: s.instanceID, Max: max, LeaseSeconds: leaseSeconds(ttl)})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(ctx, s.claim, "POST", s.pollUrl("/auth/claims"), body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		if !s.leasesUnsupported.Swap(true) {
+			logger.WarnContext(ctx, "A API não suporta leases, usando a consulta simples de requisições", "status", resp.StatusCode)
+		}
+		return s.claimByPolling(ctx)
+	default:
+		return nil, fmt.Errorf("failed to claim requests: %d, body: %s", resp.StatusCode, string(respBody))
+	}
+
+	var claimed []models.ClaimedRequest
+	if err := json.Unmarshal(respBody, &claimed); err != nil {
+		return nil, err
+	}
+
+	return claimed, nil
+}
+
+// Heartbeat renova um lease por mais ttl. Leases locais não precisam de renovação.
+// Parâmetros:
+//   - ctx: Contexto da chamada, usado para cancelamento e prazo
+//   - lease: Lease a ser renovado
+//   - ttl: Nova duração do lease
+//
+// Retorna:
+//   - models.Lease: Lease renovado
+//   - error: models.ErrLeaseLost caso o lease tenha expirado ou sido assumido por outra instância
+func (s *SmarketGateway) Heartbeat(ctx context.Context, lease models.Lease, ttl time.Duration) (models.Lease, error) {
+	if lease.ID == "" {
+		return lease, nil
+	}
+
+	body, err := json.Marshal(heartbeatRequest{LeaseSeconds: leaseSeconds(ttl)})
+	if err != nil {
+		return lease, err
+	}
+
+	resp, err := s.do(ctx, s.renew, "POST", s.leaseUrl(lease, "/heartbeat"), body)
+	if err != nil {
+		return lease, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return lease, err
+	}
+
+	if err := leaseStatusError(resp.StatusCode, "renew lease", respBody); err != nil {
+		return lease, err
+	}
+
+	var renewed models.Lease
+	if err := json.Unmarshal(respBody, &renewed); err != nil {
+		return lease, err
+	}
+	if renewed.ID == "" {
+		renewed.ID = lease.ID
+	}
+	if renewed.RequestID == "" {
+		renewed.RequestID = lease.RequestID
+	}
+
+	return renewed, nil
+}
+
+// Release libera um lease sem responder a requisição, devolvendo-a às demais instâncias.
+// Leases locais e leases já expirados não precisam ser liberados.
+// Parâmetros:
+//   - ctx: Contexto da chamada, usado para cancelamento e prazo
+//   - lease: Lease a ser liberado
+//
+// Retorna:
+//   - error: Erro em caso de falha na liberação
+func (s *SmarketGateway) Release(ctx context.Context, lease models.Lease) error {
+	if lease.ID == "" {
+		return nil
+	}
+
+	resp, err := s.do(ctx, s.renew, "DELETE", s.leaseUrl(lease, ""), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if err := leaseStatusError(resp.StatusCode, "release lease", respBody); err != nil && err != models.ErrLeaseLost {
+		return err
+	}
+
+	return nil
+}
+
+// Ack responde a requisição reservada e encerra o lease. Com um lease local, a resposta é enviada
+// por SendResponse.
+// Parâmetros:
+//   - ctx: Contexto da chamada, usado para cancelamento e prazo
+//   - lease: Lease da requisição
+//   - response: Dados da resposta de autenticação
+//
+// Retorna:
+//   - error: models.ErrLeaseLost caso o lease tenha expirado ou sido assumido por outra instância
+func (s *SmarketGateway) Ack(ctx context.Context, lease models.Lease, response models.AuthResponse) error {
+	if lease.ID == "" {
+		return s.SendResponse(ctx, lease.RequestID, response)
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(ctx, s.sendResponse, "POST", s.leaseUrl(lease, "/ack"), body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	return leaseStatusError(resp.StatusCode, "ack lease", respBody)
+}
+
+// claimByPolling obtém as requisições pela consulta simples, com leases locais
+func (s *SmarketGateway) claimByPolling(ctx context.Context) ([]models.ClaimedRequest, error) {
+	requests, err := s.GetRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make([]models.ClaimedRequest, 0, len(requests))
+	for _, request := range requests {
+		claimed = append(claimed, models.ClaimedRequest{
+			Request: request,
+			Lease:   models.Lease{RequestID: request.RequestID},
+		})
+	}
+
+	return claimed, nil
+}
+
+// leaseUrl monta a URL de um lease, seguida do sufixo informado
+func (s *SmarketGateway) leaseUrl(lease models.Lease, suffix string) string {
+	return fmt.Sprintf("%s/auth/claims/%s%s", s.baseUrl, url.PathEscape(lease.ID), suffix)
+}
+
+// leaseStatusError converte o status de uma operação sobre um lease em erro. 404, 409 e 410
+// indicam que o lease expirou ou foi assumido por outra instância.
+func leaseStatusError(statusCode int, operation string, body []byte) error {
+	switch statusCode {
+	case http.StatusOK, http.StatusNoContent:
+		return nil
+	case http.StatusNotFound, http.StatusConflict, http.StatusGone:
+		return models.ErrLeaseLost
+	default:
+		return fmt.Errorf("failed to %s: %d, body: %s", operation, statusCode, string(body))
+	}
+}
+
+// leaseSeconds converte a duração do lease em segundos, com mínimo de 1
+func leaseSeconds(ttl time.Duration) int {
+	if seconds := int(ttl / time.Second); seconds > 0 {
+		return seconds
+	}
+
+	return 1
+}
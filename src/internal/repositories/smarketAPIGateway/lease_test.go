@@ -0,0 +1,158 @@
+package smarketAPIGateway
+
+import (
+	"auth-ad/src/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newLeaseGateway cria um gateway apontando para o servidor de teste
+func newLeaseGateway(server *httptest.Server) *SmarketGateway {
+	return &SmarketGateway{
+		httpClient: server.Client(),
+		baseUrl:    server.URL + "/v1",
+		auth:       NewStaticTokenAuth("test-token"),
+		instanceID: "instancia-1",
+	}
+}
+
+func TestClaim(t *testing.T) {
+	expires := time.Now().Add(30 * time.Second).UTC().Truncate(time.Second)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v1/auth/claims" {
+			t.Errorf("Esperado POST /v1/auth/claims, recebido %s %s", r.Method, r.URL.Path)
+		}
+
+		var body claimRequest
+		json.NewDecoder(r.Body).Decode(&body)
+		if body != (claimRequest{InstanceID: "instancia-1", Max: 5, LeaseSeconds: 30}) {
+			t.Errorf("Corpo da reserva incorreto: %+v", body)
+		}
+
+		json.NewEncoder(w).Encode([]models.ClaimedRequest{{
+			Request: models.AuthRequest{RequestID: "123", Username: "user"},
+			Lease:   models.Lease{ID: "lease-1", RequestID: "123", ExpiresAt: expires},
+		}})
+	}))
+	defer server.Close()
+
+	claimed, err := newLeaseGateway(server).Claim(context.Background(), 5, 30*time.Second)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if len(claimed) != 1 || claimed[0].Request.RequestID != "123" || claimed[0].Lease.ID != "lease-1" || !claimed[0].Lease.ExpiresAt.Equal(expires) {
+		t.Errorf("Reserva incorreta: %+v", claimed)
+	}
+}
+
+func TestClaim_FallsBackToPolling(t *testing.T) {
+	var claims int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/auth/claims":
+			atomic.AddInt32(&claims, 1)
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == "GET" && r.URL.Path == "/v1/auth":
+			json.NewEncoder(w).Encode([]models.AuthRequest{{RequestID: "123"}})
+		case r.Method == "POST" && r.URL.Path == "/v1/auth/123":
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("Chamada inesperada: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	gateway := newLeaseGateway(server)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		claimed, err := gateway.Claim(ctx, 5, 30*time.Second)
+		if err != nil {
+			t.Fatalf("Erro inesperado: %v", err)
+		}
+		if len(claimed) != 1 || claimed[0].Lease != (models.Lease{RequestID: "123"}) {
+			t.Errorf("Esperado lease local da requisição 123, recebido %+v", claimed)
+		}
+	}
+
+	// O suporte a leases é verificado apenas uma vez
+	if atomic.LoadInt32(&claims) != 1 {
+		t.Errorf("Esperada 1 tentativa de reserva, recebido %d", claims)
+	}
+
+	// Leases locais não são renovados nem liberados, e a resposta usa o envio simples
+	lease := models.Lease{RequestID: "123"}
+	if _, err := gateway.Heartbeat(ctx, lease, time.Second); err != nil {
+		t.Errorf("Erro inesperado na renovação: %v", err)
+	}
+	if err := gateway.Release(ctx, lease); err != nil {
+		t.Errorf("Erro inesperado na liberação: %v", err)
+	}
+	if err := gateway.Ack(ctx, lease, models.AuthResponse{RequestID: "123"}); err != nil {
+		t.Errorf("Erro inesperado na resposta: %v", err)
+	}
+}
+
+func TestLease_HeartbeatReleaseAndAck(t *testing.T) {
+	expires := time.Now().Add(time.Minute).UTC().Truncate(time.Second)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /v1/auth/claims/lease-1/heartbeat":
+			json.NewEncoder(w).Encode(models.Lease{ID: "lease-1", ExpiresAt: expires})
+		case "DELETE /v1/auth/claims/lease-1":
+			w.WriteHeader(http.StatusNoContent)
+		case "POST /v1/auth/claims/lease-1/ack":
+			var response models.AuthResponse
+			json.NewDecoder(r.Body).Decode(&response)
+			if !response.Success {
+				t.Error("Esperada resposta de sucesso")
+			}
+			w.WriteHeader(http.StatusNoContent)
+		case "POST /v1/auth/claims/lease-2/heartbeat", "POST /v1/auth/claims/lease-2/ack":
+			w.WriteHeader(http.StatusGone)
+		case "DELETE /v1/auth/claims/lease-2":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			t.Errorf("Chamada inesperada: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	gateway := newLeaseGateway(server)
+	ctx := context.Background()
+	lease := models.Lease{ID: "lease-1", RequestID: "123"}
+
+	renewed, err := gateway.Heartbeat(ctx, lease, time.Minute)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if renewed.RequestID != "123" || !renewed.ExpiresAt.Equal(expires) {
+		t.Errorf("Lease renovado incorreto: %+v", renewed)
+	}
+	if err := gateway.Release(ctx, lease); err != nil {
+		t.Errorf("Erro inesperado na liberação: %v", err)
+	}
+	if err := gateway.Ack(ctx, lease, models.AuthResponse{RequestID: "123", Success: true}); err != nil {
+		t.Errorf("Erro inesperado na resposta: %v", err)
+	}
+
+	// Um lease expirado ou assumido por outra instância é reportado como perdido
+	lost := models.Lease{ID: "lease-2", RequestID: "456"}
+	if _, err := gateway.Heartbeat(ctx, lost, time.Minute); !errors.Is(err, models.ErrLeaseLost) {
+		t.Errorf("Esperado ErrLeaseLost na renovação, recebido %v", err)
+	}
+	if err := gateway.Ack(ctx, lost, models.AuthResponse{}); !errors.Is(err, models.ErrLeaseLost) {
+		t.Errorf("Esperado ErrLeaseLost na resposta, recebido %v", err)
+	}
+	if err := gateway.Release(ctx, lost); err != nil {
+		t.Errorf("Liberar um lease já expirado não deve falhar: %v", err)
+	}
+}
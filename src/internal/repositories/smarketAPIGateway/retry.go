@@ -0,0 +1,129 @@
+package smarketAPIGateway
+
+import (
+	"auth-ad/src/pkg/configs"
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// operation descreve uma chamada à API e como ela pode ser repetida
+type operation struct {
+	// idempotent indica se a chamada pode ser repetida mesmo quando a API pode tê-la processado
+	idempotent bool
+	// longPoll indica uma consulta que a API pode segurar aguardando novas requisições
+	longPoll bool
+	retry    configs.RetryConfig
+}
+
+// shouldRetry indica se uma tentativa com falha pode ser repetida.
+// Chamadas idempotentes são repetidas em qualquer falha transitória (timeout, conexão
+// interrompida, 429, 500, 502, 503 e 504). As demais só são repetidas quando a API
+// certamente não as processou: falha ao abrir a conexão, 429 ou 503.
+// Parâmetros:
+//   - ctx: Contexto da chamada; cancelamentos do chamador nunca são repetidos
+//   - resp: Resposta da tentativa, ou nil
+//   - err: Erro da tentativa, ou nil
+//
+// Retorna:
+//   - bool: true se a chamada pode ser repetida
+func (o operation) shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		if isDialError(err) {
+			return true
+		}
+
+		var netErr net.Error
+		return o.idempotent && errors.As(err, &netErr)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return o.idempotent
+	}
+
+	return false
+}
+
+// backoff calcula a espera antes da próxima tentativa, com backoff exponencial e jitter
+// completo. Quando a resposta traz Retry-After, o valor indicado pela API é respeitado, limitado
+// a MaxBackoff para que uma resposta da API não segure a chamada por tempo indeterminado.
+// Parâmetros:
+//   - attempt: Número da tentativa que falhou, a partir de 1
+//   - resp: Resposta da tentativa, ou nil
+//
+// Retorna:
+//   - time.Duration: Espera antes da próxima tentativa
+func (o operation) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if delay, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			if o.retry.MaxBackoff > 0 && delay > o.retry.MaxBackoff {
+				delay = o.retry.MaxBackoff
+			}
+			return delay
+		}
+	}
+
+	ceiling := o.retry.InitialBackoff
+	for i := 1; i < attempt && ceiling < o.retry.MaxBackoff; i++ {
+		ceiling *= 2
+	}
+	if o.retry.MaxBackoff > 0 && ceiling > o.retry.MaxBackoff {
+		ceiling = o.retry.MaxBackoff
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+
+	return rand.N(ceiling + 1)
+}
+
+// retryAfter interpreta o cabeçalho Retry-After, em segundos ou como data HTTP
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+
+	return 0, false
+}
+
+// isDialError indica se a falha ocorreu ao abrir a conexão, antes de a requisição ser enviada
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// wait aguarda o tempo informado ou o cancelamento do contexto
+func wait(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
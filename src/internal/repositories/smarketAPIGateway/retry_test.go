@@ -0,0 +1,173 @@
+package smarketAPIGateway
+
+import (
+	"auth-ad/src/internal/models"
+	"auth-ad/src/pkg/configs"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testRetry é uma política de novas tentativas com esperas curtas para os testes
+var testRetry = configs.RetryConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+// newFlakyServer cria um servidor que responde com os status informados e, depois deles, com sucesso
+func newFlakyServer(statuses []int, calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := int(atomic.AddInt32(calls, 1))
+		if call <= len(statuses) {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(statuses[call-1])
+			return
+		}
+		json.NewEncoder(w).Encode([]models.AuthRequest{})
+	}))
+}
+
+// newRetryGateway cria um gateway com as políticas de novas tentativas de produção e esperas curtas
+func newRetryGateway(server *httptest.Server) *SmarketGateway {
+	return &SmarketGateway{
+		httpClient:   server.Client(),
+		baseUrl:      server.URL + "/v1",
+		auth:         NewStaticTokenAuth("test-token"),
+		getRequest:   operation{idempotent: true, retry: testRetry},
+		sendResponse: operation{idempotent: false, retry: testRetry},
+	}
+}
+
+func TestGetRequest_RetriesTransientErrors(t *testing.T) {
+	var calls int32
+	server := newFlakyServer([]int{http.StatusServiceUnavailable, http.StatusBadGateway}, &calls)
+	defer server.Close()
+
+	_, err := newRetryGateway(server).GetRequest(context.Background())
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if atomic.LoadInt32(&calls) != 3 {
+		t.Errorf("Esperado 3 chamadas, recebido %d", calls)
+	}
+}
+
+func TestGetRequest_StopsAtMaxAttempts(t *testing.T) {
+	var calls int32
+	server := newFlakyServer([]int{500, 500, 500, 500}, &calls)
+	defer server.Close()
+
+	_, err := newRetryGateway(server).GetRequest(context.Background())
+	if err == nil {
+		t.Fatal("Esperado erro, recebido nil")
+	}
+	if atomic.LoadInt32(&calls) != 3 {
+		t.Errorf("Esperado 3 chamadas, recebido %d", calls)
+	}
+}
+
+func TestSendResponse_RetriesOnlyUnprocessedCalls(t *testing.T) {
+	// 503 indica que a API não processou a chamada e pode ser repetido
+	var calls int32
+	server := newFlakyServer([]int{http.StatusServiceUnavailable}, &calls)
+	defer server.Close()
+
+	if err := newRetryGateway(server).SendResponse(context.Background(), "123", models.AuthResponse{}); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("Esperado 2 chamadas, recebido %d", calls)
+	}
+
+	// 500 pode ter ocorrido após a resposta ser gravada e não é repetido
+	atomic.StoreInt32(&calls, 0)
+	server500 := newFlakyServer([]int{http.StatusInternalServerError}, &calls)
+	defer server500.Close()
+
+	if err := newRetryGateway(server500).SendResponse(context.Background(), "123", models.AuthResponse{}); err == nil {
+		t.Fatal("Esperado erro, recebido nil")
+	}
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("Esperado 1 chamada, recebido %d", calls)
+	}
+}
+
+func TestGetRequest_RetryAfterBeyondDeadline(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	gateway := newRetryGateway(server)
+	gateway.getRequest.retry.MaxBackoff = time.Minute
+
+	// A espera pedida pela API ultrapassa o prazo, então a falha é retornada sem aguardar
+	_, err := gateway.GetRequest(ctx)
+	if err == nil {
+		t.Fatal("Esperado erro, recebido nil")
+	}
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("Esperado 1 chamada, recebido %d", calls)
+	}
+}
+
+func TestOperation_ShouldRetry(t *testing.T) {
+	get := operation{idempotent: true, retry: testRetry}
+	send := operation{idempotent: false, retry: testRetry}
+	ctx := context.Background()
+
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "connection refused"}}
+	readErr := &net.OpError{Op: "read", Net: "tcp", Err: &net.DNSError{Err: "connection reset by peer"}}
+
+	if !get.shouldRetry(ctx, nil, dialErr) || !send.shouldRetry(ctx, nil, dialErr) {
+		t.Error("Falhas ao abrir a conexão devem ser repetidas")
+	}
+	if !get.shouldRetry(ctx, nil, readErr) || send.shouldRetry(ctx, nil, readErr) {
+		t.Error("Conexão interrompida só deve ser repetida em operações idempotentes")
+	}
+	if get.shouldRetry(ctx, &http.Response{StatusCode: http.StatusBadRequest}, nil) {
+		t.Error("Erros do cliente não devem ser repetidos")
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if get.shouldRetry(canceled, nil, dialErr) {
+		t.Error("Chamadas canceladas não devem ser repetidas")
+	}
+}
+
+func TestOperation_Backoff(t *testing.T) {
+	op := operation{retry: configs.RetryConfig{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}}
+
+	for attempt := 1; attempt <= 5; attempt++ {
+		if delay := op.backoff(attempt, nil); delay < 0 || delay > 300*time.Millisecond {
+			t.Errorf("Espera fora do limite na tentativa %d: %s", attempt, delay)
+		}
+	}
+
+	resp := &http.Response{Header: http.Header{"Retry-After": {"2"}}}
+	if delay := op.backoff(1, resp); delay != 300*time.Millisecond {
+		t.Errorf("Esperado Retry-After limitado a 300ms, recebido %s", delay)
+	}
+
+	resp = &http.Response{Header: http.Header{"Retry-After": {"0"}}}
+	if delay := op.backoff(1, resp); delay != 0 {
+		t.Errorf("Esperado Retry-After de 0s, recebido %s", delay)
+	}
+
+	date := time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)
+	if delay, ok := retryAfter(date); !ok || delay != 0 {
+		t.Errorf("Esperado Retry-After vencido igual a 0, recebido %s", delay)
+	}
+	if _, ok := retryAfter("amanhã"); ok {
+		t.Error("Retry-After inválido não deve ser aceito")
+	}
+}
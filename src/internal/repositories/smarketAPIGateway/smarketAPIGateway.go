@@ -3,49 +3,111 @@ package smarketAPIGateway
 import (
 	"auth-ad/src/internal/interfaces"
 	"auth-ad/src/internal/models"
+	"auth-ad/src/pkg/configs"
+	"auth-ad/src/pkg/logging"
+	"auth-ad/src/pkg/tracing"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// tracerName identifica os spans das chamadas à API
+const tracerName = "auth-ad/smarketAPIGateway"
+
+// logger registra os eventos da comunicação com a API
+var logger = logging.Component("smarketAPIGateway")
+
 // SmarketGateway implementa a interface IApiRepository para comunicação com a API Smarket
 type SmarketGateway struct {
-	httpClient *http.Client
-	baseUrl    string
-	token      string
+	httpClient   *http.Client
+	baseUrl      string
+	auth         AuthStrategy
+	getRequest   operation
+	sendResponse operation
+
+	// longPollWait é o tempo que a API pode segurar uma consulta vazia; 0 desativa o long polling
+	longPollWait time.Duration
+	// pollClient é o cliente das consultas, com timeout acrescido de longPollWait
+	pollClient *http.Client
+
+	// instanceID identifica esta instância nas reservas de requisições
+	instanceID string
+	// claim e renew são as políticas da reserva e da renovação ou liberação de leases
+	claim operation
+	renew operation
+	// leasesUnsupported indica que a API não suporta leases e a consulta simples é usada
+	leasesUnsupported atomic.Bool
 }
 
 // NewSmarketGateway cria uma nova instância de SmarketGateway
 // Parâmetros:
-//   - token: Token de autenticação para a API
+//   - config: Configurações da API, com a URL, o tempo máximo de cada chamada e as credenciais
 //
 // Retorna:
 //   - interfaces.IApiRepository: Interface implementada pelo gateway
-func NewSmarketGateway(token string, baseUrl string) interfaces.IApiRepository {
-	return &SmarketGateway{
-		httpClient: http.DefaultClient,
-		baseUrl:    baseUrl,
-		token:      token,
-	}
+//   - error: Erro em caso de credenciais não configuradas
+func NewSmarketGateway(config *configs.ApiConfig) (interfaces.IApiRepository, error) {
+	return NewSmarketGatewayWithTransport(config, http.DefaultTransport)
 }
 
-// GetRequest busca as requisições de autenticação pendentes
+// NewSmarketGatewayWithTransport cria uma nova instância de SmarketGateway cujas chamadas HTTP,
+// inclusive as de emissão de tokens OAuth2, passam pelo transporte informado
+// Parâmetros:
+//   - config: Configurações da API, com a URL, o tempo máximo de cada chamada e as credenciais
+//   - transport: Transporte das chamadas HTTP, como um transporte instrumentado com métricas
+//
 // Retorna:
-//   - []models.AuthRequest: Lista de requisições de autenticação
-//   - error: Erro em caso de falha na requisição
-func (s *SmarketGateway) GetRequest() ([]models.AuthRequest, error) {
+//   - interfaces.IApiRepository: Interface implementada pelo gateway
+//   - error: Erro em caso de credenciais não configuradas
+func NewSmarketGatewayWithTransport(config *configs.ApiConfig, transport http.RoundTripper) (interfaces.IApiRepository, error) {
+	httpClient := &http.Client{Transport: transport, Timeout: config.Timeout}
 
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/auth", s.baseUrl), nil)
+	auth, err := NewAuthStrategy(config, httpClient)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.token))
+	return &SmarketGateway{
+		httpClient: httpClient,
+		baseUrl:    config.Url,
+		auth:       auth,
+		// A consulta é segura para repetir; o envio da resposta só é repetido quando a API
+		// certamente não o processou
+		getRequest:   operation{idempotent: true, longPoll: true, retry: config.GetRequestRetry},
+		sendResponse: operation{idempotent: false, retry: config.SendResponseRetry},
+		longPollWait: config.LongPollWait,
+		pollClient:   &http.Client{Transport: transport, Timeout: config.Timeout + config.LongPollWait},
+		instanceID:   config.InstanceID,
+		// Uma reserva repetida pode reservar requisições que nunca serão processadas até o lease
+		// expirar; renovar ou liberar um lease pode ser repetido sem efeitos adicionais
+		claim: operation{idempotent: false, longPoll: true, retry: config.GetRequestRetry},
+		renew: operation{idempotent: true, retry: config.GetRequestRetry},
+	}, nil
+}
 
-	resp, err := s.httpClient.Do(req)
+// GetRequest busca as requisições de autenticação pendentes. Com o long polling ativo, a API pode
+// segurar a consulta por até longPollWait aguardando novas requisições.
+// Parâmetros:
+//   - ctx: Contexto da chamada, usado para cancelamento e prazo
+//
+// Retorna:
+//   - []models.AuthRequest: Lista de requisições de autenticação
+//   - error: Erro em caso de falha na requisição
+func (s *SmarketGateway) GetRequest(ctx context.Context) ([]models.AuthRequest, error) {
+
+	resp, err := s.do(ctx, s.getRequest, "GET", s.pollUrl("/auth"), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -59,6 +121,10 @@ func (s *SmarketGateway) GetRequest() ([]models.AuthRequest, error) {
 		return nil, err
 	}
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get requests: %d, body: %s", resp.StatusCode, string(body))
+	}
+
 	if err := json.Unmarshal(body, &responseJsons); err != nil {
 		return nil, err
 	}
@@ -68,27 +134,20 @@ func (s *SmarketGateway) GetRequest() ([]models.AuthRequest, error) {
 
 // SendResponse envia uma resposta de autenticação para uma requisição específica
 // Parâmetros:
+//   - ctx: Contexto da chamada, usado para cancelamento e prazo
 //   - requestId: ID da requisição a ser respondida
 //   - response: Dados da resposta de autenticação
 //
 // Retorna:
 //   - error: Erro em caso de falha no envio da resposta
-func (s *SmarketGateway) SendResponse(requestId string, response models.AuthResponse) error {
+func (s *SmarketGateway) SendResponse(ctx context.Context, requestId string, response models.AuthResponse) error {
 
 	responseBody, err := json.Marshal(response)
 	if err != nil {
 		return err
 	}
 
-	request, err := http.NewRequest("POST", fmt.Sprintf("%s/auth/%s", s.baseUrl, requestId), bytes.NewBuffer(responseBody))
-	if err != nil {
-		return err
-	}
-
-	request.Header.Set("Content-Type", "application/json")
-	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.token))
-
-	resp, err := s.httpClient.Do(request)
+	resp, err := s.do(ctx, s.sendResponse, "POST", fmt.Sprintf("%s/auth/%s", s.baseUrl, requestId), responseBody)
 	if err != nil {
 		return err
 	}
@@ -105,3 +164,157 @@ func (s *SmarketGateway) SendResponse(requestId string, response models.AuthResp
 
 	return nil
 }
+
+// Ping verifica se a API está acessível e aceita as credenciais do serviço, com uma chamada HEAD à
+// rota das requisições, que não reserva nem consome requisições. Com OAuth2, o token é obtido ou
+// renovado se necessário.
+// Parâmetros:
+//   - ctx: Contexto da chamada, usado para cancelamento e prazo
+//
+// Retorna:
+//   - error: Erro em caso de API inacessível, credenciais recusadas ou falha do servidor
+func (s *SmarketGateway) Ping(ctx context.Context) error {
+	resp, err := s.send(ctx, s.httpClient, http.MethodHead, s.baseUrl+"/auth", nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("credenciais recusadas pela API: %d", resp.StatusCode)
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return fmt.Errorf("API indisponível: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// do executa uma chamada à API, repetindo as falhas transitórias conforme a política da operação.
+// A espera entre tentativas segue o Retry-After da API ou o backoff exponencial com jitter e não
+// ultrapassa o prazo do contexto.
+// Parâmetros:
+//   - ctx: Contexto da chamada
+//   - op: Operação executada, com sua política de novas tentativas
+//   - method: Método HTTP
+//   - url: URL da chamada
+//   - body: Corpo da requisição, ou nil
+//
+// Retorna:
+//   - *http.Response: Resposta da última tentativa
+//   - error: Erro da última tentativa
+func (s *SmarketGateway) do(ctx context.Context, op operation, method, url string, body []byte) (*http.Response, error) {
+	client := s.httpClient
+	if op.longPoll && s.pollClient != nil {
+		client = s.pollClient
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := s.send(ctx, client, method, url, body)
+		if attempt >= op.retry.MaxAttempts || !op.shouldRetry(ctx, resp, err) {
+			return resp, err
+		}
+
+		delay := op.backoff(attempt, resp)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return resp, err
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		if err := wait(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// send executa uma tentativa autenticada de chamada à API. Caso a API rejeite as credenciais
+// com 401, elas são invalidadas e a chamada é repetida uma única vez com credenciais renovadas.
+// Parâmetros:
+//   - ctx: Contexto da chamada
+//   - client: Cliente HTTP usado na chamada
+//   - method: Método HTTP
+//   - url: URL da chamada
+//   - body: Corpo da requisição, ou nil
+//
+// Retorna:
+//   - *http.Response: Resposta da API
+//   - error: Erro em caso de falha na chamada
+func (s *SmarketGateway) send(ctx context.Context, client *http.Client, method, url string, body []byte) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+
+		request, err := http.NewRequestWithContext(ctx, method, url, reader)
+		if err != nil {
+			return nil, err
+		}
+
+		request.Header.Set("Content-Type", "application/json")
+		if err := s.auth.Authorize(ctx, request); err != nil {
+			return nil, err
+		}
+
+		resp, err := roundTrip(ctx, client, request)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusUnauthorized || attempt > 0 {
+			return resp, nil
+		}
+
+		resp.Body.Close()
+		s.auth.Invalidate()
+	}
+}
+
+// roundTrip executa uma chamada HTTP em um span de cliente, filho do span da etapa que fez a
+// chamada, e propaga o contexto de trace à API nos cabeçalhos W3C traceparent e tracestate.
+// Respostas 5xx marcam o span com erro.
+// Parâmetros:
+//   - ctx: Contexto da chamada, com o span da etapa
+//   - client: Cliente HTTP usado na chamada
+//   - request: Requisição HTTP
+//
+// Retorna:
+//   - *http.Response: Resposta da API
+//   - error: Erro em caso de falha na chamada
+func roundTrip(ctx context.Context, client *http.Client, request *http.Request) (*http.Response, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "HTTP "+request.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", request.Method),
+			attribute.String("server.address", request.URL.Hostname()),
+			attribute.String("url.path", request.URL.Path),
+		))
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(request.Header))
+
+	resp, err := client.Do(request)
+	if err != nil {
+		tracing.End(span, err)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	span.End()
+
+	return resp, nil
+}
+
+// pollUrl monta a URL de uma consulta, com o parâmetro wait (em segundos) quando o long polling está ativo
+func (s *SmarketGateway) pollUrl(path string) string {
+	url := s.baseUrl + path
+	if s.longPollWait <= 0 {
+		return url
+	}
+
+	return url + "?wait=" + strconv.Itoa(int(s.longPollWait/time.Second))
+}
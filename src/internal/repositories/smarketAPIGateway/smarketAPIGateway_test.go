@@ -2,10 +2,20 @@ package smarketAPIGateway
 
 import (
 	"auth-ad/src/internal/models"
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestGetRequest(t *testing.T) {
@@ -36,11 +46,11 @@ func TestGetRequest(t *testing.T) {
 	gateway := &SmarketGateway{
 		httpClient: server.Client(),
 		baseUrl:    server.URL + "/v1",
-		token:      "test-token",
+		auth:       NewStaticTokenAuth("test-token"),
 	}
 
 	// Executar teste
-	requests, err := gateway.GetRequest()
+	requests, err := gateway.GetRequest(context.Background())
 	if err != nil {
 		t.Fatalf("Erro inesperado: %v", err)
 	}
@@ -81,11 +91,11 @@ func TestSendResponse(t *testing.T) {
 	gateway := &SmarketGateway{
 		httpClient: server.Client(),
 		baseUrl:    server.URL + "/v1",
-		token:      "test-token",
+		auth:       NewStaticTokenAuth("test-token"),
 	}
 
 	// Executar teste
-	err := gateway.SendResponse("123", models.AuthResponse{
+	err := gateway.SendResponse(context.Background(), "123", models.AuthResponse{
 		Success: true,
 	})
 
@@ -107,11 +117,11 @@ func TestSendResponse_Error(t *testing.T) {
 	gateway := &SmarketGateway{
 		httpClient: server.Client(),
 		baseUrl:    server.URL + "/v1",
-		token:      "test-token",
+		auth:       NewStaticTokenAuth("test-token"),
 	}
 
 	// Executar teste
-	err := gateway.SendResponse("123", models.AuthResponse{
+	err := gateway.SendResponse(context.Background(), "123", models.AuthResponse{
 		Success: true,
 	})
 
@@ -120,3 +130,189 @@ func TestSendResponse_Error(t *testing.T) {
 		t.Fatal("Esperado erro, recebido nil")
 	}
 }
+
+func TestGetRequest_ContextCanceled(t *testing.T) {
+	// Configurar servidor mock que demora a responder
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	gateway := &SmarketGateway{
+		httpClient: server.Client(),
+		baseUrl:    server.URL + "/v1",
+		auth:       NewStaticTokenAuth("test-token"),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// Executar teste
+	_, err := gateway.GetRequest(ctx)
+
+	// Verificar se a chamada foi interrompida pelo prazo do contexto
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Esperado context.DeadlineExceeded, recebido %v", err)
+	}
+}
+
+func TestGetRequest_RetriesOnceOnUnauthorized(t *testing.T) {
+	var issued int32
+	tokenServer := newTokenServer(t, 3600, &issued)
+	defer tokenServer.Close()
+
+	// Configurar servidor mock que só aceita o segundo token emitido
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode([]models.AuthRequest{{RequestID: "123"}})
+	}))
+	defer server.Close()
+
+	gateway := &SmarketGateway{
+		httpClient: server.Client(),
+		baseUrl:    server.URL + "/v1",
+		auth:       NewClientCredentialsAuth(newOAuthConfig(tokenServer.URL), tokenServer.Client()),
+	}
+
+	// Executar teste
+	requests, err := gateway.GetRequest(context.Background())
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	// Verificar que a chamada foi repetida com o token renovado
+	if len(requests) != 1 || atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("Esperado 1 request após 2 chamadas, recebido %d requests e %d chamadas", len(requests), calls)
+	}
+
+	// Um novo 401 não é repetido indefinidamente
+	tokenServer.Close()
+	gateway.auth = NewStaticTokenAuth("expirado")
+	atomic.StoreInt32(&calls, 0)
+	if _, err := gateway.GetRequest(context.Background()); err == nil {
+		t.Fatal("Esperado erro, recebido nil")
+	}
+	if atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("Esperado 2 chamadas, recebido %d", calls)
+	}
+}
+
+func TestGetRequest_LongPoll(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("wait") != "20" {
+			t.Errorf("Esperado wait=20, recebido %q", r.URL.RawQuery)
+		}
+		json.NewEncoder(w).Encode([]models.AuthRequest{})
+	}))
+	defer server.Close()
+
+	gateway := &SmarketGateway{
+		httpClient:   server.Client(),
+		pollClient:   server.Client(),
+		baseUrl:      server.URL + "/v1",
+		auth:         NewStaticTokenAuth("test-token"),
+		getRequest:   operation{longPoll: true},
+		longPollWait: 20 * time.Second,
+	}
+
+	requests, err := gateway.GetRequest(context.Background())
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if len(requests) != 0 {
+		t.Errorf("Esperado nenhum request, recebido %d", len(requests))
+	}
+}
+
+func TestPing(t *testing.T) {
+	var status atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead || r.URL.Path != "/v1/auth" {
+			t.Errorf("Esperado HEAD /v1/auth, recebido %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			t.Error("Token de autorização inválido")
+		}
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer server.Close()
+
+	gateway := &SmarketGateway{
+		httpClient: server.Client(),
+		baseUrl:    server.URL + "/v1",
+		auth:       NewStaticTokenAuth("test-token"),
+	}
+
+	// Rotas sem suporte a HEAD também indicam uma API acessível e credenciais aceitas
+	for _, code := range []int{http.StatusOK, http.StatusMethodNotAllowed} {
+		status.Store(int32(code))
+		if err := gateway.Ping(context.Background()); err != nil {
+			t.Errorf("Erro inesperado com status %d: %v", code, err)
+		}
+	}
+
+	for _, code := range []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusServiceUnavailable} {
+		status.Store(int32(code))
+		if err := gateway.Ping(context.Background()); err == nil {
+			t.Errorf("Esperado erro com status %d", code)
+		}
+	}
+
+	server.Close()
+	if err := gateway.Ping(context.Background()); err == nil {
+		t.Error("Esperado erro com a API inacessível")
+	}
+}
+
+func TestSendResponsePropagatesTraceContext(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTracerProvider(previousProvider)
+	defer otel.SetTextMapPropagator(previousPropagator)
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "authentication.respond")
+	traceID := parent.SpanContext().TraceID().String()
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	gateway := &SmarketGateway{
+		httpClient: server.Client(),
+		baseUrl:    server.URL + "/v1",
+		auth:       NewStaticTokenAuth("test-token"),
+	}
+
+	if err := gateway.SendResponse(ctx, "123", models.AuthResponse{Success: true}); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	parent.End()
+
+	// O cabeçalho traceparent leva o trace da etapa e o span da chamada HTTP
+	if !strings.Contains(traceparent, traceID) {
+		t.Errorf("Esperado traceparent com o trace %s, recebido %q", traceID, traceparent)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 || spans[0].Name != "HTTP POST" {
+		t.Fatalf("Esperado o span HTTP POST e o span da etapa, recebido %d spans", len(spans))
+	}
+	if spans[0].Parent.SpanID() != parent.SpanContext().SpanID() {
+		t.Error("O span da chamada HTTP deve ser filho do span da etapa")
+	}
+	if !strings.Contains(traceparent, spans[0].SpanContext.SpanID().String()) {
+		t.Errorf("Esperado traceparent com o span da chamada HTTP, recebido %q", traceparent)
+	}
+}
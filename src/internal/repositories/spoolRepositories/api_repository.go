@@ -0,0 +1,288 @@
+package spoolRepositories
+
+import (
+	"auth-ad/src/internal/interfaces"
+	"auth-ad/src/internal/models"
+	"auth-ad/src/internal/spool"
+	"auth-ad/src/pkg/configs"
+	"auth-ad/src/pkg/logging"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// logger registra os reenvios das respostas guardadas no spool
+var logger = logging.Component("spool")
+
+// ApiRepository guarda no spool as respostas que não puderam ser entregues à API e as
+// reenvia em segundo plano, com backoff exponencial por resposta. Respostas mais antigas
+// que a idade máxima configurada são descartadas sem serem entregues.
+type ApiRepository struct {
+	repository interfaces.IApiRepository
+	spool      *spool.Spool
+	config     *configs.SpoolConfig
+	now        func() time.Time
+
+	mu      sync.Mutex
+	retries map[string]*retryState
+}
+
+// retryState controla os reenvios de uma resposta guardada no spool
+type retryState struct {
+	attempts int
+	next     time.Time
+}
+
+// NewApiRepository cria o repositório da API com spool das respostas não entregues
+// Params:
+//   - repository: Repositório da API
+//   - spool: Spool onde as respostas não entregues são guardadas
+//   - config: Configurações do spool
+//
+// Returns:
+//   - *ApiRepository: Repositório com spool, cujo reenvio é executado por Run
+func NewApiRepository(repository interfaces.IApiRepository, spool *spool.Spool, config *configs.SpoolConfig) *ApiRepository {
+	return &ApiRepository{
+		repository: repository,
+		spool:      spool,
+		config:     config,
+		now:        time.Now,
+		retries:    make(map[string]*retryState),
+	}
+}
+
+// GetRequest busca as requisições de autenticação pendentes
+// Params:
+//   - ctx: Contexto da operação
+//
+// Returns:
+//   - []models.AuthRequest: Requisições pendentes
+//   - error: Erro em caso de falha na busca
+func (r *ApiRepository) GetRequest(ctx context.Context) ([]models.AuthRequest, error) {
+	return r.repository.GetRequest(ctx)
+}
+
+// SendResponse envia a resposta de uma requisição. Caso o envio falhe, a resposta é guardada
+// no spool para reenvio e a chamada é bem-sucedida; só há erro se a resposta não puder ser guardada.
+// Params:
+//   - ctx: Contexto da operação
+//   - requestId: ID da requisição
+//   - response: Resposta da autenticação
+//
+// Returns:
+//   - error: Erro em caso de falha no envio e ao guardar a resposta no spool
+func (r *ApiRepository) SendResponse(ctx context.Context, requestId string, response models.AuthResponse) error {
+	err := r.repository.SendResponse(ctx, requestId, response)
+	if err == nil {
+		// Uma resposta anterior da mesma requisição, ainda no spool, não precisa mais ser reenviada
+		r.ack(requestId)
+		return nil
+	}
+
+	return r.store(requestId, response, err)
+}
+
+// store guarda no spool uma resposta cujo envio falhou
+// Params:
+//   - requestID: ID da requisição
+//   - response: Resposta da autenticação
+//   - sendErr: Erro do envio
+//
+// Returns:
+//   - error: Erro em caso de falha ao guardar a resposta no spool
+func (r *ApiRepository) store(requestID string, response models.AuthResponse, sendErr error) error {
+	if err := r.spool.Put(requestID, response); err != nil {
+		return fmt.Errorf("erro ao enviar a resposta: %w; erro ao guardá-la no spool: %w", sendErr, err)
+	}
+	r.schedule(requestID, 0)
+
+	logger.Warn("Resposta guardada no spool para reenvio", logging.AttrRequestID, requestID, logging.AttrError, sendErr)
+
+	return nil
+}
+
+// Claim reserva requisições pendentes com um lease
+// Params:
+//   - ctx: Contexto da operação
+//   - max: Quantidade máxima de requisições reservadas
+//   - ttl: Duração do lease
+//
+// Returns:
+//   - []models.ClaimedRequest: Requisições reservadas
+//   - error: Erro em caso de falha na reserva
+func (r *ApiRepository) Claim(ctx context.Context, max int, ttl time.Duration) ([]models.ClaimedRequest, error) {
+	return r.repository.Claim(ctx, max, ttl)
+}
+
+// Heartbeat renova um lease
+// Params:
+//   - ctx: Contexto da operação
+//   - lease: Lease a ser renovado
+//   - ttl: Nova duração do lease
+//
+// Returns:
+//   - models.Lease: Lease renovado
+//   - error: Erro em caso de falha na renovação
+func (r *ApiRepository) Heartbeat(ctx context.Context, lease models.Lease, ttl time.Duration) (models.Lease, error) {
+	return r.repository.Heartbeat(ctx, lease, ttl)
+}
+
+// Release libera um lease sem responder a requisição
+// Params:
+//   - ctx: Contexto da operação
+//   - lease: Lease a ser liberado
+//
+// Returns:
+//   - error: Erro em caso de falha na liberação
+func (r *ApiRepository) Release(ctx context.Context, lease models.Lease) error {
+	return r.repository.Release(ctx, lease)
+}
+
+// Ack responde a requisição reservada e encerra o lease. Caso o envio falhe, a resposta é guardada
+// no spool e reenviada por SendResponse. Um lease perdido não é guardado: a requisição passou para
+// outra instância, que a responderá.
+// Params:
+//   - ctx: Contexto da operação
+//   - lease: Lease da requisição
+//   - response: Resposta da autenticação
+//
+// Returns:
+//   - error: models.ErrLeaseLost, ou erro em caso de falha no envio e ao guardar a resposta no spool
+func (r *ApiRepository) Ack(ctx context.Context, lease models.Lease, response models.AuthResponse) error {
+	err := r.repository.Ack(ctx, lease, response)
+	if err == nil {
+		r.ack(lease.RequestID)
+		return nil
+	}
+	if errors.Is(err, models.ErrLeaseLost) {
+		return err
+	}
+
+	return r.store(lease.RequestID, response, err)
+}
+
+// Run reenvia as respostas do spool a cada SpoolConfig.FlushInterval, até o cancelamento do contexto
+// Params:
+//   - ctx: Contexto que encerra o reenvio
+func (r *ApiRepository) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		r.Flush(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush reenvia as respostas do spool cuja espera terminou e descarta as que excederam a idade máxima
+// Params:
+//   - ctx: Contexto da operação
+func (r *ApiRepository) Flush(ctx context.Context) {
+	for _, entry := range r.spool.Entries() {
+		if ctx.Err() != nil {
+			return
+		}
+
+		now := r.now()
+		if now.Sub(entry.CreatedAt) > r.config.MaxAge {
+			r.drop(entry)
+			continue
+		}
+
+		if !r.due(entry.RequestID, now) {
+			continue
+		}
+
+		if err := r.repository.SendResponse(ctx, entry.RequestID, entry.Response); err != nil {
+			attempts := r.schedule(entry.RequestID, 1)
+			logger.Warn("Erro ao reenviar a resposta", logging.AttrRequestID, entry.RequestID, "attempt", attempts, logging.AttrError, err)
+			continue
+		}
+
+		r.ack(entry.RequestID)
+		logger.Info("Resposta reenviada a partir do spool", logging.AttrRequestID, entry.RequestID)
+	}
+}
+
+// ack registra a entrega de uma resposta do spool
+func (r *ApiRepository) ack(requestID string) {
+	if !r.spool.Has(requestID) {
+		return
+	}
+
+	if err := r.spool.Ack(requestID); err != nil {
+		logger.Error("Erro ao registrar a entrega no spool", logging.AttrRequestID, requestID, logging.AttrError, err)
+	}
+	r.forget(requestID)
+}
+
+// drop descarta uma resposta que excedeu a idade máxima
+func (r *ApiRepository) drop(entry spool.Entry) {
+	if err := r.spool.Drop(entry.RequestID); err != nil {
+		logger.Error("Erro ao descartar a resposta do spool", logging.AttrRequestID, entry.RequestID, logging.AttrError, err)
+		return
+	}
+	r.forget(entry.RequestID)
+
+	logger.Warn("Resposta descartada do spool sem ser entregue", logging.AttrRequestID, entry.RequestID, "age", r.now().Sub(entry.CreatedAt).Round(time.Second))
+}
+
+// due indica se a espera antes do próximo reenvio terminou
+func (r *ApiRepository) due(requestID string, now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, ok := r.retries[requestID]
+	return !ok || !now.Before(state.next)
+}
+
+// schedule registra falhas de envio e agenda o próximo reenvio
+// Params:
+//   - requestID: ID da requisição
+//   - failures: Falhas a somar às tentativas já registradas
+//
+// Returns:
+//   - int: Tentativas de reenvio registradas
+func (r *ApiRepository) schedule(requestID string, failures int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, ok := r.retries[requestID]
+	if !ok {
+		state = &retryState{}
+		r.retries[requestID] = state
+	}
+
+	state.attempts += failures
+	state.next = r.now().Add(r.backoff(state.attempts))
+
+	return state.attempts
+}
+
+// forget descarta o controle de reenvios de uma requisição
+func (r *ApiRepository) forget(requestID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.retries, requestID)
+}
+
+// backoff calcula a espera antes do próximo reenvio, dobrando a cada falha até SpoolConfig.MaxBackoff
+func (r *ApiRepository) backoff(attempts int) time.Duration {
+	delay := r.config.InitialBackoff
+	for i := 0; i < attempts && delay < r.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > r.config.MaxBackoff {
+		delay = r.config.MaxBackoff
+	}
+
+	return delay
+}
@@ -0,0 +1,124 @@
+package spoolRepositories
+
+import (
+	"auth-ad/src/internal/interfaces/mocks"
+	"auth-ad/src/internal/models"
+	"auth-ad/src/internal/spool"
+	"auth-ad/src/pkg/configs"
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newTestRepository cria o repositório com spool em um diretório temporário e relógio controlado pelo teste
+func newTestRepository(t *testing.T, now *time.Time) (*ApiRepository, *mocks.IApiRepository, *spool.Spool) {
+	s, err := spool.Open(filepath.Join(t.TempDir(), "spool.jsonl"))
+	if err != nil {
+		t.Fatalf("Erro ao abrir o spool: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	mockRepo := new(mocks.IApiRepository)
+	repository := NewApiRepository(mockRepo, s, &configs.SpoolConfig{
+		MaxAge:         time.Hour,
+		FlushInterval:  time.Second,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+	})
+	repository.now = func() time.Time { return *now }
+
+	return repository, mockRepo, s
+}
+
+func TestSendResponse_SpoolsFailedDelivery(t *testing.T) {
+	now := time.Now()
+	repository, mockRepo, s := newTestRepository(t, &now)
+	ctx := context.Background()
+	response := models.AuthResponse{RequestID: "1", Success: true}
+
+	mockRepo.On("SendResponse", mock.Anything, "1", response).Return(errors.New("status 503")).Once()
+
+	// A falha no envio não é propagada, pois a resposta foi guardada
+	assert.NoError(t, repository.SendResponse(ctx, "1", response))
+	assert.True(t, s.Has("1"))
+
+	// O reenvio aguarda o backoff inicial
+	repository.Flush(ctx)
+	mockRepo.AssertNumberOfCalls(t, "SendResponse", 1)
+
+	mockRepo.On("SendResponse", mock.Anything, "1", response).Return(nil).Once()
+	now = now.Add(2 * time.Second)
+	repository.Flush(ctx)
+
+	assert.False(t, s.Has("1"))
+	assert.Equal(t, 1, s.Stats().Delivered)
+}
+
+func TestFlush_BacksOffAfterFailures(t *testing.T) {
+	now := time.Now()
+	repository, mockRepo, s := newTestRepository(t, &now)
+	ctx := context.Background()
+
+	s.Put("1", models.AuthResponse{RequestID: "1"})
+	mockRepo.On("SendResponse", mock.Anything, "1", mock.Anything).Return(errors.New("status 503"))
+
+	// Respostas recuperadas do arquivo são reenviadas imediatamente
+	repository.Flush(ctx)
+	mockRepo.AssertNumberOfCalls(t, "SendResponse", 1)
+
+	// Após a primeira falha, a espera dobra para 2s
+	now = now.Add(time.Second)
+	repository.Flush(ctx)
+	mockRepo.AssertNumberOfCalls(t, "SendResponse", 1)
+
+	now = now.Add(time.Second)
+	repository.Flush(ctx)
+	mockRepo.AssertNumberOfCalls(t, "SendResponse", 2)
+}
+
+func TestFlush_DropsExpiredResponses(t *testing.T) {
+	now := time.Now()
+	repository, mockRepo, s := newTestRepository(t, &now)
+
+	s.Put("1", models.AuthResponse{RequestID: "1"})
+
+	now = now.Add(2 * time.Hour)
+	repository.Flush(context.Background())
+
+	mockRepo.AssertNotCalled(t, "SendResponse", mock.Anything, mock.Anything, mock.Anything)
+	assert.Equal(t, spool.Stats{Pending: 0, Delivered: 0, Dropped: 1}, s.Stats())
+}
+
+func TestSendResponse_AcksSpooledResponse(t *testing.T) {
+	now := time.Now()
+	repository, mockRepo, s := newTestRepository(t, &now)
+
+	// Uma nova entrega bem-sucedida da mesma requisição dispensa o reenvio da resposta guardada
+	s.Put("1", models.AuthResponse{RequestID: "1"})
+	mockRepo.On("SendResponse", mock.Anything, "1", mock.Anything).Return(nil)
+
+	assert.NoError(t, repository.SendResponse(context.Background(), "1", models.AuthResponse{RequestID: "1"}))
+	assert.False(t, s.Has("1"))
+}
+
+func TestAck_SpoolsFailedDelivery(t *testing.T) {
+	now := time.Now()
+	repository, mockRepo, s := newTestRepository(t, &now)
+	ctx := context.Background()
+
+	mockRepo.On("Ack", mock.Anything, models.Lease{ID: "lease-1", RequestID: "1"}, mock.Anything).Return(errors.New("status 503"))
+	mockRepo.On("Ack", mock.Anything, models.Lease{ID: "lease-2", RequestID: "2"}, mock.Anything).Return(models.ErrLeaseLost)
+
+	assert.NoError(t, repository.Ack(ctx, models.Lease{ID: "lease-1", RequestID: "1"}, models.AuthResponse{RequestID: "1"}))
+	assert.True(t, s.Has("1"))
+
+	// Com o lease perdido, a requisição passou para outra instância e a resposta não é guardada
+	err := repository.Ack(ctx, models.Lease{ID: "lease-2", RequestID: "2"}, models.AuthResponse{RequestID: "2"})
+	assert.ErrorIs(t, err, models.ErrLeaseLost)
+	assert.False(t, s.Has("2"))
+}
@@ -3,37 +3,168 @@ package apiService
 import (
 	"auth-ad/src/internal/interfaces"
 	"auth-ad/src/internal/models"
+	"auth-ad/src/pkg/configs"
+	"auth-ad/src/pkg/logging"
+	"context"
+	"errors"
+	"sync"
+	"time"
 )
 
+// logger registra a renovação dos leases das requisições
+var logger = logging.Component("apiService")
+
 type ApiService struct {
 	apiRepository interfaces.IApiRepository
+	config        *configs.ApiConfig
+
+	mu     sync.Mutex
+	leases map[string]*heldLease
+}
+
+// heldLease é o lease de uma requisição em processamento, renovado até a resposta ou liberação
+type heldLease struct {
+	lease models.Lease
+	stop  chan struct{}
 }
 
 // NewApiService cria uma nova instância de ApiService.
 // Parâmetros:
 // - apiRepository: uma implementação da interface IApiRepository.
+// - config: configurações da API, com a duração dos leases e o tamanho das reservas.
 // Retorno:
 // - *ApiService: uma nova instância de ApiService.
-func NewApiService(apiRepository interfaces.IApiRepository) *ApiService {
+func NewApiService(apiRepository interfaces.IApiRepository, config *configs.ApiConfig) *ApiService {
 	return &ApiService{
 		apiRepository: apiRepository,
+		config:        config,
+		leases:        make(map[string]*heldLease),
 	}
 }
 
-// GetRequest obtém uma lista de AuthRequest.
+// GetRequest reserva requisições pendentes, permitindo que várias instâncias compartilhem a fila.
+// O lease de cada requisição é renovado em segundo plano até que ela seja respondida por
+// SendResponse ou devolvida por Release.
+// Parâmetros:
+// - ctx: contexto da chamada, usado para cancelamento e prazo.
 // Retorno:
 // - []models.AuthRequest: uma lista de solicitações de autenticação.
 // - error: um erro, se ocorrer.
-func (s *ApiService) GetRequest() ([]models.AuthRequest, error) {
-	return s.apiRepository.GetRequest()
+func (s *ApiService) GetRequest(ctx context.Context) ([]models.AuthRequest, error) {
+	claimed, err := s.apiRepository.Claim(ctx, s.config.ClaimBatchSize, s.config.LeaseTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	requests := make([]models.AuthRequest, 0, len(claimed))
+	for _, claim := range claimed {
+		s.hold(claim.Lease)
+		requests = append(requests, claim.Request)
+	}
+
+	return requests, nil
 }
 
-// SendResponse envia uma resposta de autenticação.
+// SendResponse envia uma resposta de autenticação, encerrando o lease da requisição.
 // Parâmetros:
+// - ctx: contexto da chamada, usado para cancelamento e prazo.
 // - requestID: o ID da solicitação.
 // - response: a resposta de autenticação a ser enviada.
 // Retorno:
+// - error: um erro, se ocorrer; models.ErrLeaseLost caso a requisição tenha passado para outra instância.
+func (s *ApiService) SendResponse(ctx context.Context, requestID string, response models.AuthResponse) error {
+	lease, ok := s.take(requestID)
+	if !ok {
+		return s.apiRepository.SendResponse(ctx, requestID, response)
+	}
+
+	return s.apiRepository.Ack(ctx, lease, response)
+}
+
+// Release devolve uma requisição reservada sem respondê-la, para que seja processada novamente.
+// Parâmetros:
+// - ctx: contexto da chamada, usado para cancelamento e prazo.
+// - requestID: o ID da solicitação.
+// Retorno:
 // - error: um erro, se ocorrer.
-func (s *ApiService) SendResponse(requestID string, response models.AuthResponse) error {
-	return s.apiRepository.SendResponse(requestID, response)
+func (s *ApiService) Release(ctx context.Context, requestID string) error {
+	lease, ok := s.take(requestID)
+	if !ok {
+		return nil
+	}
+
+	return s.apiRepository.Release(ctx, lease)
+}
+
+// hold registra o lease de uma requisição reservada e inicia sua renovação. Um lease anterior
+// da mesma requisição, que expirou e foi reservado novamente, é substituído.
+func (s *ApiService) hold(lease models.Lease) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if previous, ok := s.leases[lease.RequestID]; ok {
+		close(previous.stop)
+	}
+
+	held := &heldLease{lease: lease, stop: make(chan struct{})}
+	s.leases[lease.RequestID] = held
+
+	// Leases locais, de APIs sem suporte a leases, não são renovados
+	if lease.ID != "" {
+		go s.heartbeat(held)
+	}
+}
+
+// take remove o lease de uma requisição, encerrando sua renovação.
+// Retorno: o lease e true caso a requisição tenha sido reservada.
+func (s *ApiService) take(requestID string) (models.Lease, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	held, ok := s.leases[requestID]
+	if !ok {
+		return models.Lease{}, false
+	}
+
+	delete(s.leases, requestID)
+	close(held.stop)
+
+	return held.lease, true
+}
+
+// heartbeat renova o lease a cada terço de sua duração até ser encerrado. Caso o lease seja
+// perdido, a renovação para e a resposta da requisição será rejeitada pela API.
+func (s *ApiService) heartbeat(held *heldLease) {
+	interval := s.config.LeaseTTL / 3
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-held.stop:
+			return
+		case <-ticker.C:
+		}
+
+		s.mu.Lock()
+		lease := held.lease
+		s.mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		renewed, err := s.apiRepository.Heartbeat(ctx, lease, s.config.LeaseTTL)
+		cancel()
+
+		if errors.Is(err, models.ErrLeaseLost) {
+			logger.Warn("Lease perdido, a requisição será respondida por outra instância", logging.AttrRequestID, lease.RequestID)
+			return
+		}
+		if err != nil {
+			logger.Warn("Erro ao renovar o lease", logging.AttrRequestID, lease.RequestID, logging.AttrError, err)
+			continue
+		}
+
+		s.mu.Lock()
+		held.lease = renewed
+		s.mu.Unlock()
+	}
 }
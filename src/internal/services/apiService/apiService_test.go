@@ -1,38 +1,67 @@
 package apiService
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"auth-ad/src/internal/interfaces/mocks"
 	"auth-ad/src/internal/models"
+	"auth-ad/src/pkg/configs"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
+var testConfig = &configs.ApiConfig{LeaseTTL: 30 * time.Second, ClaimBatchSize: 10}
+
 func TestGetRequest(t *testing.T) {
 	mockRepo := new(mocks.IApiRepository)
-	service := NewApiService(mockRepo)
+	service := NewApiService(mockRepo, testConfig)
+	ctx := context.Background()
 
-	expectedRequests := []models.AuthRequest{{}, {}}
-	mockRepo.On("GetRequest").Return(expectedRequests, nil)
+	claimed := []models.ClaimedRequest{
+		{Request: models.AuthRequest{RequestID: "1"}, Lease: models.Lease{RequestID: "1"}},
+		{Request: models.AuthRequest{RequestID: "2"}, Lease: models.Lease{RequestID: "2"}},
+	}
+	mockRepo.On("Claim", ctx, 10, 30*time.Second).Return(claimed, nil)
 
-	requests, err := service.GetRequest()
+	requests, err := service.GetRequest(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []models.AuthRequest{{RequestID: "1"}, {RequestID: "2"}}, requests)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSendResponse_AcksLease(t *testing.T) {
+	mockRepo := new(mocks.IApiRepository)
+	service := NewApiService(mockRepo, testConfig)
+	ctx := context.Background()
+
+	lease := models.Lease{ID: "lease-1", RequestID: "1"}
+	response := models.AuthResponse{RequestID: "1", Success: true}
+	mockRepo.On("Claim", ctx, 10, 30*time.Second).Return([]models.ClaimedRequest{{Request: models.AuthRequest{RequestID: "1"}, Lease: lease}}, nil)
+	mockRepo.On("Ack", ctx, lease, response).Return(nil)
+
+	service.GetRequest(ctx)
+	err := service.SendResponse(ctx, "1", response)
 
 	assert.NoError(t, err)
-	assert.Equal(t, expectedRequests, requests)
 	mockRepo.AssertExpectations(t)
 }
 
 func TestSendResponse(t *testing.T) {
 	mockRepo := new(mocks.IApiRepository)
-	service := NewApiService(mockRepo)
+	service := NewApiService(mockRepo, testConfig)
+	ctx := context.Background()
 
+	// Sem lease, como nos reenvios, a resposta é enviada diretamente
 	requestID := "123"
 	response := models.AuthResponse{}
-	mockRepo.On("SendResponse", requestID, response).Return(nil)
+	mockRepo.On("SendResponse", ctx, requestID, response).Return(nil)
 
-	err := service.SendResponse(requestID, response)
+	err := service.SendResponse(ctx, requestID, response)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
@@ -40,14 +69,60 @@ func TestSendResponse(t *testing.T) {
 
 func TestSendResponse_Error(t *testing.T) {
 	mockRepo := new(mocks.IApiRepository)
-	service := NewApiService(mockRepo)
+	service := NewApiService(mockRepo, testConfig)
+	ctx := context.Background()
 
 	requestID := "123"
 	response := models.AuthResponse{}
-	mockRepo.On("SendResponse", requestID, response).Return(errors.New("some error"))
+	mockRepo.On("SendResponse", ctx, requestID, response).Return(errors.New("some error"))
 
-	err := service.SendResponse(requestID, response)
+	err := service.SendResponse(ctx, requestID, response)
 
 	assert.Error(t, err)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestRelease(t *testing.T) {
+	mockRepo := new(mocks.IApiRepository)
+	service := NewApiService(mockRepo, testConfig)
+	ctx := context.Background()
+
+	lease := models.Lease{ID: "lease-1", RequestID: "1"}
+	mockRepo.On("Claim", ctx, 10, 30*time.Second).Return([]models.ClaimedRequest{{Request: models.AuthRequest{RequestID: "1"}, Lease: lease}}, nil)
+	mockRepo.On("Release", ctx, lease).Return(nil).Once()
+
+	service.GetRequest(ctx)
+	assert.NoError(t, service.Release(ctx, "1"))
+
+	// Requisições já liberadas ou não reservadas não são liberadas novamente
+	assert.NoError(t, service.Release(ctx, "1"))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestHeartbeat_RenewsLeaseUntilAck(t *testing.T) {
+	mockRepo := new(mocks.IApiRepository)
+	service := NewApiService(mockRepo, &configs.ApiConfig{LeaseTTL: 30 * time.Millisecond, ClaimBatchSize: 10})
+	ctx := context.Background()
+
+	lease := models.Lease{ID: "lease-1", RequestID: "1"}
+	renewed := models.Lease{ID: "lease-1", RequestID: "1", ExpiresAt: time.Now().Add(time.Minute)}
+	mockRepo.On("Claim", ctx, 10, 30*time.Millisecond).Return([]models.ClaimedRequest{{Request: models.AuthRequest{RequestID: "1"}, Lease: lease}}, nil)
+	heartbeats := make(chan struct{}, 10)
+	mockRepo.On("Heartbeat", mock.Anything, mock.Anything, 30*time.Millisecond).Run(func(mock.Arguments) {
+		heartbeats <- struct{}{}
+	}).Return(renewed, nil)
+	mockRepo.On("Ack", ctx, renewed, mock.Anything).Return(nil)
+
+	service.GetRequest(ctx)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-heartbeats:
+		case <-time.After(time.Second):
+			t.Fatal("Lease não renovado")
+		}
+	}
+
+	// A resposta usa o lease renovado
+	assert.NoError(t, service.SendResponse(ctx, "1", models.AuthResponse{RequestID: "1"}))
+	mockRepo.AssertCalled(t, "Ack", ctx, renewed, mock.Anything)
+}
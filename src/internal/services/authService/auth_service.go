@@ -3,47 +3,79 @@ package authService
 import (
 	"auth-ad/src/internal/interfaces"
 	"auth-ad/src/internal/models"
+	"auth-ad/src/pkg/requestContext"
+	"auth-ad/src/pkg/throttle"
+	"context"
+	"fmt"
 )
 
 // AuthService fornece métodos para autenticação e recuperação de dados de usuários.
 type AuthService struct {
 	adRepository interfaces.IActiveDirectoryRepository
+	throttle     *throttle.Throttle
 }
 
 // NewAuthService cria uma nova instância de AuthService.
 //
 // Parâmetros:
 //   - adRepository: Interface para o repositório do Active Directory.
+//   - throttle: Limitação das tentativas com falha por usuário e por origem; nil desativa a limitação.
 //
 // Retorna:
 //   - Ponteiro para uma nova instância de AuthService.
-func NewAuthService(adRepository interfaces.IActiveDirectoryRepository) *AuthService {
-	return &AuthService{adRepository: adRepository}
+func NewAuthService(adRepository interfaces.IActiveDirectoryRepository, throttle *throttle.Throttle) *AuthService {
+	return &AuthService{adRepository: adRepository, throttle: throttle}
 }
 
-// Authenticate verifica as credenciais do usuário.
+// Authenticate verifica as credenciais do usuário. Com a limitação de tentativas ativa, usuários,
+// origens (requestContext.Source) e chamadores (requestContext.Caller) bloqueados, ou com falhas
+// recentes cujo atraso ainda não decorreu, são recusados com models.ErrTooManyAttempts sem consulta
+// ao AD e sem ocupar o worker, com a espera sugerida em models.RetryAfter, evitando que tentativas
+// repetidas bloqueiem a conta no AD.
 //
 // Parâmetros:
+//   - ctx: Contexto da chamada, usado para cancelamento e prazo.
 //   - username: Nome de usuário para autenticação.
 //   - password: Senha do usuário.
 //
 // Retorna:
 //   - bool: Verdadeiro se a autenticação for bem-sucedida, falso caso contrário.
 //   - error: Erro, se ocorrer.
-func (s *AuthService) Authenticate(username, password string) (bool, error) {
-	return s.adRepository.Authenticate(username, password)
+func (s *AuthService) Authenticate(ctx context.Context, username, password string) (bool, error) {
+	if s.throttle == nil {
+		return s.adRepository.Authenticate(ctx, username, password)
+	}
+
+	reservation, err := s.throttle.Reserve(username, requestContext.Source(ctx), requestContext.Caller(ctx))
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", models.ErrTooManyAttempts, err)
+	}
+
+	authenticated, err := s.adRepository.Authenticate(ctx, username, password)
+	switch {
+	case err == nil && authenticated:
+		reservation.Success()
+	case err == nil, models.IsRequestError(err):
+		// Toda recusa das credenciais conta como falha, inclusive senha vazia e estado da conta
+		reservation.Failure()
+	default:
+		reservation.Cancel()
+	}
+
+	return authenticated, err
 }
 
 // GetUser recupera os dados de um usuário pelo nome de usuário.
 //
 // Parâmetros:
+//   - ctx: Contexto da chamada, usado para cancelamento e prazo.
 //   - username: Nome de usuário para recuperar os dados.
 //
 // Retorna:
 //   - *models.UserData: Dados do usuário.
 //   - error: Erro, se ocorrer.
-func (s *AuthService) GetUser(username string) (models.UserData, error) {
-	user, err := s.adRepository.GetUser(username)
+func (s *AuthService) GetUser(ctx context.Context, username string) (models.UserData, error) {
+	user, err := s.adRepository.GetUser(ctx, username)
 	if err != nil {
 		return models.UserData{}, err
 	}
@@ -58,13 +90,14 @@ func (s *AuthService) GetUser(username string) (models.UserData, error) {
 // GetUsers recupera os dados de todos os usuários de um grupo.
 //
 // Parâmetros:
+//   - ctx: Contexto da chamada, usado para cancelamento e prazo.
 //   - group: Nome do grupo para recuperar os usuários.
 //
 // Retorna:
 //   - []*models.UserData: Lista de dados dos usuários.
 //   - error: Erro, se ocorrer.
-func (s *AuthService) GetUsers(group string) ([]models.UserData, error) {
-	users, err := s.adRepository.GetUsers(group)
+func (s *AuthService) GetUsers(ctx context.Context, group string) ([]models.UserData, error) {
+	users, err := s.adRepository.GetUsers(ctx, group)
 	if err != nil {
 		return nil, err
 	}
@@ -81,11 +114,26 @@ func (s *AuthService) GetUsers(group string) ([]models.UserData, error) {
 	return userData, nil
 }
 
+// IsMemberOf indica se o usuário pertence ao grupo, direta ou indiretamente, independentemente de
+// AD_GROUP_RESOLUTION.
+//
+// Parâmetros:
+//   - ctx: Contexto da chamada, usado para cancelamento e prazo.
+//   - username: Nome de usuário.
+//   - group: Grupo no formato configurado em AD_GROUP_FORMAT.
+//
+// Retorna:
+//   - bool: Verdadeiro se o usuário pertence ao grupo.
+//   - error: Erro, se ocorrer.
+func (s *AuthService) IsMemberOf(ctx context.Context, username, group string) (bool, error) {
+	return s.adRepository.IsMemberOf(ctx, username, group)
+}
+
 // Unbind remove a vinculação atual da conexão
 // Returns:
 //   - error: Erro em caso de falha no unbind
-func (s *AuthService) Unbind() error {
-	return s.adRepository.Unbind()
+func (s *AuthService) Unbind(ctx context.Context) error {
+	return s.adRepository.Unbind(ctx)
 }
 
 // Close fecha a conexão com o Active Directory.
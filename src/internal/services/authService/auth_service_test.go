@@ -1,28 +1,84 @@
 package authService
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"auth-ad/src/internal/interfaces/mocks"
 	"auth-ad/src/internal/models"
+	"auth-ad/src/pkg/configs"
+	"auth-ad/src/pkg/requestContext"
+	"auth-ad/src/pkg/throttle"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestAuthenticate(t *testing.T) {
 	mockRepo := new(mocks.IActiveDirectoryInterface)
-	service := NewAuthService(mockRepo)
+	service := NewAuthService(mockRepo, nil)
+	ctx := context.Background()
 
-	mockRepo.On("Authenticate", "user", "pass").Return(true, nil)
+	mockRepo.On("Authenticate", ctx, "user", "pass").Return(true, nil)
 
-	authenticated, err := service.Authenticate("user", "pass")
+	authenticated, err := service.Authenticate(ctx, "user", "pass")
 	assert.NoError(t, err)
 	assert.True(t, authenticated)
 }
 
+func TestAuthenticate_Throttle(t *testing.T) {
+	mockRepo := new(mocks.IActiveDirectoryInterface)
+	service := NewAuthService(mockRepo, throttle.NewThrottle(configs.ThrottleConfig{
+		Window:        time.Minute,
+		BaseDelay:     time.Minute,
+		MaxDelay:      time.Minute,
+		BlockDuration: time.Minute,
+		User:          configs.ThrottleLimits{BlockAfter: 3},
+		Source:        configs.ThrottleLimits{DelayAfter: 1},
+	}))
+	ctx := context.Background()
+
+	// Um sucesso zera as falhas do usuário
+	mockRepo.On("Authenticate", ctx, "user", "errada").Return(false, models.ErrInvalidCredentials)
+	mockRepo.On("Authenticate", ctx, "user", "pass").Return(true, nil)
+	for _, password := range []string{"errada", "errada", "pass", "errada", "errada"} {
+		service.Authenticate(ctx, "user", password)
+	}
+	mockRepo.AssertNumberOfCalls(t, "Authenticate", 5)
+
+	// A terceira falha seguida bloqueia o usuário, recusado sem consulta ao AD
+	service.Authenticate(ctx, "user", "errada")
+	authenticated, err := service.Authenticate(ctx, "USER", "pass")
+	assert.False(t, authenticated)
+	assert.ErrorIs(t, err, models.ErrTooManyAttempts)
+	assert.Equal(t, models.ReasonTooManyAttempts, models.ReasonFor(err))
+	assert.True(t, models.IsRequestError(err))
+	mockRepo.AssertNumberOfCalls(t, "Authenticate", 6)
+
+	// Falhas sistêmicas não contam como tentativas
+	sourceCtx := requestContext.WithSource(ctx, "10.0.0.1")
+	mockRepo.On("Authenticate", sourceCtx, "outro", "pass").Return(false, errors.New("timeout")).Once()
+	_, err = service.Authenticate(sourceCtx, "outro", "pass")
+	assert.NotErrorIs(t, err, models.ErrTooManyAttempts)
+
+	// Recusas pelo estado da conta contam como falha, e a tentativa seguinte da origem é recusada de
+	// imediato, sem aguardar o atraso, com a espera sugerida
+	locked := &models.AuthError{Reason: models.ReasonAccountLocked, Err: errors.New("775")}
+	mockRepo.On("Authenticate", sourceCtx, "outro", "errada").Return(false, locked).Once()
+	service.Authenticate(sourceCtx, "outro", "errada")
+	_, err = service.Authenticate(sourceCtx, "terceiro", "pass")
+	assert.ErrorIs(t, err, models.ErrTooManyAttempts)
+	retryAfter, ok := models.RetryAfter(err)
+	assert.True(t, ok)
+	assert.InDelta(t, time.Minute, retryAfter, float64(time.Second))
+	mockRepo.AssertNumberOfCalls(t, "Authenticate", 8)
+}
+
 func TestGetUser(t *testing.T) {
 	mockRepo := new(mocks.IActiveDirectoryInterface)
-	service := NewAuthService(mockRepo)
+	service := NewAuthService(mockRepo, nil)
+	ctx := context.Background()
 
 	mockADUser := &models.ADUser{
 		SAMAccountName: "user",
@@ -30,9 +86,9 @@ func TestGetUser(t *testing.T) {
 		Groups:         []string{"group1"},
 	}
 
-	mockRepo.On("GetUser", "user").Return(mockADUser, nil)
+	mockRepo.On("GetUser", ctx, "user").Return(mockADUser, nil)
 
-	user, err := service.GetUser("user")
+	user, err := service.GetUser(ctx, "user")
 	assert.NoError(t, err)
 	assert.Equal(t, "user", user.Username)
 	assert.Equal(t, "user@example.com", user.Email)
@@ -40,7 +96,8 @@ func TestGetUser(t *testing.T) {
 
 func TestGetUsers(t *testing.T) {
 	mockRepo := new(mocks.IActiveDirectoryInterface)
-	service := NewAuthService(mockRepo)
+	service := NewAuthService(mockRepo, nil)
+	ctx := context.Background()
 
 	mockADUsers := []*models.ADUser{
 		{
@@ -55,9 +112,9 @@ func TestGetUsers(t *testing.T) {
 		},
 	}
 
-	mockRepo.On("GetUsers", "group1").Return(mockADUsers, nil)
+	mockRepo.On("GetUsers", ctx, "group1").Return(mockADUsers, nil)
 
-	users, err := service.GetUsers("group1")
+	users, err := service.GetUsers(ctx, "group1")
 	assert.NoError(t, err)
 	assert.Len(t, users, 2)
 }
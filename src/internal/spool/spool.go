@@ -0,0 +1,368 @@
+package spool
+
+import (
+	"auth-ad/src/internal/models"
+	"auth-ad/src/pkg/logging"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// logger registra as falhas na manutenção do arquivo do spool
+var logger = logging.Component("spool")
+
+// Operações registradas no arquivo do spool
+const (
+	opPut   = "put"   // Resposta guardada para reenvio
+	opAck   = "ack"   // Resposta entregue à API
+	opDrop  = "drop"  // Resposta descartada sem ser entregue
+	opStats = "stats" // Contadores acumulados, gravados na compactação
+)
+
+// compactThreshold é a quantidade de registros obsoletos a partir da qual o arquivo é compactado
+const compactThreshold = 1000
+
+// Entry é uma resposta aguardando entrega à API
+type Entry struct {
+	RequestID string              // ID da requisição respondida
+	Response  models.AuthResponse // Resposta a ser entregue
+	CreatedAt time.Time           // Momento em que a resposta foi guardada pela primeira vez
+}
+
+// Stats reúne os contadores do spool
+type Stats struct {
+	Pending   int // Respostas aguardando entrega
+	Delivered int // Respostas entregues após terem sido guardadas
+	Dropped   int // Respostas descartadas sem serem entregues
+}
+
+// record é uma linha do arquivo do spool
+type record struct {
+	Op        string               `json:"op"`
+	RequestID string               `json:"request_id,omitempty"`
+	Response  *models.AuthResponse `json:"response,omitempty"`
+	Time      time.Time            `json:"time"`
+	Delivered int                  `json:"delivered,omitempty"`
+	Dropped   int                  `json:"dropped,omitempty"`
+}
+
+// Spool guarda em disco as respostas que não puderam ser entregues à API.
+// O arquivo é append-only, com um registro JSON por linha: cada resposta guardada, entregue
+// ou descartada acrescenta um registro, e o estado é reconstruído relendo o arquivo. Há no
+// máximo uma resposta pendente por ID de requisição. A compactação reescreve o arquivo apenas
+// com as respostas pendentes e os contadores.
+type Spool struct {
+	path string
+	now  func() time.Time
+
+	mu       sync.Mutex
+	file     *os.File
+	entries  map[string]*Entry
+	stats    Stats
+	obsolete int
+	// truncated indica que o arquivo termina com uma gravação interrompida, sem quebra de linha
+	truncated bool
+}
+
+// Open abre o spool, criando o arquivo e o diretório caso não existam, e recupera as respostas pendentes
+// Parâmetros:
+//   - path: Caminho do arquivo do spool
+//
+// Retorna:
+//   - *Spool: Spool aberto
+//   - error: Erro em caso de falha ao abrir ou ler o arquivo
+func Open(path string) (*Spool, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("erro ao criar o diretório do spool: %w", err)
+	}
+
+	s := &Spool{path: path, now: time.Now, entries: make(map[string]*Entry)}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir o spool: %w", err)
+	}
+	s.file = file
+
+	return s, nil
+}
+
+// Put guarda uma resposta para reenvio. Uma resposta já pendente para a mesma requisição é
+// substituída, mantendo o momento em que foi guardada pela primeira vez.
+// Parâmetros:
+//   - requestID: ID da requisição respondida
+//   - response: Resposta a ser entregue
+//
+// Retorna:
+//   - error: Erro em caso de falha ao gravar no arquivo
+func (s *Spool) Put(requestID string, response models.AuthResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if err := s.append(record{Op: opPut, RequestID: requestID, Response: &response, Time: now}); err != nil {
+		return err
+	}
+
+	s.apply(requestID, response, now)
+
+	return nil
+}
+
+// Ack registra a entrega de uma resposta pendente. Requisições sem resposta pendente são ignoradas.
+// Parâmetros:
+//   - requestID: ID da requisição respondida
+//
+// Retorna:
+//   - error: Erro em caso de falha ao gravar no arquivo
+func (s *Spool) Ack(requestID string) error {
+	return s.remove(opAck, requestID)
+}
+
+// Drop descarta uma resposta pendente sem entregá-la. Requisições sem resposta pendente são ignoradas.
+// Parâmetros:
+//   - requestID: ID da requisição respondida
+//
+// Retorna:
+//   - error: Erro em caso de falha ao gravar no arquivo
+func (s *Spool) Drop(requestID string) error {
+	return s.remove(opDrop, requestID)
+}
+
+// Has indica se há uma resposta pendente para a requisição
+func (s *Spool) Has(requestID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.entries[requestID]
+	return ok
+}
+
+// Entries retorna as respostas pendentes, da mais antiga para a mais recente
+func (s *Spool) Entries() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := make([]Entry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+
+	return entries
+}
+
+// Stats retorna os contadores do spool
+func (s *Spool) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.stats
+	stats.Pending = len(s.entries)
+	return stats
+}
+
+// Compact reescreve o arquivo apenas com as respostas pendentes e os contadores
+// Retorna:
+//   - error: Erro em caso de falha ao reescrever o arquivo
+func (s *Spool) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.compact()
+}
+
+// Close fecha o arquivo do spool
+// Retorna:
+//   - error: Erro em caso de falha ao fechar o arquivo
+func (s *Spool) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
+
+// remove registra a entrega ou o descarte de uma resposta pendente
+func (s *Spool) remove(op, requestID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[requestID]; !ok {
+		return nil
+	}
+
+	if err := s.append(record{Op: op, RequestID: requestID, Time: s.now()}); err != nil {
+		return err
+	}
+	s.applyRemove(op, requestID)
+
+	if s.obsolete >= compactThreshold && s.obsolete > len(s.entries) {
+		if err := s.compact(); err != nil {
+			logger.Error("Erro ao compactar o spool", logging.AttrError, err)
+		}
+	}
+
+	return nil
+}
+
+// apply registra uma resposta pendente no estado em memória
+func (s *Spool) apply(requestID string, response models.AuthResponse, at time.Time) {
+	if entry, ok := s.entries[requestID]; ok {
+		entry.Response = response
+		s.obsolete++
+		return
+	}
+
+	s.entries[requestID] = &Entry{RequestID: requestID, Response: response, CreatedAt: at}
+}
+
+// applyRemove retira uma resposta pendente do estado em memória, atualizando os contadores
+func (s *Spool) applyRemove(op, requestID string) {
+	if _, ok := s.entries[requestID]; !ok {
+		return
+	}
+
+	delete(s.entries, requestID)
+	s.obsolete += 2
+
+	if op == opAck {
+		s.stats.Delivered++
+	} else {
+		s.stats.Dropped++
+	}
+}
+
+// load reconstrói o estado a partir do arquivo. Linhas inválidas, como a última linha
+// de uma gravação interrompida, são ignoradas.
+func (s *Spool) load() error {
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao ler o spool: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		var r record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			logger.Warn("Registro inválido do spool ignorado", "line", line, logging.AttrError, err)
+			continue
+		}
+
+		switch r.Op {
+		case opPut:
+			if r.Response != nil {
+				s.apply(r.RequestID, *r.Response, r.Time)
+			}
+		case opAck, opDrop:
+			s.applyRemove(r.Op, r.RequestID)
+		case opStats:
+			s.stats.Delivered += r.Delivered
+			s.stats.Dropped += r.Dropped
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("erro ao ler o spool: %w", err)
+	}
+
+	if info, err := file.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, info.Size()-1); err == nil {
+			s.truncated = last[0] != '\n'
+		}
+	}
+
+	return nil
+}
+
+// append grava um registro no final do arquivo, sincronizando-o com o disco
+func (s *Spool) append(r record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar o registro do spool: %w", err)
+	}
+
+	// Uma gravação interrompida é encerrada antes, para não corromper o novo registro
+	if s.truncated {
+		data = append([]byte{'\n'}, data...)
+	}
+
+	if _, err := s.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("erro ao gravar no spool: %w", err)
+	}
+	s.truncated = false
+
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("erro ao sincronizar o spool: %w", err)
+	}
+
+	return nil
+}
+
+// compact grava o estado atual em um arquivo temporário e o troca pelo arquivo do spool
+func (s *Spool) compact() error {
+	tmpPath := s.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("erro ao criar o arquivo de compactação do spool: %w", err)
+	}
+	defer os.Remove(tmpPath)
+
+	writer := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(writer)
+
+	err = encoder.Encode(record{Op: opStats, Time: s.now(), Delivered: s.stats.Delivered, Dropped: s.stats.Dropped})
+	for _, entry := range s.entries {
+		if err != nil {
+			break
+		}
+		response := entry.Response
+		err = encoder.Encode(record{Op: opPut, RequestID: entry.RequestID, Response: &response, Time: entry.CreatedAt})
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao compactar o spool: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("erro ao substituir o arquivo do spool: %w", err)
+	}
+
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("erro ao reabrir o spool: %w", err)
+	}
+
+	if s.file != nil {
+		s.file.Close()
+	}
+	s.file = file
+	s.obsolete = 0
+	s.truncated = false
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package spool
+
+import (
+	"auth-ad/src/internal/models"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// openTestSpool abre um spool em um diretório temporário
+func openTestSpool(t *testing.T) (*Spool, string) {
+	path := filepath.Join(t.TempDir(), "spool", "responses.jsonl")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Erro ao abrir o spool: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	return s, path
+}
+
+// reopen fecha o spool e o abre novamente a partir do arquivo
+func reopen(t *testing.T, s *Spool, path string) *Spool {
+	s.Close()
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Erro ao reabrir o spool: %v", err)
+	}
+	t.Cleanup(func() { reopened.Close() })
+
+	return reopened
+}
+
+func TestSpool_SurvivesRestart(t *testing.T) {
+	s, path := openTestSpool(t)
+
+	s.Put("1", models.AuthResponse{RequestID: "1", Success: true})
+	s.Put("2", models.AuthResponse{RequestID: "2", Reason: models.ReasonInvalidCredentials})
+	s.Put("3", models.AuthResponse{RequestID: "3"})
+	s.Ack("1")
+	s.Drop("3")
+
+	s = reopen(t, s, path)
+
+	entries := s.Entries()
+	if len(entries) != 1 || entries[0].RequestID != "2" || entries[0].Response.Reason != models.ReasonInvalidCredentials {
+		t.Fatalf("Esperada apenas a resposta 2 pendente, obtido %+v", entries)
+	}
+
+	stats := s.Stats()
+	if stats.Pending != 1 || stats.Delivered != 1 || stats.Dropped != 1 {
+		t.Errorf("Contadores incorretos: %+v", stats)
+	}
+}
+
+func TestSpool_DeduplicatesByRequestID(t *testing.T) {
+	s, _ := openTestSpool(t)
+
+	s.Put("1", models.AuthResponse{RequestID: "1", Reason: models.ReasonDirectoryUnavailable})
+	created := s.Entries()[0].CreatedAt
+	s.Put("1", models.AuthResponse{RequestID: "1", Success: true})
+
+	entries := s.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("Esperada 1 resposta pendente, obtido %d", len(entries))
+	}
+	if !entries[0].Response.Success || !entries[0].CreatedAt.Equal(created) {
+		t.Errorf("Esperada a resposta mais recente com o momento original, obtido %+v", entries[0])
+	}
+
+	// Confirmações de requisições sem resposta pendente não alteram os contadores
+	s.Ack("desconhecida")
+	if stats := s.Stats(); stats.Delivered != 0 {
+		t.Errorf("Esperado 0 entregues, obtido %d", stats.Delivered)
+	}
+}
+
+func TestSpool_CompactKeepsState(t *testing.T) {
+	s, path := openTestSpool(t)
+
+	s.Put("1", models.AuthResponse{RequestID: "1"})
+	s.Put("2", models.AuthResponse{RequestID: "2"})
+	s.Ack("1")
+
+	if err := s.Compact(); err != nil {
+		t.Fatalf("Erro ao compactar: %v", err)
+	}
+	s.Put("3", models.AuthResponse{RequestID: "3"})
+
+	s = reopen(t, s, path)
+
+	if stats := s.Stats(); stats.Pending != 2 || stats.Delivered != 1 {
+		t.Errorf("Contadores incorretos após compactação: %+v", stats)
+	}
+}
+
+func TestSpool_IgnoresInterruptedWrite(t *testing.T) {
+	s, path := openTestSpool(t)
+	s.Put("1", models.AuthResponse{RequestID: "1"})
+	s.Close()
+
+	// Simula uma gravação interrompida no meio do registro
+	file, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	file.WriteString(`{"op":"put","request_id":"2","resp`)
+	file.Close()
+
+	s = reopen(t, s, path)
+	s.Put("3", models.AuthResponse{RequestID: "3"})
+
+	s = reopen(t, s, path)
+	if stats := s.Stats(); stats.Pending != 2 || !s.Has("1") || !s.Has("3") {
+		t.Errorf("Esperadas as respostas 1 e 3 pendentes, obtido %+v", s.Entries())
+	}
+}
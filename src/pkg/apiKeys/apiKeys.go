@@ -0,0 +1,45 @@
+package apiKeys
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+)
+
+// KeySet é o conjunto de chaves de acesso aceitas pelas APIs do serviço. As chaves são guardadas
+// como hash e comparadas em tempo constante.
+type KeySet struct {
+	hashes [][sha256.Size]byte
+}
+
+// NewKeySet cria o conjunto de chaves de acesso
+// Parâmetros:
+//   - keys: Chaves aceitas
+//
+// Retorna:
+//   - *KeySet: Conjunto de chaves
+func NewKeySet(keys []string) *KeySet {
+	hashes := make([][sha256.Size]byte, 0, len(keys))
+	for _, key := range keys {
+		hashes = append(hashes, sha256.Sum256([]byte(key)))
+	}
+
+	return &KeySet{hashes: hashes}
+}
+
+// Valid indica se o token corresponde a uma das chaves. Todas as chaves são comparadas, para que
+// o tempo da verificação não revele qual delas foi usada.
+// Parâmetros:
+//   - token: Token apresentado pelo cliente
+//
+// Retorna:
+//   - bool: Verdadeiro caso o token seja uma das chaves
+func (k *KeySet) Valid(token string) bool {
+	hash := sha256.Sum256([]byte(token))
+
+	valid := 0
+	for _, key := range k.hashes {
+		valid |= subtle.ConstantTimeCompare(hash[:], key[:])
+	}
+
+	return valid == 1
+}
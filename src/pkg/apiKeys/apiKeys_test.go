@@ -0,0 +1,23 @@
+package apiKeys
+
+import "testing"
+
+func TestKeySet_Valid(t *testing.T) {
+	keys := NewKeySet([]string{"chave-1", "chave-2"})
+
+	for token, expected := range map[string]bool{
+		"chave-1": true,
+		"chave-2": true,
+		"chave-3": false,
+		"chave":   false,
+		"":        false,
+	} {
+		if keys.Valid(token) != expected {
+			t.Errorf("Valid(%q): esperado %v", token, expected)
+		}
+	}
+
+	if NewKeySet(nil).Valid("") {
+		t.Error("Um conjunto vazio não deve aceitar nenhum token")
+	}
+}
@@ -0,0 +1,175 @@
+package circuitBreaker
+
+import (
+	"auth-ad/src/pkg/configs"
+	"auth-ad/src/pkg/logging"
+	"errors"
+	"sync"
+	"time"
+)
+
+// logger registra as mudanças de estado dos circuitos
+var logger = logging.Component("circuitBreaker")
+
+// ErrOpen é retornado quando o circuito está aberto e a chamada não é executada
+var ErrOpen = errors.New("circuito aberto")
+
+// State representa o estado do circuito
+type State int
+
+const (
+	StateClosed   State = iota // Chamadas liberadas, contando falhas consecutivas
+	StateOpen                  // Chamadas rejeitadas até o fim do tempo de abertura
+	StateHalfOpen              // Chamadas de teste liberadas para verificar a recuperação
+)
+
+// String retorna o nome do estado
+func (s State) String() string {
+	switch s {
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// Result é o resultado de uma chamada liberada por Allow
+type Result int
+
+const (
+	ResultSuccess  Result = iota // A dependência respondeu
+	ResultFailure                // A chamada falhou por causa da dependência
+	ResultReleased               // A chamada terminou sem avaliar a dependência (ex.: cancelada pelo chamador)
+)
+
+// CircuitBreaker interrompe as chamadas a uma dependência após falhas consecutivas.
+// Com o circuito aberto, as chamadas são rejeitadas com ErrOpen; após OpenTimeout, até
+// HalfOpenMaxCalls chamadas de teste são liberadas e, com SuccessThreshold sucessos,
+// o circuito volta a fechar. Uma falha no estado semiaberto reabre o circuito.
+type CircuitBreaker struct {
+	name   string
+	config configs.BreakerConfig
+	now    func() time.Time
+
+	mu        sync.Mutex
+	state     State
+	failures  int
+	successes int
+	probes    int
+	openedAt  time.Time
+}
+
+// NewCircuitBreaker cria um circuit breaker fechado
+// Parâmetros:
+//   - name: Nome da dependência protegida, usado nos logs
+//   - config: Limites do circuito
+//
+// Retorna:
+//   - *CircuitBreaker: Circuit breaker
+func NewCircuitBreaker(name string, config configs.BreakerConfig) *CircuitBreaker {
+	return &CircuitBreaker{name: name, config: config, now: time.Now}
+}
+
+// Allow verifica se uma chamada pode ser executada. Quando liberada, o chamador deve informar o
+// resultado por meio da função retornada; ResultReleased libera a vaga da chamada de teste sem
+// contar sucesso nem falha.
+// Retorna:
+//   - func(result Result): Função que registra o resultado da chamada
+//   - error: ErrOpen caso o circuito esteja aberto
+func (b *CircuitBreaker) Allow() (func(result Result), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.config.OpenTimeout {
+		b.transition(StateHalfOpen)
+	}
+
+	switch b.state {
+	case StateOpen:
+		return nil, ErrOpen
+	case StateHalfOpen:
+		if b.probes >= b.config.HalfOpenMaxCalls {
+			return nil, ErrOpen
+		}
+		b.probes++
+	}
+
+	var once sync.Once
+	return func(result Result) {
+		once.Do(func() { b.record(result) })
+	}, nil
+}
+
+// State retorna o estado atual do circuito
+func (b *CircuitBreaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.config.OpenTimeout {
+		return StateHalfOpen
+	}
+
+	return b.state
+}
+
+// Name retorna o nome da dependência protegida
+func (b *CircuitBreaker) Name() string {
+	return b.name
+}
+
+// record registra o resultado de uma chamada liberada
+func (b *CircuitBreaker) record(result Result) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateClosed:
+		switch result {
+		case ResultReleased:
+			return
+		case ResultSuccess:
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.config.FailureThreshold {
+			b.transition(StateOpen)
+		}
+
+	case StateHalfOpen:
+		b.probes--
+		switch result {
+		case ResultReleased:
+			return
+		case ResultFailure:
+			b.transition(StateOpen)
+			return
+		}
+		b.successes++
+		if b.successes >= b.config.SuccessThreshold {
+			b.transition(StateClosed)
+		}
+
+	case StateOpen:
+		// Resultado de uma chamada liberada antes da abertura do circuito
+	}
+}
+
+// transition altera o estado do circuito, reiniciando os contadores. O chamador deve deter a trava.
+func (b *CircuitBreaker) transition(state State) {
+	if b.state == state {
+		return
+	}
+
+	logger.Warn("Estado do circuit breaker alterado", "breaker", b.name, "from", b.state.String(), "to", state.String())
+
+	b.state = state
+	b.failures = 0
+	b.successes = 0
+	b.probes = 0
+	if state == StateOpen {
+		b.openedAt = b.now()
+	}
+}
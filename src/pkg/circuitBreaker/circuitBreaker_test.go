@@ -0,0 +1,157 @@
+package circuitBreaker
+
+import (
+	"auth-ad/src/pkg/configs"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newTestBreaker cria um circuit breaker com relógio controlado pelo teste
+func newTestBreaker(now *time.Time) *CircuitBreaker {
+	breaker := NewCircuitBreaker("teste", configs.BreakerConfig{
+		FailureThreshold: 3,
+		OpenTimeout:      time.Minute,
+		HalfOpenMaxCalls: 1,
+		SuccessThreshold: 2,
+	})
+	breaker.now = func() time.Time { return *now }
+
+	return breaker
+}
+
+// call executa uma chamada no circuito, registrando o resultado informado
+func call(b *CircuitBreaker, failure bool) error {
+	done, err := b.Allow()
+	if err != nil {
+		return err
+	}
+	if failure {
+		done(ResultFailure)
+	} else {
+		done(ResultSuccess)
+	}
+	return nil
+}
+
+func TestCircuitBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
+	now := time.Now()
+	breaker := newTestBreaker(&now)
+
+	// Um sucesso reinicia a contagem de falhas consecutivas
+	call(breaker, true)
+	call(breaker, true)
+	call(breaker, false)
+	call(breaker, true)
+	call(breaker, true)
+	if breaker.State() != StateClosed {
+		t.Fatalf("Esperado circuito fechado, obtido %s", breaker.State())
+	}
+
+	call(breaker, true)
+	if breaker.State() != StateOpen {
+		t.Fatalf("Esperado circuito aberto, obtido %s", breaker.State())
+	}
+
+	if err := call(breaker, false); !errors.Is(err, ErrOpen) {
+		t.Errorf("Esperado ErrOpen, obtido %v", err)
+	}
+}
+
+func TestCircuitBreaker_RecoversThroughHalfOpen(t *testing.T) {
+	now := time.Now()
+	breaker := newTestBreaker(&now)
+	for i := 0; i < 3; i++ {
+		call(breaker, true)
+	}
+
+	now = now.Add(time.Minute)
+	if breaker.State() != StateHalfOpen {
+		t.Fatalf("Esperado circuito semiaberto, obtido %s", breaker.State())
+	}
+
+	// Apenas uma chamada de teste é liberada por vez
+	done, err := breaker.Allow()
+	if err != nil {
+		t.Fatalf("Esperava chamada de teste liberada: %v", err)
+	}
+	if _, err := breaker.Allow(); !errors.Is(err, ErrOpen) {
+		t.Errorf("Esperado ErrOpen para a segunda chamada de teste, obtido %v", err)
+	}
+	done(ResultSuccess)
+
+	// São necessários dois sucessos para fechar o circuito
+	if breaker.State() != StateHalfOpen {
+		t.Fatalf("Esperado circuito semiaberto, obtido %s", breaker.State())
+	}
+	call(breaker, false)
+	if breaker.State() != StateClosed {
+		t.Fatalf("Esperado circuito fechado, obtido %s", breaker.State())
+	}
+}
+
+func TestCircuitBreaker_ReopensOnProbeFailure(t *testing.T) {
+	now := time.Now()
+	breaker := newTestBreaker(&now)
+	for i := 0; i < 3; i++ {
+		call(breaker, true)
+	}
+
+	now = now.Add(time.Minute)
+	call(breaker, true)
+	if breaker.State() != StateOpen {
+		t.Fatalf("Esperado circuito aberto, obtido %s", breaker.State())
+	}
+
+	// O tempo de abertura recomeça a partir da nova falha
+	now = now.Add(30 * time.Second)
+	if err := call(breaker, false); !errors.Is(err, ErrOpen) {
+		t.Errorf("Esperado ErrOpen, obtido %v", err)
+	}
+}
+
+func TestCircuitBreaker_ReleaseDoesNotCount(t *testing.T) {
+	now := time.Now()
+	breaker := newTestBreaker(&now)
+
+	// No estado fechado, uma chamada liberada não reinicia a contagem de falhas
+	call(breaker, true)
+	call(breaker, true)
+	done, _ := breaker.Allow()
+	done(ResultReleased)
+	call(breaker, true)
+	if breaker.State() != StateOpen {
+		t.Fatalf("Esperado circuito aberto, obtido %s", breaker.State())
+	}
+
+	// No estado semiaberto, a vaga da chamada de teste é liberada sem contar um sucesso
+	now = now.Add(time.Minute)
+	done, err := breaker.Allow()
+	if err != nil {
+		t.Fatalf("Esperava chamada de teste liberada: %v", err)
+	}
+	done(ResultReleased)
+
+	call(breaker, false)
+	if breaker.State() != StateHalfOpen {
+		t.Fatalf("Esperado circuito semiaberto após um único sucesso, obtido %s", breaker.State())
+	}
+	call(breaker, false)
+	if breaker.State() != StateClosed {
+		t.Fatalf("Esperado circuito fechado, obtido %s", breaker.State())
+	}
+}
+
+func TestCircuitBreaker_DoneIsIdempotent(t *testing.T) {
+	now := time.Now()
+	breaker := newTestBreaker(&now)
+
+	done, _ := breaker.Allow()
+	done(ResultFailure)
+	done(ResultFailure)
+	done(ResultFailure)
+
+	if breaker.State() != StateClosed {
+		t.Errorf("Esperado circuito fechado, obtido %s", breaker.State())
+	}
+}
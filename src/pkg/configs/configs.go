@@ -2,21 +2,233 @@ package configs
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// Modos de transporte suportados na conexão com o Active Directory
+const (
+	TransportPlain    = "plain"    // LDAP sem criptografia
+	TransportStartTLS = "starttls" // LDAP com upgrade para TLS via StartTLS
+	TransportLDAPS    = "ldaps"    // LDAP sobre TLS (LDAPS)
+)
+
+// Estratégias de resolução dos grupos do usuário
+const (
+	GroupResolutionDirect      = "direct"      // Apenas grupos diretos (memberOf)
+	GroupResolutionNested      = "nested"      // Grupos diretos e aninhados via regra LDAP_MATCHING_RULE_IN_CHAIN
+	GroupResolutionTokenGroups = "tokengroups" // Grupos de segurança diretos e aninhados via atributo tokenGroups
+)
+
+// Formatos de identificação dos grupos retornados
+const (
+	GroupFormatCN             = "cn"             // Nome comum do grupo
+	GroupFormatDN             = "dn"             // Distinguished name do grupo
+	GroupFormatSAMAccountName = "samaccountname" // sAMAccountName do grupo
+	GroupFormatSID            = "sid"            // SID do grupo (S-1-5-21-...)
+)
+
+// Estratégias de autenticação na API
+const (
+	ApiAuthToken  = "token"  // Bearer token estático, lido de variável de ambiente ou arquivo
+	ApiAuthOAuth2 = "oauth2" // OAuth2 client credentials
+)
+
+// Transportes suportados na comunicação com a fila de requisições
+const (
+	ApiTransportHttp = "http" // Consulta à API Smarket por HTTP
+	ApiTransportNats = "nats" // Consumo de um stream do NATS JetStream
+)
+
+// Exportadores de traces suportados
+const (
+	TracingExporterNone = "none" // Traces desativados
+	TracingExporterOtlp = "otlp" // Envio por OTLP a um coletor OpenTelemetry
+)
+
+// Protocolos do exportador OTLP
+const (
+	OtlpProtocolGrpc = "grpc" // OTLP sobre gRPC (porta padrão 4317)
+	OtlpProtocolHttp = "http" // OTLP sobre HTTP com protobuf (porta padrão 4318)
+)
+
+// Formatos de saída dos logs
+const (
+	LogFormatText = "text" // Linhas chave=valor, mais legíveis no terminal
+	LogFormatJson = "json" // Um objeto JSON por linha, para agregadores de logs
+)
+
 // ADConfig representa as configurações de conexão com o Active Directory
 type ADConfig struct {
-	Server   string // Endereço do servidor AD
-	Port     int    // Porta de conexão
-	Domain   string // Domínio do AD
-	Username string // Nome de usuário para autenticação
-	Password string // Senha para autenticação
-	BaseDN   string // DN base para pesquisas
-	ApiUrl   string // URL da API
+	Server        string   // Endereço do servidor AD
+	Port          int      // Porta de conexão
+	Domain        string   // Domínio do AD
+	Username      string   // Nome de usuário para autenticação
+	Password      string   // Senha para autenticação
+	BaseDN        string   // DN base para pesquisas
+	TransportMode string   // Modo de transporte: plain, starttls ou ldaps
+	TLSCAFile     string   // Caminho do bundle de CAs (PEM) usado para validar o servidor
+	TLSPinnedKeys []string // Fingerprints SHA-256 (hex) das chaves públicas aceitas
+	TLSServerName string   // Nome esperado no certificado do servidor
+	TLSMinVersion string   // Versão mínima do TLS (1.0, 1.1, 1.2 ou 1.3)
+
+	DialTimeout time.Duration // Tempo máximo para abrir uma conexão, incluindo a negociação TLS
+
+	PoolMinSize             int           // Quantidade mínima de conexões mantidas no pool
+	PoolMaxSize             int           // Quantidade máxima de conexões abertas no pool
+	PoolIdleTimeout         time.Duration // Tempo ocioso após o qual uma conexão é descartada
+	PoolHealthCheckInterval time.Duration // Intervalo após o qual uma conexão ociosa é verificada antes do uso
+	PoolAcquireTimeout      time.Duration // Tempo máximo de espera por uma conexão livre
+
+	GroupResolution string // Estratégia de resolução dos grupos: direct, nested ou tokengroups
+	GroupFormat     string // Formato dos grupos retornados: cn, dn, samaccountname ou sid
+
+	GroupMembersLimit int // Quantidade máxima de membros retornados na consulta dos membros de um grupo
+
+	Breaker BreakerConfig // Circuit breaker das operações no AD
+}
+
+// RetryConfig representa a política de novas tentativas de uma operação
+type RetryConfig struct {
+	MaxAttempts    int           // Quantidade máxima de tentativas, incluindo a primeira
+	InitialBackoff time.Duration // Espera antes da segunda tentativa
+	MaxBackoff     time.Duration // Espera máxima entre tentativas
+}
+
+// BreakerConfig representa os limites de um circuit breaker
+type BreakerConfig struct {
+	FailureThreshold int           // Falhas consecutivas que abrem o circuito
+	OpenTimeout      time.Duration // Tempo com o circuito aberto antes de liberar chamadas de teste
+	HalfOpenMaxCalls int           // Chamadas de teste simultâneas permitidas com o circuito semiaberto
+	SuccessThreshold int           // Chamadas de teste bem-sucedidas que fecham o circuito
+}
+
+// ApiConfig representa as configurações de comunicação com a API de autenticação
+type ApiConfig struct {
+	Transport string        // Transporte da fila de requisições: http ou nats
+	Url       string        // URL da API
+	Timeout   time.Duration // Tempo máximo de cada chamada HTTP
+	AuthMode  string        // Estratégia de autenticação: token ou oauth2
+
+	GetRequestRetry   RetryConfig   // Novas tentativas da consulta de requisições
+	SendResponseRetry RetryConfig   // Novas tentativas do envio de respostas
+	Breaker           BreakerConfig // Circuit breaker das chamadas à API
+
+	InstanceID     string        // Identificação desta instância nas reservas de requisições
+	LeaseTTL       time.Duration // Duração de cada lease, renovado enquanto a requisição é processada
+	ClaimBatchSize int           // Quantidade máxima de requisições reservadas por consulta
+
+	LongPollWait time.Duration // Tempo que a API pode segurar uma consulta vazia aguardando requisições; 0 desativa
+
+	Token     string // Bearer token estático
+	TokenFile string // Arquivo com o bearer token, relido quando a API rejeita o token atual
+
+	OAuthTokenUrl      string        // Endpoint de emissão de tokens OAuth2
+	OAuthClientID      string        // Client ID OAuth2
+	OAuthClientSecret  string        // Client secret OAuth2
+	OAuthScopes        []string      // Escopos solicitados
+	OAuthRefreshBefore time.Duration // Antecedência com que o token é renovado antes de expirar
+}
+
+// AuthenticationConfig representa as configurações do processamento das requisições de autenticação
+type AuthenticationConfig struct {
+	Workers        int           // Quantidade de requisições processadas em paralelo
+	QueueSize      int           // Capacidade da fila entre a consulta de requisições e os workers
+	RequestTimeout time.Duration // Prazo para processar e responder cada requisição
+	DrainTimeout   time.Duration // Prazo para concluir as requisições em andamento no encerramento
+	DedupTTL       time.Duration // Tempo em que a resposta de uma requisição é reaproveitada para duplicatas; 0 desativa
+
+	PollMinInterval time.Duration // Intervalo entre consultas enquanto há requisições chegando
+	PollMaxInterval time.Duration // Intervalo máximo entre consultas com a fila da API vazia
+}
+
+// SpoolConfig representa as configurações do spool de respostas não entregues à API
+type SpoolConfig struct {
+	Path           string        // Arquivo do spool
+	MaxAge         time.Duration // Idade após a qual uma resposta não entregue é descartada
+	FlushInterval  time.Duration // Intervalo entre as rodadas de reenvio
+	InitialBackoff time.Duration // Espera antes do primeiro reenvio de uma resposta
+	MaxBackoff     time.Duration // Espera máxima entre reenvios de uma resposta
+}
+
+// NatsConfig representa as configurações do transporte NATS JetStream
+type NatsConfig struct {
+	Url             string        // URL do servidor NATS
+	CredsFile       string        // Arquivo de credenciais (.creds) do NATS
+	Token           string        // Token de autenticação no NATS
+	Stream          string        // Stream com as requisições de autenticação
+	Consumer        string        // Nome do consumer durável, compartilhado pelas instâncias
+	RequestSubject  string        // Subject das requisições consumidas
+	ResponseSubject string        // Subject em que as respostas são publicadas
+	MaxDeliver      int           // Entregas de uma requisição antes de ela ser descartada; -1 para ilimitadas
+	FetchWait       time.Duration // Tempo que uma consulta aguarda a chegada de requisições
+}
+
+// HttpApiConfig representa as configurações da API HTTP síncrona de autenticação
+type HttpApiConfig struct {
+	Addr           string        // Endereço em que o servidor escuta (ex.: :8080); vazio desativa a API
+	ApiKeys        []string      // Chaves aceitas no cabeçalho Authorization: Bearer dos clientes
+	MaxBodyBytes   int64         // Tamanho máximo do corpo das requisições
+	RequestTimeout time.Duration // Prazo para processar cada requisição
+}
+
+// GrpcApiConfig representa as configurações da API gRPC de autenticação
+type GrpcApiConfig struct {
+	Addr           string        // Endereço em que o servidor escuta (ex.: :9090); vazio desativa a API
+	ApiKeys        []string      // Chaves aceitas no metadado authorization: Bearer dos clientes
+	RequestTimeout time.Duration // Prazo para processar cada chamada
+	Reflection     bool          // Ativa o server reflection, para depuração com ferramentas como grpcurl
+}
+
+// ThrottleLimits representa os limites de falhas de autenticação de uma chave (usuário, origem ou chamador)
+// dentro da janela de contagem
+type ThrottleLimits struct {
+	DelayAfter int // Falhas a partir das quais cada nova tentativa é atrasada; 0 desativa os atrasos
+	BlockAfter int // Falhas que bloqueiam temporariamente a chave; 0 desativa o bloqueio
+}
+
+// ThrottleConfig representa as configurações da limitação de tentativas de autenticação com falha
+type ThrottleConfig struct {
+	Enabled       bool          // Ativa a limitação de tentativas
+	Window        time.Duration // Janela deslizante em que as falhas são contadas
+	BaseDelay     time.Duration // Atraso da primeira tentativa após DelayAfter falhas, dobrado a cada nova falha
+	MaxDelay      time.Duration // Atraso máximo de uma tentativa
+	BlockDuration time.Duration // Duração do bloqueio de uma chave
+
+	User   ThrottleLimits // Limites por nome de usuário
+	Source ThrottleLimits // Limites por endereço do usuário final, repassado pelo chamador autenticado
+	Caller ThrottleLimits // Limites por chamador compartilhado (endereço da conexão ou tenant)
+}
+
+// AdminConfig representa as configurações do servidor de administração, com as métricas e as
+// verificações de saúde do serviço
+type AdminConfig struct {
+	Addr             string        // Endereço em que o servidor escuta (ex.: :9100); vazio desativa o servidor
+	ApiKeys          []string      // Chaves exigidas no cabeçalho Authorization: Bearer das operações de bloqueio; vazio não as expõe
+	RequestTimeout   time.Duration // Prazo para atender cada requisição
+	PollStallTimeout time.Duration // Tempo sem consultas concluídas após o qual o laço de consultas é considerado travado
+	CheckTimeout     time.Duration // Prazo de cada verificação de dependência em /readyz
+}
+
+// TracingConfig representa as configurações dos traces OpenTelemetry
+type TracingConfig struct {
+	Exporter    string  // Exportador dos traces: none ou otlp
+	Protocol    string  // Protocolo do exportador OTLP: grpc ou http
+	Endpoint    string  // URL do coletor OTLP; vazio usa OTEL_EXPORTER_OTLP_ENDPOINT ou o padrão do protocolo
+	ServiceName string  // Nome do serviço nos traces
+	SampleRatio float64 // Fração dos traces iniciados pelo serviço que são registrados, de 0 a 1
+}
+
+// LoggingConfig representa as configurações dos logs
+type LoggingConfig struct {
+	Format           string     // Formato de saída: text ou json
+	Level            slog.Level // Nível mínimo registrado: debug, info, warn ou error
+	RedactAttributes []string   // Atributos com dados pessoais (ex.: username, email) mascarados, além das senhas e tokens
 }
 
 // LoadEnv carrega as variáveis de ambiente do arquivo .env
@@ -44,15 +256,726 @@ func GetADConfig() (*ADConfig, error) {
 	username := os.Getenv("AD_USERNAME")
 	password := os.Getenv("AD_PASSWORD")
 	baseDN := os.Getenv("AD_BASE_DN")
-	apiUrl := os.Getenv("API_URL")
+
+	transportMode := strings.ToLower(getEnvDefault("AD_TLS_MODE", TransportPlain))
+	switch transportMode {
+	case TransportPlain, TransportStartTLS, TransportLDAPS:
+	default:
+		return nil, fmt.Errorf("modo de transporte inválido: %s", transportMode)
+	}
+
+	poolMinSize, err := getEnvInt("AD_POOL_MIN_SIZE", 1)
+	if err != nil {
+		return nil, err
+	}
+	poolMaxSize, err := getEnvInt("AD_POOL_MAX_SIZE", 4)
+	if err != nil {
+		return nil, err
+	}
+	if poolMaxSize < 1 || poolMinSize < 0 || poolMinSize > poolMaxSize {
+		return nil, fmt.Errorf("tamanho do pool inválido: mínimo %d, máximo %d", poolMinSize, poolMaxSize)
+	}
+	poolIdleTimeout, err := getEnvDuration("AD_POOL_IDLE_TIMEOUT", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	poolHealthCheckInterval, err := getEnvDuration("AD_POOL_HEALTH_CHECK_INTERVAL", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	poolAcquireTimeout, err := getEnvDuration("AD_POOL_ACQUIRE_TIMEOUT", 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	dialTimeout, err := getEnvDuration("AD_DIAL_TIMEOUT", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if dialTimeout <= 0 {
+		return nil, fmt.Errorf("AD_DIAL_TIMEOUT deve ser maior que zero")
+	}
+
+	groupResolution := strings.ToLower(getEnvDefault("AD_GROUP_RESOLUTION", GroupResolutionNested))
+	switch groupResolution {
+	case GroupResolutionDirect, GroupResolutionNested, GroupResolutionTokenGroups:
+	default:
+		return nil, fmt.Errorf("estratégia de resolução de grupos inválida: %s", groupResolution)
+	}
+
+	groupFormat := strings.ToLower(getEnvDefault("AD_GROUP_FORMAT", GroupFormatCN))
+	switch groupFormat {
+	case GroupFormatCN, GroupFormatDN, GroupFormatSAMAccountName, GroupFormatSID:
+	default:
+		return nil, fmt.Errorf("formato de grupo inválido: %s", groupFormat)
+	}
+
+	groupMembersLimit, err := getEnvInt("AD_GROUP_MEMBERS_LIMIT", 5000)
+	if err != nil {
+		return nil, err
+	}
+	if groupMembersLimit < 1 {
+		return nil, fmt.Errorf("AD_GROUP_MEMBERS_LIMIT deve ser maior que zero")
+	}
+
+	breaker, err := getBreakerConfig("AD_BREAKER")
+	if err != nil {
+		return nil, err
+	}
 
 	return &ADConfig{
-		Server:   server,
-		Port:     port,
-		Domain:   domain,
-		Username: username,
-		Password: password,
-		BaseDN:   baseDN,
-		ApiUrl:   apiUrl,
+		Server:        server,
+		Port:          port,
+		Domain:        domain,
+		Username:      username,
+		Password:      password,
+		BaseDN:        baseDN,
+		TransportMode: transportMode,
+		TLSCAFile:     os.Getenv("AD_TLS_CA_FILE"),
+		TLSPinnedKeys: splitList(os.Getenv("AD_TLS_PINNED_SHA256")),
+		TLSServerName: os.Getenv("AD_TLS_SERVER_NAME"),
+		TLSMinVersion: getEnvDefault("AD_TLS_MIN_VERSION", "1.2"),
+
+		DialTimeout: dialTimeout,
+
+		PoolMinSize:             poolMinSize,
+		PoolMaxSize:             poolMaxSize,
+		PoolIdleTimeout:         poolIdleTimeout,
+		PoolHealthCheckInterval: poolHealthCheckInterval,
+		PoolAcquireTimeout:      poolAcquireTimeout,
+
+		GroupResolution: groupResolution,
+		GroupFormat:     groupFormat,
+
+		GroupMembersLimit: groupMembersLimit,
+
+		Breaker: breaker,
+	}, nil
+}
+
+// GetAuthenticationConfig recupera as configurações do processamento das requisições das variáveis de ambiente
+// Retorna:
+//   - *AuthenticationConfig: estrutura com as configurações carregadas
+//   - error: erro em caso de falha ao converter valores
+func GetAuthenticationConfig() (*AuthenticationConfig, error) {
+	workers, err := getEnvInt("AUTH_WORKERS", 4)
+	if err != nil {
+		return nil, err
+	}
+	if workers < 1 {
+		return nil, fmt.Errorf("quantidade de workers inválida: %d", workers)
+	}
+
+	queueSize, err := getEnvInt("AUTH_QUEUE_SIZE", 100)
+	if err != nil {
+		return nil, err
+	}
+	if queueSize < 1 {
+		return nil, fmt.Errorf("tamanho da fila inválido: %d", queueSize)
+	}
+
+	requestTimeout, err := getEnvDuration("AUTH_REQUEST_TIMEOUT", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	drainTimeout, err := getEnvDuration("AUTH_DRAIN_TIMEOUT", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	dedupTTL, err := getEnvDuration("AUTH_DEDUP_TTL", 10*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	if dedupTTL < 0 {
+		return nil, fmt.Errorf("tempo de deduplicação inválido: %s", dedupTTL)
+	}
+
+	pollMinInterval, err := getEnvDuration("AUTH_POLL_MIN_INTERVAL", 100*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+
+	pollMaxInterval, err := getEnvDuration("AUTH_POLL_MAX_INTERVAL", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if pollMinInterval < 0 || pollMaxInterval < pollMinInterval {
+		return nil, fmt.Errorf("intervalos de consulta inválidos: mínimo %s, máximo %s", pollMinInterval, pollMaxInterval)
+	}
+
+	return &AuthenticationConfig{
+		Workers:         workers,
+		QueueSize:       queueSize,
+		RequestTimeout:  requestTimeout,
+		DrainTimeout:    drainTimeout,
+		DedupTTL:        dedupTTL,
+		PollMinInterval: pollMinInterval,
+		PollMaxInterval: pollMaxInterval,
+	}, nil
+}
+
+// GetApiConfig recupera as configurações da API das variáveis de ambiente
+// Retorna:
+//   - *ApiConfig: estrutura com as configurações carregadas
+//   - error: erro em caso de falha ao converter valores
+func GetApiConfig() (*ApiConfig, error) {
+	timeout, err := getEnvDuration("API_TIMEOUT", 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := strings.ToLower(getEnvDefault("API_TRANSPORT", ApiTransportHttp))
+	switch transport {
+	case ApiTransportHttp, ApiTransportNats:
+	default:
+		return nil, fmt.Errorf("transporte da API inválido: %s", transport)
+	}
+
+	authMode := strings.ToLower(getEnvDefault("API_AUTH_MODE", ApiAuthToken))
+	switch authMode {
+	case ApiAuthToken, ApiAuthOAuth2:
+	default:
+		return nil, fmt.Errorf("estratégia de autenticação da API inválida: %s", authMode)
+	}
+
+	refreshBefore, err := getEnvDuration("API_OAUTH_REFRESH_BEFORE", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	getRequestRetry, err := getRetryConfig("API_GET_RETRY")
+	if err != nil {
+		return nil, err
+	}
+
+	sendResponseRetry, err := getRetryConfig("API_SEND_RETRY")
+	if err != nil {
+		return nil, err
+	}
+
+	breaker, err := getBreakerConfig("API_BREAKER")
+	if err != nil {
+		return nil, err
+	}
+
+	leaseTTL, err := getEnvDuration("API_LEASE_TTL", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if leaseTTL < time.Second {
+		return nil, fmt.Errorf("duração do lease inválida: %s", leaseTTL)
+	}
+
+	claimBatchSize, err := getEnvInt("API_CLAIM_BATCH_SIZE", 10)
+	if err != nil {
+		return nil, err
+	}
+	if claimBatchSize < 1 {
+		return nil, fmt.Errorf("quantidade de requisições por reserva inválida: %d", claimBatchSize)
+	}
+
+	longPollWait, err := getEnvDuration("API_LONG_POLL_WAIT", 0)
+	if err != nil {
+		return nil, err
+	}
+	if longPollWait < 0 {
+		return nil, fmt.Errorf("espera do long polling inválida: %s", longPollWait)
+	}
+
+	hostname, _ := os.Hostname()
+
+	return &ApiConfig{
+		Transport:          transport,
+		Url:                os.Getenv("API_URL"),
+		Timeout:            timeout,
+		AuthMode:           authMode,
+		GetRequestRetry:    getRequestRetry,
+		SendResponseRetry:  sendResponseRetry,
+		Breaker:            breaker,
+		InstanceID:         getEnvDefault("API_INSTANCE_ID", hostname),
+		LeaseTTL:           leaseTTL,
+		ClaimBatchSize:     claimBatchSize,
+		LongPollWait:       longPollWait,
+		Token:              os.Getenv("API_TOKEN"),
+		TokenFile:          os.Getenv("API_TOKEN_FILE"),
+		OAuthTokenUrl:      os.Getenv("API_OAUTH_TOKEN_URL"),
+		OAuthClientID:      os.Getenv("API_OAUTH_CLIENT_ID"),
+		OAuthClientSecret:  os.Getenv("API_OAUTH_CLIENT_SECRET"),
+		OAuthScopes:        splitList(os.Getenv("API_OAUTH_SCOPES")),
+		OAuthRefreshBefore: refreshBefore,
+	}, nil
+}
+
+// GetSpoolConfig recupera as configurações do spool de respostas das variáveis de ambiente
+// Retorna:
+//   - *SpoolConfig: estrutura com as configurações carregadas
+//   - error: erro em caso de falha ao converter valores
+func GetSpoolConfig() (*SpoolConfig, error) {
+	maxAge, err := getEnvDuration("SPOOL_MAX_AGE", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	if maxAge <= 0 {
+		return nil, fmt.Errorf("idade máxima do spool inválida: %s", maxAge)
+	}
+
+	flushInterval, err := getEnvDuration("SPOOL_FLUSH_INTERVAL", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if flushInterval <= 0 {
+		return nil, fmt.Errorf("intervalo de reenvio do spool inválido: %s", flushInterval)
+	}
+
+	initialBackoff, err := getEnvDuration("SPOOL_INITIAL_BACKOFF", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	maxBackoff, err := getEnvDuration("SPOOL_MAX_BACKOFF", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SpoolConfig{
+		Path:           getEnvDefault("SPOOL_PATH", "data/spool.jsonl"),
+		MaxAge:         maxAge,
+		FlushInterval:  flushInterval,
+		InitialBackoff: initialBackoff,
+		MaxBackoff:     maxBackoff,
+	}, nil
+}
+
+// GetNatsConfig recupera as configurações do transporte NATS JetStream das variáveis de ambiente
+// Retorna:
+//   - *NatsConfig: estrutura com as configurações carregadas
+//   - error: erro em caso de falha ao converter valores
+func GetNatsConfig() (*NatsConfig, error) {
+	maxDeliver, err := getEnvInt("NATS_MAX_DELIVER", 5)
+	if err != nil {
+		return nil, err
+	}
+	if maxDeliver == 0 || maxDeliver < -1 {
+		return nil, fmt.Errorf("quantidade máxima de entregas inválida: %d", maxDeliver)
+	}
+
+	fetchWait, err := getEnvDuration("NATS_FETCH_WAIT", time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if fetchWait <= 0 {
+		return nil, fmt.Errorf("espera da consulta ao NATS inválida: %s", fetchWait)
+	}
+
+	return &NatsConfig{
+		Url:             getEnvDefault("NATS_URL", "nats://127.0.0.1:4222"),
+		CredsFile:       os.Getenv("NATS_CREDS_FILE"),
+		Token:           os.Getenv("NATS_TOKEN"),
+		Stream:          getEnvDefault("NATS_STREAM", "AUTH_REQUESTS"),
+		Consumer:        getEnvDefault("NATS_CONSUMER", "auth-ad"),
+		RequestSubject:  getEnvDefault("NATS_REQUEST_SUBJECT", "auth.requests"),
+		ResponseSubject: getEnvDefault("NATS_RESPONSE_SUBJECT", "auth.responses"),
+		MaxDeliver:      maxDeliver,
+		FetchWait:       fetchWait,
+	}, nil
+}
+
+// GetHttpApiConfig recupera as configurações da API HTTP das variáveis de ambiente
+// Retorna:
+//   - *HttpApiConfig: estrutura com as configurações carregadas
+//   - error: erro em caso de falha ao converter valores ou de API ativa sem chaves de acesso
+func GetHttpApiConfig() (*HttpApiConfig, error) {
+	maxBodyBytes, err := getEnvInt("HTTP_MAX_BODY_BYTES", 16*1024)
+	if err != nil {
+		return nil, err
+	}
+	if maxBodyBytes < 1 {
+		return nil, fmt.Errorf("tamanho máximo do corpo inválido: %d", maxBodyBytes)
+	}
+
+	requestTimeout, err := getEnvDuration("HTTP_REQUEST_TIMEOUT", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if requestTimeout <= 0 {
+		return nil, fmt.Errorf("prazo das requisições HTTP inválido: %s", requestTimeout)
+	}
+
+	addr := os.Getenv("HTTP_ADDR")
+	apiKeys := splitList(os.Getenv("HTTP_API_KEYS"))
+	if addr != "" && len(apiKeys) == 0 {
+		return nil, fmt.Errorf("HTTP_API_KEYS deve ser configurada quando a API HTTP está ativa")
+	}
+
+	return &HttpApiConfig{
+		Addr:           addr,
+		ApiKeys:        apiKeys,
+		MaxBodyBytes:   int64(maxBodyBytes),
+		RequestTimeout: requestTimeout,
+	}, nil
+}
+
+// GetGrpcApiConfig recupera as configurações da API gRPC das variáveis de ambiente
+// Retorna:
+//   - *GrpcApiConfig: estrutura com as configurações carregadas
+//   - error: erro em caso de falha ao converter valores ou de API ativa sem chaves de acesso
+func GetGrpcApiConfig() (*GrpcApiConfig, error) {
+	requestTimeout, err := getEnvDuration("GRPC_REQUEST_TIMEOUT", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if requestTimeout <= 0 {
+		return nil, fmt.Errorf("prazo das chamadas gRPC inválido: %s", requestTimeout)
+	}
+
+	reflection, err := getEnvBool("GRPC_REFLECTION", false)
+	if err != nil {
+		return nil, err
+	}
+
+	addr := os.Getenv("GRPC_ADDR")
+	apiKeys := splitList(os.Getenv("GRPC_API_KEYS"))
+	if addr != "" && len(apiKeys) == 0 {
+		return nil, fmt.Errorf("GRPC_API_KEYS deve ser configurada quando a API gRPC está ativa")
+	}
+
+	return &GrpcApiConfig{
+		Addr:           addr,
+		ApiKeys:        apiKeys,
+		RequestTimeout: requestTimeout,
+		Reflection:     reflection,
+	}, nil
+}
+
+// GetAdminConfig recupera as configurações do servidor de administração das variáveis de ambiente
+// Retorna:
+//   - *AdminConfig: estrutura com as configurações carregadas
+//   - error: erro em caso de falha ao converter valores
+func GetAdminConfig() (*AdminConfig, error) {
+	requestTimeout, err := getEnvDuration("ADMIN_REQUEST_TIMEOUT", 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if requestTimeout <= 0 {
+		return nil, fmt.Errorf("prazo das requisições de administração inválido: %s", requestTimeout)
+	}
+
+	pollStallTimeout, err := getEnvDuration("ADMIN_POLL_STALL_TIMEOUT", 2*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	if pollStallTimeout <= 0 {
+		return nil, fmt.Errorf("tempo máximo sem consultas inválido: %s", pollStallTimeout)
+	}
+
+	checkTimeout, err := getEnvDuration("ADMIN_CHECK_TIMEOUT", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if checkTimeout <= 0 {
+		return nil, fmt.Errorf("prazo das verificações de dependências inválido: %s", checkTimeout)
+	}
+
+	return &AdminConfig{
+		Addr:             os.Getenv("ADMIN_ADDR"),
+		ApiKeys:          splitList(os.Getenv("ADMIN_API_KEYS")),
+		RequestTimeout:   requestTimeout,
+		PollStallTimeout: pollStallTimeout,
+		CheckTimeout:     checkTimeout,
+	}, nil
+}
+
+// GetThrottleConfig recupera as configurações da limitação de tentativas das variáveis de ambiente
+// Retorna:
+//   - *ThrottleConfig: estrutura com as configurações carregadas
+//   - error: erro em caso de falha ao converter valores ou de prazos ou limites inválidos
+func GetThrottleConfig() (*ThrottleConfig, error) {
+	enabled, err := getEnvBool("THROTTLE_ENABLED", true)
+	if err != nil {
+		return nil, err
+	}
+
+	window, err := getEnvDuration("THROTTLE_WINDOW", 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	if window <= 0 {
+		return nil, fmt.Errorf("janela de contagem das falhas inválida: %s", window)
+	}
+
+	baseDelay, err := getEnvDuration("THROTTLE_BASE_DELAY", time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	maxDelay, err := getEnvDuration("THROTTLE_MAX_DELAY", 8*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if baseDelay < 0 || maxDelay < baseDelay {
+		return nil, fmt.Errorf("atrasos das tentativas inválidos: inicial %s, máximo %s", baseDelay, maxDelay)
+	}
+
+	blockDuration, err := getEnvDuration("THROTTLE_BLOCK_DURATION", 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	if blockDuration <= 0 {
+		return nil, fmt.Errorf("duração do bloqueio inválida: %s", blockDuration)
+	}
+
+	user, err := getThrottleLimits("THROTTLE_USER", 3, 5)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := getThrottleLimits("THROTTLE_SOURCE", 10, 50)
+	if err != nil {
+		return nil, err
+	}
+
+	// Um chamador compartilhado por muitos usuários não é limitado por padrão, para que as falhas
+	// de poucos usuários não bloqueiem os demais
+	caller, err := getThrottleLimits("THROTTLE_CALLER", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ThrottleConfig{
+		Enabled:       enabled,
+		Window:        window,
+		BaseDelay:     baseDelay,
+		MaxDelay:      maxDelay,
+		BlockDuration: blockDuration,
+		User:          user,
+		Source:        source,
+		Caller:        caller,
+	}, nil
+}
+
+// GetTracingConfig recupera as configurações dos traces das variáveis de ambiente
+// Retorna:
+//   - *TracingConfig: estrutura com as configurações carregadas
+//   - error: erro em caso de falha ao converter valores ou de exportador, protocolo ou amostragem inválidos
+func GetTracingConfig() (*TracingConfig, error) {
+	exporter := strings.ToLower(getEnvDefault("TRACING_EXPORTER", TracingExporterNone))
+	switch exporter {
+	case TracingExporterNone, TracingExporterOtlp:
+	default:
+		return nil, fmt.Errorf("exportador de traces inválido: %s", exporter)
+	}
+
+	protocol := strings.ToLower(getEnvDefault("TRACING_OTLP_PROTOCOL", OtlpProtocolGrpc))
+	switch protocol {
+	case OtlpProtocolGrpc, OtlpProtocolHttp:
+	default:
+		return nil, fmt.Errorf("protocolo OTLP inválido: %s", protocol)
+	}
+
+	sampleRatio, err := getEnvFloat("TRACING_SAMPLE_RATIO", 1)
+	if err != nil {
+		return nil, err
+	}
+	if sampleRatio < 0 || sampleRatio > 1 {
+		return nil, fmt.Errorf("fração de amostragem dos traces inválida: %g", sampleRatio)
+	}
+
+	return &TracingConfig{
+		Exporter:    exporter,
+		Protocol:    protocol,
+		Endpoint:    os.Getenv("TRACING_OTLP_ENDPOINT"),
+		ServiceName: getEnvDefault("TRACING_SERVICE_NAME", "auth-ad"),
+		SampleRatio: sampleRatio,
+	}, nil
+}
+
+// GetLoggingConfig recupera as configurações dos logs das variáveis de ambiente
+// Retorna:
+//   - *LoggingConfig: estrutura com as configurações carregadas
+//   - error: erro em caso de formato ou nível inválidos
+func GetLoggingConfig() (*LoggingConfig, error) {
+	format := strings.ToLower(getEnvDefault("LOG_FORMAT", LogFormatText))
+	switch format {
+	case LogFormatText, LogFormatJson:
+	default:
+		return nil, fmt.Errorf("formato de log inválido: %s", format)
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(getEnvDefault("LOG_LEVEL", "info"))); err != nil {
+		return nil, fmt.Errorf("nível de log inválido: %v", err)
+	}
+
+	return &LoggingConfig{
+		Format:           format,
+		Level:            level,
+		RedactAttributes: splitList(os.Getenv("LOG_REDACT_ATTRIBUTES")),
+	}, nil
+}
+
+// getRetryConfig lê a política de novas tentativas das variáveis <prefix>_MAX_ATTEMPTS,
+// <prefix>_INITIAL_BACKOFF e <prefix>_MAX_BACKOFF
+func getRetryConfig(prefix string) (RetryConfig, error) {
+	maxAttempts, err := getEnvInt(prefix+"_MAX_ATTEMPTS", 3)
+	if err != nil {
+		return RetryConfig{}, err
+	}
+	if maxAttempts < 1 {
+		return RetryConfig{}, fmt.Errorf("quantidade de tentativas inválida em %s_MAX_ATTEMPTS: %d", prefix, maxAttempts)
+	}
+
+	initialBackoff, err := getEnvDuration(prefix+"_INITIAL_BACKOFF", 200*time.Millisecond)
+	if err != nil {
+		return RetryConfig{}, err
+	}
+
+	maxBackoff, err := getEnvDuration(prefix+"_MAX_BACKOFF", 5*time.Second)
+	if err != nil {
+		return RetryConfig{}, err
+	}
+
+	return RetryConfig{
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: initialBackoff,
+		MaxBackoff:     maxBackoff,
+	}, nil
+}
+
+// getBreakerConfig lê os limites de um circuit breaker das variáveis <prefix>_FAILURE_THRESHOLD,
+// <prefix>_OPEN_TIMEOUT, <prefix>_HALF_OPEN_MAX_CALLS e <prefix>_SUCCESS_THRESHOLD
+func getBreakerConfig(prefix string) (BreakerConfig, error) {
+	failureThreshold, err := getEnvInt(prefix+"_FAILURE_THRESHOLD", 5)
+	if err != nil {
+		return BreakerConfig{}, err
+	}
+
+	openTimeout, err := getEnvDuration(prefix+"_OPEN_TIMEOUT", 30*time.Second)
+	if err != nil {
+		return BreakerConfig{}, err
+	}
+
+	halfOpenMaxCalls, err := getEnvInt(prefix+"_HALF_OPEN_MAX_CALLS", 1)
+	if err != nil {
+		return BreakerConfig{}, err
+	}
+
+	successThreshold, err := getEnvInt(prefix+"_SUCCESS_THRESHOLD", 1)
+	if err != nil {
+		return BreakerConfig{}, err
+	}
+
+	if failureThreshold < 1 || halfOpenMaxCalls < 1 || successThreshold < 1 {
+		return BreakerConfig{}, fmt.Errorf("limites do circuit breaker inválidos em %s_*", prefix)
+	}
+
+	return BreakerConfig{
+		FailureThreshold: failureThreshold,
+		OpenTimeout:      openTimeout,
+		HalfOpenMaxCalls: halfOpenMaxCalls,
+		SuccessThreshold: successThreshold,
 	}, nil
 }
+
+// getThrottleLimits lê os limites de falhas de uma chave das variáveis <prefix>_DELAY_AFTER e
+// <prefix>_BLOCK_AFTER
+func getThrottleLimits(prefix string, delayAfter, blockAfter int) (ThrottleLimits, error) {
+	delayAfter, err := getEnvInt(prefix+"_DELAY_AFTER", delayAfter)
+	if err != nil {
+		return ThrottleLimits{}, err
+	}
+
+	blockAfter, err = getEnvInt(prefix+"_BLOCK_AFTER", blockAfter)
+	if err != nil {
+		return ThrottleLimits{}, err
+	}
+
+	if delayAfter < 0 || blockAfter < 0 {
+		return ThrottleLimits{}, fmt.Errorf("limites de falhas inválidos em %s_*", prefix)
+	}
+
+	return ThrottleLimits{DelayAfter: delayAfter, BlockAfter: blockAfter}, nil
+}
+
+// getEnvDefault retorna o valor da variável de ambiente ou o valor padrão quando ela não estiver definida
+func getEnvDefault(key, defaultValue string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	return value
+}
+
+// getEnvInt lê uma variável de ambiente inteira, retornando o valor padrão quando ela não estiver definida
+func getEnvInt(key string, defaultValue int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao converter %s: %v", key, err)
+	}
+
+	return parsed, nil
+}
+
+// getEnvFloat lê uma variável de ambiente decimal, retornando o valor padrão quando ela não estiver definida
+func getEnvFloat(key string, defaultValue float64) (float64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao converter %s: %v", key, err)
+	}
+
+	return parsed, nil
+}
+
+// getEnvBool lê uma variável de ambiente booleana (true, false, 1, 0), retornando o valor padrão quando ela não estiver definida
+func getEnvBool(key string, defaultValue bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("erro ao converter %s: %v", key, err)
+	}
+
+	return parsed, nil
+}
+
+// getEnvDuration lê uma variável de ambiente de duração (ex.: 30s, 5m), retornando o valor padrão quando ela não estiver definida
+func getEnvDuration(key string, defaultValue time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao converter %s: %v", key, err)
+	}
+
+	return parsed, nil
+}
+
+// splitList separa uma lista de valores separados por vírgula, ignorando itens vazios
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
@@ -1,8 +1,10 @@
 package configs
 
 import (
+	"log/slog"
 	"os"
 	"testing"
+	"time"
 )
 
 func TestLoadEnv(t *testing.T) {
@@ -43,7 +45,6 @@ func TestGetADConfig(t *testing.T) {
 	os.Setenv("AD_USERNAME", "admin")
 	os.Setenv("AD_PASSWORD", "senha123")
 	os.Setenv("AD_BASE_DN", "dc=exemplo,dc=com")
-	os.Setenv("API_URL", "https://api-gtw.smarketsolutions.com.br/v1")
 
 	config, err := GetADConfig()
 	if err != nil {
@@ -58,6 +59,30 @@ func TestGetADConfig(t *testing.T) {
 		t.Errorf("Port incorreta, obtido: %d, esperado: %d", config.Port, 389)
 	}
 
+	if config.TransportMode != TransportPlain {
+		t.Errorf("TransportMode incorreto, obtido: %s, esperado: %s", config.TransportMode, TransportPlain)
+	}
+	if config.TLSMinVersion != "1.2" {
+		t.Errorf("TLSMinVersion incorreta, obtido: %s, esperado: %s", config.TLSMinVersion, "1.2")
+	}
+	if config.GroupMembersLimit != 5000 {
+		t.Errorf("GroupMembersLimit incorreto, obtido: %d, esperado: %d", config.GroupMembersLimit, 5000)
+	}
+	if config.DialTimeout != 5*time.Second {
+		t.Errorf("DialTimeout incorreto, obtido: %s, esperado: %s", config.DialTimeout, 5*time.Second)
+	}
+	if config.Breaker != (BreakerConfig{FailureThreshold: 5, OpenTimeout: 30 * time.Second, HalfOpenMaxCalls: 1, SuccessThreshold: 1}) {
+		t.Errorf("Breaker incorreto, obtido: %+v", config.Breaker)
+	}
+
+	// Teste com timeout de conexão inválido
+	os.Setenv("AD_DIAL_TIMEOUT", "0s")
+	_, err = GetADConfig()
+	os.Unsetenv("AD_DIAL_TIMEOUT")
+	if err == nil {
+		t.Error("Esperava erro com AD_DIAL_TIMEOUT igual a zero")
+	}
+
 	// Teste com porta inválida
 	os.Setenv("AD_PORT", "porta_invalida")
 	_, err = GetADConfig()
@@ -65,3 +90,470 @@ func TestGetADConfig(t *testing.T) {
 		t.Error("Esperava erro ao converter porta inválida")
 	}
 }
+
+func TestGetADConfig_TLS(t *testing.T) {
+	os.Setenv("AD_PORT", "636")
+	os.Setenv("AD_TLS_MODE", "LDAPS")
+	os.Setenv("AD_TLS_CA_FILE", "/etc/ssl/ad-ca.pem")
+	os.Setenv("AD_TLS_PINNED_SHA256", "aa:bb, cc:dd ,")
+	os.Setenv("AD_TLS_SERVER_NAME", "dc01.exemplo.com")
+	os.Setenv("AD_TLS_MIN_VERSION", "1.3")
+	defer func() {
+		os.Unsetenv("AD_TLS_MODE")
+		os.Unsetenv("AD_TLS_CA_FILE")
+		os.Unsetenv("AD_TLS_PINNED_SHA256")
+		os.Unsetenv("AD_TLS_SERVER_NAME")
+		os.Unsetenv("AD_TLS_MIN_VERSION")
+	}()
+
+	config, err := GetADConfig()
+	if err != nil {
+		t.Fatalf("Não esperava erro ao obter configurações: %v", err)
+	}
+
+	if config.TransportMode != TransportLDAPS {
+		t.Errorf("TransportMode incorreto, obtido: %s, esperado: %s", config.TransportMode, TransportLDAPS)
+	}
+	if config.TLSCAFile != "/etc/ssl/ad-ca.pem" {
+		t.Errorf("TLSCAFile incorreto, obtido: %s", config.TLSCAFile)
+	}
+	if len(config.TLSPinnedKeys) != 2 || config.TLSPinnedKeys[1] != "cc:dd" {
+		t.Errorf("TLSPinnedKeys incorreto, obtido: %v", config.TLSPinnedKeys)
+	}
+	if config.TLSServerName != "dc01.exemplo.com" {
+		t.Errorf("TLSServerName incorreto, obtido: %s", config.TLSServerName)
+	}
+	if config.TLSMinVersion != "1.3" {
+		t.Errorf("TLSMinVersion incorreta, obtido: %s", config.TLSMinVersion)
+	}
+
+	// Teste com modo de transporte inválido
+	os.Setenv("AD_TLS_MODE", "ssl")
+	_, err = GetADConfig()
+	if err == nil {
+		t.Error("Esperava erro com modo de transporte inválido")
+	}
+}
+
+func TestGetAuthenticationConfig(t *testing.T) {
+	config, err := GetAuthenticationConfig()
+	if err != nil {
+		t.Fatalf("Não esperava erro ao obter configurações: %v", err)
+	}
+	if config.Workers != 4 || config.QueueSize != 100 || config.RequestTimeout != 30*time.Second || config.DrainTimeout != 30*time.Second || config.DedupTTL != 10*time.Minute ||
+		config.PollMinInterval != 100*time.Millisecond || config.PollMaxInterval != 5*time.Second {
+		t.Errorf("Valores padrão incorretos, obtido: %+v", config)
+	}
+
+	os.Setenv("AUTH_WORKERS", "8")
+	os.Setenv("AUTH_QUEUE_SIZE", "20")
+	defer os.Unsetenv("AUTH_WORKERS")
+	defer os.Unsetenv("AUTH_QUEUE_SIZE")
+
+	config, err = GetAuthenticationConfig()
+	if err != nil {
+		t.Fatalf("Não esperava erro ao obter configurações: %v", err)
+	}
+	if config.Workers != 8 || config.QueueSize != 20 {
+		t.Errorf("Valores incorretos, obtido: %+v", config)
+	}
+
+	os.Setenv("AUTH_WORKERS", "0")
+	_, err = GetAuthenticationConfig()
+	if err == nil {
+		t.Error("Esperava erro com quantidade de workers inválida")
+	}
+	os.Unsetenv("AUTH_WORKERS")
+
+	os.Setenv("AUTH_POLL_MIN_INTERVAL", "10s")
+	defer os.Unsetenv("AUTH_POLL_MIN_INTERVAL")
+	_, err = GetAuthenticationConfig()
+	if err == nil {
+		t.Error("Esperava erro com intervalo mínimo maior que o máximo")
+	}
+}
+
+func TestGetSpoolConfig(t *testing.T) {
+	config, err := GetSpoolConfig()
+	if err != nil {
+		t.Fatalf("Não esperava erro ao obter configurações: %v", err)
+	}
+	if config.Path != "data/spool.jsonl" || config.MaxAge != time.Hour || config.FlushInterval != 5*time.Second ||
+		config.InitialBackoff != 5*time.Second || config.MaxBackoff != 5*time.Minute {
+		t.Errorf("Valores padrão incorretos, obtido: %+v", config)
+	}
+
+	os.Setenv("SPOOL_PATH", "/var/lib/auth-ad/spool.jsonl")
+	os.Setenv("SPOOL_MAX_AGE", "24h")
+	defer os.Unsetenv("SPOOL_PATH")
+	defer os.Unsetenv("SPOOL_MAX_AGE")
+
+	config, err = GetSpoolConfig()
+	if err != nil {
+		t.Fatalf("Não esperava erro ao obter configurações: %v", err)
+	}
+	if config.Path != "/var/lib/auth-ad/spool.jsonl" || config.MaxAge != 24*time.Hour {
+		t.Errorf("Valores incorretos, obtido: %+v", config)
+	}
+
+	os.Setenv("SPOOL_MAX_AGE", "0s")
+	if _, err := GetSpoolConfig(); err == nil {
+		t.Error("Esperava erro com idade máxima inválida")
+	}
+}
+
+func TestGetNatsConfig(t *testing.T) {
+	config, err := GetNatsConfig()
+	if err != nil {
+		t.Fatalf("Não esperava erro ao obter configurações: %v", err)
+	}
+	if config.Url != "nats://127.0.0.1:4222" || config.Stream != "AUTH_REQUESTS" || config.Consumer != "auth-ad" ||
+		config.RequestSubject != "auth.requests" || config.ResponseSubject != "auth.responses" ||
+		config.MaxDeliver != 5 || config.FetchWait != time.Second {
+		t.Errorf("Valores padrão incorretos, obtido: %+v", config)
+	}
+
+	os.Setenv("NATS_MAX_DELIVER", "0")
+	defer os.Unsetenv("NATS_MAX_DELIVER")
+	if _, err := GetNatsConfig(); err == nil {
+		t.Error("Esperava erro com quantidade máxima de entregas inválida")
+	}
+}
+
+func TestGetHttpApiConfig(t *testing.T) {
+	config, err := GetHttpApiConfig()
+	if err != nil {
+		t.Fatalf("Não esperava erro ao obter configurações: %v", err)
+	}
+	if config.Addr != "" || config.MaxBodyBytes != 16*1024 || config.RequestTimeout != 30*time.Second {
+		t.Errorf("Valores padrão incorretos, obtido: %+v", config)
+	}
+
+	// A API ativa exige ao menos uma chave de acesso
+	os.Setenv("HTTP_ADDR", ":8080")
+	defer os.Unsetenv("HTTP_ADDR")
+	if _, err := GetHttpApiConfig(); err == nil {
+		t.Error("Esperava erro com a API ativa sem chaves de acesso")
+	}
+
+	os.Setenv("HTTP_API_KEYS", "chave-1, chave-2")
+	defer os.Unsetenv("HTTP_API_KEYS")
+
+	config, err = GetHttpApiConfig()
+	if err != nil {
+		t.Fatalf("Não esperava erro ao obter configurações: %v", err)
+	}
+	if config.Addr != ":8080" || len(config.ApiKeys) != 2 || config.ApiKeys[1] != "chave-2" {
+		t.Errorf("Valores incorretos, obtido: %+v", config)
+	}
+}
+
+func TestGetGrpcApiConfig(t *testing.T) {
+	config, err := GetGrpcApiConfig()
+	if err != nil {
+		t.Fatalf("Não esperava erro ao obter configurações: %v", err)
+	}
+	if config.Addr != "" || config.RequestTimeout != 30*time.Second || config.Reflection {
+		t.Errorf("Valores padrão incorretos, obtido: %+v", config)
+	}
+
+	os.Setenv("GRPC_ADDR", ":9090")
+	defer os.Unsetenv("GRPC_ADDR")
+	if _, err := GetGrpcApiConfig(); err == nil {
+		t.Error("Esperava erro com a API ativa sem chaves de acesso")
+	}
+
+	os.Setenv("GRPC_API_KEYS", "chave-1")
+	os.Setenv("GRPC_REFLECTION", "true")
+	defer os.Unsetenv("GRPC_API_KEYS")
+	defer os.Unsetenv("GRPC_REFLECTION")
+
+	config, err = GetGrpcApiConfig()
+	if err != nil {
+		t.Fatalf("Não esperava erro ao obter configurações: %v", err)
+	}
+	if config.Addr != ":9090" || len(config.ApiKeys) != 1 || !config.Reflection {
+		t.Errorf("Valores incorretos, obtido: %+v", config)
+	}
+
+	os.Setenv("GRPC_REFLECTION", "talvez")
+	if _, err := GetGrpcApiConfig(); err == nil {
+		t.Error("Esperava erro com GRPC_REFLECTION inválida")
+	}
+}
+
+func TestGetAdminConfig(t *testing.T) {
+	config, err := GetAdminConfig()
+	if err != nil {
+		t.Fatalf("Não esperava erro ao obter configurações: %v", err)
+	}
+	if config.Addr != "" || config.RequestTimeout != 10*time.Second || config.PollStallTimeout != 2*time.Minute || config.CheckTimeout != 5*time.Second {
+		t.Errorf("Valores padrão incorretos, obtido: %+v", config)
+	}
+
+	os.Setenv("ADMIN_ADDR", ":9100")
+	os.Setenv("ADMIN_API_KEYS", "chave1")
+	defer os.Unsetenv("ADMIN_ADDR")
+	defer os.Unsetenv("ADMIN_API_KEYS")
+	defer os.Unsetenv("ADMIN_REQUEST_TIMEOUT")
+	defer os.Unsetenv("ADMIN_POLL_STALL_TIMEOUT")
+	defer os.Unsetenv("ADMIN_CHECK_TIMEOUT")
+	for _, key := range []string{"ADMIN_REQUEST_TIMEOUT", "ADMIN_POLL_STALL_TIMEOUT", "ADMIN_CHECK_TIMEOUT"} {
+		os.Setenv(key, "0s")
+		if _, err := GetAdminConfig(); err == nil {
+			t.Errorf("Esperava erro com %s inválido", key)
+		}
+		os.Unsetenv(key)
+	}
+
+	os.Setenv("ADMIN_REQUEST_TIMEOUT", "5s")
+	os.Setenv("ADMIN_POLL_STALL_TIMEOUT", "5m")
+	os.Setenv("ADMIN_CHECK_TIMEOUT", "2s")
+	config, err = GetAdminConfig()
+	if err != nil {
+		t.Fatalf("Não esperava erro ao obter configurações: %v", err)
+	}
+	if config.Addr != ":9100" || config.RequestTimeout != 5*time.Second || config.PollStallTimeout != 5*time.Minute || config.CheckTimeout != 2*time.Second {
+		t.Errorf("Valores incorretos, obtido: %+v", config)
+	}
+	if len(config.ApiKeys) != 1 || config.ApiKeys[0] != "chave1" {
+		t.Errorf("Chaves de acesso incorretas, obtido: %v", config.ApiKeys)
+	}
+}
+
+func TestGetThrottleConfig(t *testing.T) {
+	config, err := GetThrottleConfig()
+	if err != nil {
+		t.Fatalf("Não esperava erro ao obter configurações: %v", err)
+	}
+	expected := ThrottleConfig{
+		Enabled:       true,
+		Window:        15 * time.Minute,
+		BaseDelay:     time.Second,
+		MaxDelay:      8 * time.Second,
+		BlockDuration: 15 * time.Minute,
+		User:          ThrottleLimits{DelayAfter: 3, BlockAfter: 5},
+		Source:        ThrottleLimits{DelayAfter: 10, BlockAfter: 50},
+	}
+	if *config != expected {
+		t.Errorf("Valores padrão incorretos, obtido: %+v", config)
+	}
+
+	os.Setenv("THROTTLE_ENABLED", "false")
+	os.Setenv("THROTTLE_WINDOW", "5m")
+	os.Setenv("THROTTLE_USER_BLOCK_AFTER", "0")
+	os.Setenv("THROTTLE_SOURCE_DELAY_AFTER", "20")
+	os.Setenv("THROTTLE_CALLER_BLOCK_AFTER", "1000")
+	defer os.Unsetenv("THROTTLE_ENABLED")
+	defer os.Unsetenv("THROTTLE_WINDOW")
+	defer os.Unsetenv("THROTTLE_USER_BLOCK_AFTER")
+	defer os.Unsetenv("THROTTLE_SOURCE_DELAY_AFTER")
+	defer os.Unsetenv("THROTTLE_CALLER_BLOCK_AFTER")
+
+	config, err = GetThrottleConfig()
+	if err != nil {
+		t.Fatalf("Não esperava erro ao obter configurações: %v", err)
+	}
+	if config.Enabled || config.Window != 5*time.Minute || config.User.BlockAfter != 0 || config.Source.DelayAfter != 20 || config.Caller.BlockAfter != 1000 {
+		t.Errorf("Valores incorretos, obtido: %+v", config)
+	}
+
+	invalid := map[string]string{
+		"THROTTLE_WINDOW":           "0s",
+		"THROTTLE_MAX_DELAY":        "500ms",
+		"THROTTLE_BLOCK_DURATION":   "0s",
+		"THROTTLE_USER_DELAY_AFTER": "-1",
+	}
+	for key, value := range invalid {
+		previous := os.Getenv(key)
+		os.Setenv(key, value)
+		if _, err := GetThrottleConfig(); err == nil {
+			t.Errorf("Esperava erro com %s=%s", key, value)
+		}
+		os.Setenv(key, previous)
+	}
+}
+
+func TestGetTracingConfig(t *testing.T) {
+	config, err := GetTracingConfig()
+	if err != nil {
+		t.Fatalf("Não esperava erro ao obter configurações: %v", err)
+	}
+	if config.Exporter != TracingExporterNone || config.Protocol != OtlpProtocolGrpc || config.ServiceName != "auth-ad" || config.SampleRatio != 1 {
+		t.Errorf("Valores padrão incorretos, obtido: %+v", config)
+	}
+
+	os.Setenv("TRACING_EXPORTER", "OTLP")
+	os.Setenv("TRACING_OTLP_PROTOCOL", "http")
+	os.Setenv("TRACING_OTLP_ENDPOINT", "http://collector:4318")
+	os.Setenv("TRACING_SAMPLE_RATIO", "0.25")
+	defer os.Unsetenv("TRACING_EXPORTER")
+	defer os.Unsetenv("TRACING_OTLP_PROTOCOL")
+	defer os.Unsetenv("TRACING_OTLP_ENDPOINT")
+	defer os.Unsetenv("TRACING_SAMPLE_RATIO")
+
+	config, err = GetTracingConfig()
+	if err != nil {
+		t.Fatalf("Não esperava erro ao obter configurações: %v", err)
+	}
+	if config.Exporter != TracingExporterOtlp || config.Protocol != OtlpProtocolHttp || config.Endpoint != "http://collector:4318" || config.SampleRatio != 0.25 {
+		t.Errorf("Valores incorretos, obtido: %+v", config)
+	}
+
+	invalid := map[string]string{
+		"TRACING_EXPORTER":      "jaeger",
+		"TRACING_OTLP_PROTOCOL": "udp",
+		"TRACING_SAMPLE_RATIO":  "1.5",
+	}
+	for key, value := range invalid {
+		previous := os.Getenv(key)
+		os.Setenv(key, value)
+		if _, err := GetTracingConfig(); err == nil {
+			t.Errorf("Esperava erro com %s=%s", key, value)
+		}
+		os.Setenv(key, previous)
+	}
+}
+
+func TestGetLoggingConfig(t *testing.T) {
+	config, err := GetLoggingConfig()
+	if err != nil {
+		t.Fatalf("Não esperava erro ao obter configurações: %v", err)
+	}
+	if config.Format != LogFormatText || config.Level != slog.LevelInfo || len(config.RedactAttributes) != 0 {
+		t.Errorf("Valores padrão incorretos, obtido: %+v", config)
+	}
+
+	os.Setenv("LOG_FORMAT", "JSON")
+	os.Setenv("LOG_LEVEL", "debug")
+	os.Setenv("LOG_REDACT_ATTRIBUTES", "username, email")
+	defer os.Unsetenv("LOG_FORMAT")
+	defer os.Unsetenv("LOG_LEVEL")
+	defer os.Unsetenv("LOG_REDACT_ATTRIBUTES")
+
+	config, err = GetLoggingConfig()
+	if err != nil {
+		t.Fatalf("Não esperava erro ao obter configurações: %v", err)
+	}
+	if config.Format != LogFormatJson || config.Level != slog.LevelDebug || len(config.RedactAttributes) != 2 || config.RedactAttributes[1] != "email" {
+		t.Errorf("Valores incorretos, obtido: %+v", config)
+	}
+
+	invalid := map[string]string{
+		"LOG_FORMAT": "xml",
+		"LOG_LEVEL":  "verbose",
+	}
+	for key, value := range invalid {
+		previous := os.Getenv(key)
+		os.Setenv(key, value)
+		if _, err := GetLoggingConfig(); err == nil {
+			t.Errorf("Esperava erro com %s=%s", key, value)
+		}
+		os.Setenv(key, previous)
+	}
+}
+
+func TestGetApiConfig(t *testing.T) {
+	os.Setenv("API_URL", "https://api-gtw.smarketsolutions.com.br/v1")
+	defer os.Unsetenv("API_URL")
+
+	config, err := GetApiConfig()
+	if err != nil {
+		t.Fatalf("Não esperava erro ao obter configurações: %v", err)
+	}
+	if config.Url != "https://api-gtw.smarketsolutions.com.br/v1" {
+		t.Errorf("Url incorreta, obtido: %s", config.Url)
+	}
+	if config.Timeout != 10*time.Second {
+		t.Errorf("Timeout incorreto, obtido: %s", config.Timeout)
+	}
+	if config.AuthMode != ApiAuthToken {
+		t.Errorf("AuthMode incorreto, obtido: %s, esperado: %s", config.AuthMode, ApiAuthToken)
+	}
+	if config.Transport != ApiTransportHttp {
+		t.Errorf("Transport incorreto, obtido: %s, esperado: %s", config.Transport, ApiTransportHttp)
+	}
+	if config.GetRequestRetry != (RetryConfig{MaxAttempts: 3, InitialBackoff: 200 * time.Millisecond, MaxBackoff: 5 * time.Second}) {
+		t.Errorf("GetRequestRetry incorreto, obtido: %+v", config.GetRequestRetry)
+	}
+	if hostname, _ := os.Hostname(); config.InstanceID != hostname || config.LeaseTTL != 30*time.Second || config.ClaimBatchSize != 10 || config.LongPollWait != 0 {
+		t.Errorf("Configuração de leases incorreta, obtido: %s %s %d", config.InstanceID, config.LeaseTTL, config.ClaimBatchSize)
+	}
+
+	os.Setenv("API_SEND_RETRY_MAX_ATTEMPTS", "5")
+	os.Setenv("API_SEND_RETRY_MAX_BACKOFF", "1m")
+	defer os.Unsetenv("API_SEND_RETRY_MAX_ATTEMPTS")
+	defer os.Unsetenv("API_SEND_RETRY_MAX_BACKOFF")
+
+	config, err = GetApiConfig()
+	if err != nil {
+		t.Fatalf("Não esperava erro ao obter configurações: %v", err)
+	}
+	if config.SendResponseRetry.MaxAttempts != 5 || config.SendResponseRetry.MaxBackoff != time.Minute {
+		t.Errorf("SendResponseRetry incorreto, obtido: %+v", config.SendResponseRetry)
+	}
+	if config.GetRequestRetry.MaxAttempts != 3 {
+		t.Errorf("GetRequestRetry não deveria ser afetado, obtido: %+v", config.GetRequestRetry)
+	}
+
+	os.Setenv("API_AUTH_MODE", "OAuth2")
+	os.Setenv("API_OAUTH_SCOPES", "auth.read, auth.write")
+	defer os.Unsetenv("API_AUTH_MODE")
+	defer os.Unsetenv("API_OAUTH_SCOPES")
+
+	config, err = GetApiConfig()
+	if err != nil {
+		t.Fatalf("Não esperava erro ao obter configurações: %v", err)
+	}
+	if config.AuthMode != ApiAuthOAuth2 {
+		t.Errorf("AuthMode incorreto, obtido: %s, esperado: %s", config.AuthMode, ApiAuthOAuth2)
+	}
+	if len(config.OAuthScopes) != 2 || config.OAuthScopes[1] != "auth.write" {
+		t.Errorf("OAuthScopes incorreto, obtido: %v", config.OAuthScopes)
+	}
+	if config.OAuthRefreshBefore != time.Minute {
+		t.Errorf("OAuthRefreshBefore incorreto, obtido: %s", config.OAuthRefreshBefore)
+	}
+
+	os.Setenv("API_AUTH_MODE", "basic")
+	_, err = GetApiConfig()
+	if err == nil {
+		t.Error("Esperava erro com estratégia de autenticação inválida")
+	}
+	os.Unsetenv("API_AUTH_MODE")
+
+	os.Setenv("API_TRANSPORT", "kafka")
+	_, err = GetApiConfig()
+	if err == nil {
+		t.Error("Esperava erro com transporte inválido")
+	}
+	os.Unsetenv("API_TRANSPORT")
+
+	os.Setenv("API_TIMEOUT", "dez segundos")
+	defer os.Unsetenv("API_TIMEOUT")
+	_, err = GetApiConfig()
+	if err == nil {
+		t.Error("Esperava erro ao converter timeout inválido")
+	}
+}
+
+func TestGetBreakerConfig(t *testing.T) {
+	os.Setenv("TEST_BREAKER_FAILURE_THRESHOLD", "3")
+	os.Setenv("TEST_BREAKER_OPEN_TIMEOUT", "1m")
+	defer os.Unsetenv("TEST_BREAKER_FAILURE_THRESHOLD")
+	defer os.Unsetenv("TEST_BREAKER_OPEN_TIMEOUT")
+
+	config, err := getBreakerConfig("TEST_BREAKER")
+	if err != nil {
+		t.Fatalf("Não esperava erro ao obter configurações: %v", err)
+	}
+	if config.FailureThreshold != 3 || config.OpenTimeout != time.Minute || config.HalfOpenMaxCalls != 1 {
+		t.Errorf("Valores incorretos, obtido: %+v", config)
+	}
+
+	os.Setenv("TEST_BREAKER_FAILURE_THRESHOLD", "0")
+	_, err = getBreakerConfig("TEST_BREAKER")
+	if err == nil {
+		t.Error("Esperava erro com limite de falhas inválido")
+	}
+}
@@ -0,0 +1,97 @@
+package ldapFilter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Filter representa um filtro LDAP (RFC 4515) com todos os valores já escapados
+type Filter string
+
+// String retorna o filtro no formato textual usado nas buscas
+func (f Filter) String() string {
+	return string(f)
+}
+
+// Escape escapa um valor para uso seguro em filtros LDAP, conforme a RFC 4515.
+// Os caracteres *, (, ), \ e NUL são convertidos para a forma \xx.
+// Parâmetros:
+//   - value: Valor a ser escapado
+//
+// Retorna:
+//   - string: Valor escapado
+func Escape(value string) string {
+	var escaped strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '*', '(', ')', '\\', 0:
+			fmt.Fprintf(&escaped, "\\%02x", c)
+		default:
+			escaped.WriteByte(c)
+		}
+	}
+
+	return escaped.String()
+}
+
+// EscapeBytes escapa todos os bytes de um valor binário (ex.: objectSid) na forma \xx
+// Parâmetros:
+//   - value: Valor binário
+//
+// Retorna:
+//   - string: Valor escapado
+func EscapeBytes(value []byte) string {
+	var escaped strings.Builder
+	for _, b := range value {
+		fmt.Fprintf(&escaped, "\\%02x", b)
+	}
+
+	return escaped.String()
+}
+
+// Equal cria o filtro (atributo=valor), escapando o valor
+func Equal(attribute, value string) Filter {
+	return Filter(fmt.Sprintf("(%s=%s)", attribute, Escape(value)))
+}
+
+// EqualBytes cria o filtro (atributo=valor) para valores binários
+func EqualBytes(attribute string, value []byte) Filter {
+	return Filter(fmt.Sprintf("(%s=%s)", attribute, EscapeBytes(value)))
+}
+
+// Present cria o filtro (atributo=*), que testa a presença do atributo
+func Present(attribute string) Filter {
+	return Filter(fmt.Sprintf("(%s=*)", attribute))
+}
+
+// Extensible cria o filtro de correspondência extensível (atributo:regra:=valor), escapando o valor
+func Extensible(attribute, matchingRule, value string) Filter {
+	return Filter(fmt.Sprintf("(%s:%s:=%s)", attribute, matchingRule, Escape(value)))
+}
+
+// And combina os filtros com o operador &
+func And(filters ...Filter) Filter {
+	return compose("&", filters)
+}
+
+// Or combina os filtros com o operador |
+func Or(filters ...Filter) Filter {
+	return compose("|", filters)
+}
+
+// Not nega o filtro com o operador !
+func Not(filter Filter) Filter {
+	return Filter("(!" + string(filter) + ")")
+}
+
+// compose monta um filtro composto com o operador informado
+func compose(operator string, filters []Filter) Filter {
+	var composed strings.Builder
+	composed.WriteString("(" + operator)
+	for _, filter := range filters {
+		composed.WriteString(string(filter))
+	}
+	composed.WriteString(")")
+
+	return Filter(composed.String())
+}
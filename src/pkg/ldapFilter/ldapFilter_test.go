@@ -0,0 +1,45 @@
+package ldapFilter
+
+import (
+	"testing"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscape(t *testing.T) {
+	assert.Equal(t, `\2a\29\28objectClass=\2a`, Escape("*)(objectClass=*"))
+	assert.Equal(t, `C:\5cUsers`, Escape(`C:\Users`))
+	assert.Equal(t, `a\00b`, Escape("a\x00b"))
+	assert.Equal(t, "joão.silva", Escape("joão.silva"))
+}
+
+func TestEscapeBytes(t *testing.T) {
+	assert.Equal(t, `\01\0a\ff`, EscapeBytes([]byte{0x01, 0x0a, 0xff}))
+}
+
+func TestCompose(t *testing.T) {
+	filter := And(
+		Equal("objectClass", "user"),
+		Or(Equal("sAMAccountName", "jdoe"), Equal("mail", "jdoe@example.com")),
+		Not(Present("lockoutTime")),
+		Extensible("memberOf", "1.2.840.113556.1.4.1941", "CN=Vendas (SP),DC=example,DC=com"),
+	)
+
+	assert.Equal(t,
+		`(&(objectClass=user)(|(sAMAccountName=jdoe)(mail=jdoe@example.com))(!(lockoutTime=*))(memberOf:1.2.840.113556.1.4.1941:=CN=Vendas \28SP\29,DC=example,DC=com))`,
+		filter.String(),
+	)
+}
+
+func TestInjectionIsNeutralized(t *testing.T) {
+	filter := And(Equal("objectClass", "user"), Equal("sAMAccountName", "*)(objectClass=*"))
+
+	compiled, err := ldap.CompileFilter(filter.String())
+	assert.NoError(t, err)
+
+	decompiled, err := ldap.DecompileFilter(compiled)
+	assert.NoError(t, err)
+	assert.Equal(t, filter.String(), decompiled)
+	assert.Equal(t, `(&(objectClass=user)(sAMAccountName=\2a\29\28objectClass=\2a))`, filter.String())
+}
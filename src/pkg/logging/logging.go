@@ -0,0 +1,151 @@
+package logging
+
+import (
+	"auth-ad/src/pkg/configs"
+	"auth-ad/src/pkg/requestContext"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Atributos comuns dos logs do serviço
+const (
+	AttrComponent = "component"  // Componente que registrou o log
+	AttrRequestID = "request_id" // ID da requisição de autenticação, lido do contexto
+	AttrTenant    = "tenant"     // Tenant da requisição, lido do contexto
+	AttrTraceID   = "trace_id"   // ID do trace OpenTelemetry, lido do contexto
+	AttrSpanID    = "span_id"    // ID do span OpenTelemetry, lido do contexto
+	AttrError     = "error"      // Erro da operação
+)
+
+// Setup configura o logger padrão do serviço conforme config, com saída em stderr. A partir
+// daqui, as mensagens do pacote log também passam pelo logger, com a mesma máscara de dados
+// sensíveis.
+// Parâmetros:
+//   - config: Configurações dos logs
+//
+// Retorna:
+//   - *slog.Logger: Logger configurado, também definido como padrão
+func Setup(config *configs.LoggingConfig) *slog.Logger {
+	logger := NewLogger(os.Stderr, config)
+	slog.SetDefault(logger)
+
+	return logger
+}
+
+// NewLogger cria um logger que escreve em w no formato e nível de config. Os registros recebem o
+// ID da requisição, o tenant e o trace guardados no contexto, e os valores de senhas, tokens e
+// dos atributos de config.RedactAttributes são mascarados.
+// Parâmetros:
+//   - w: Destino dos logs
+//   - config: Configurações dos logs
+//
+// Retorna:
+//   - *slog.Logger: Logger criado
+func NewLogger(w io.Writer, config *configs.LoggingConfig) *slog.Logger {
+	options := &slog.HandlerOptions{
+		Level:       config.Level,
+		ReplaceAttr: newRedactor(config.RedactAttributes).replaceAttr,
+	}
+
+	var handler slog.Handler
+	if config.Format == configs.LogFormatJson {
+		handler = slog.NewJSONHandler(w, options)
+	} else {
+		handler = slog.NewTextHandler(w, options)
+	}
+
+	return slog.New(&contextHandler{next: handler})
+}
+
+// Component retorna o logger de um componente do serviço, que identifica a origem dos registros
+// no atributo component. O logger usa o logger padrão do momento de cada registro, e por isso
+// pode ser guardado em variáveis de pacote criadas antes de Setup.
+// Parâmetros:
+//   - name: Nome do componente (ex.: authentication, natsGateway)
+//
+// Retorna:
+//   - *slog.Logger: Logger do componente
+func Component(name string) *slog.Logger {
+	return slog.New(&defaultHandler{}).With(AttrComponent, name)
+}
+
+// contextHandler acrescenta aos registros os dados de correlação guardados no contexto
+type contextHandler struct {
+	next slog.Handler
+}
+
+func (h *contextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := requestContext.RequestID(ctx); requestID != "" {
+		record.AddAttrs(slog.String(AttrRequestID, requestID))
+	}
+	if tenant := requestContext.Tenant(ctx); tenant != "" {
+		record.AddAttrs(slog.String(AttrTenant, tenant))
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		record.AddAttrs(
+			slog.String(AttrTraceID, spanContext.TraceID().String()),
+			slog.String(AttrSpanID, spanContext.SpanID().String()),
+		)
+	}
+
+	return h.next.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{next: h.next.WithGroup(name)}
+}
+
+// defaultHandler repassa os registros ao handler do logger padrão do momento, reaplicando os
+// atributos e grupos acrescentados com With e WithGroup
+type defaultHandler struct {
+	wrap func(slog.Handler) slog.Handler
+}
+
+// current retorna o handler padrão com os atributos e grupos acrescentados
+func (h *defaultHandler) current() slog.Handler {
+	handler := slog.Default().Handler()
+	if h.wrap != nil {
+		handler = h.wrap(handler)
+	}
+
+	return handler
+}
+
+func (h *defaultHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return slog.Default().Handler().Enabled(ctx, level)
+}
+
+func (h *defaultHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.current().Handle(ctx, record)
+}
+
+func (h *defaultHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.chain(func(handler slog.Handler) slog.Handler { return handler.WithAttrs(attrs) })
+}
+
+func (h *defaultHandler) WithGroup(name string) slog.Handler {
+	return h.chain(func(handler slog.Handler) slog.Handler { return handler.WithGroup(name) })
+}
+
+// chain retorna uma cópia do handler que aplica next após as transformações já registradas
+func (h *defaultHandler) chain(next func(slog.Handler) slog.Handler) slog.Handler {
+	previous := h.wrap
+	return &defaultHandler{wrap: func(handler slog.Handler) slog.Handler {
+		if previous != nil {
+			handler = previous(handler)
+		}
+		return next(handler)
+	}}
+}
@@ -0,0 +1,154 @@
+package logging
+
+import (
+	"auth-ad/src/internal/models"
+	"auth-ad/src/pkg/configs"
+	"auth-ad/src/pkg/requestContext"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newTestLogger cria um logger JSON que escreve no buffer retornado
+func newTestLogger(config *configs.LoggingConfig) (*slog.Logger, *bytes.Buffer) {
+	var buffer bytes.Buffer
+	config.Format = configs.LogFormatJson
+
+	return NewLogger(&buffer, config), &buffer
+}
+
+// decode lê o registro JSON gravado no buffer
+func decode(t *testing.T, buffer *bytes.Buffer) map[string]any {
+	var record map[string]any
+	if err := json.Unmarshal(buffer.Bytes(), &record); err != nil {
+		t.Fatalf("Registro JSON inválido %q: %v", buffer.String(), err)
+	}
+
+	return record
+}
+
+func TestNewLogger_RedactsCredentials(t *testing.T) {
+	logger, buffer := newTestLogger(&configs.LoggingConfig{})
+
+	request := models.AuthRequest{RequestID: "req-1", Username: "jdoe", Password: "s3nh4-secreta", Tenant: "loja"}
+	logger.Info("Autenticando com Bearer abc.def.ghi",
+		"request", request,
+		"password", "s3nh4-secreta",
+		"api_key", "chave",
+		"clientSecret", "segredo",
+		slog.Group("credentials", slog.String("value", "oculto")),
+		"error", errors.New(`resposta {"access_token":"tok123"} de https://api/token?token=tok456`),
+	)
+
+	output := buffer.String()
+	for _, secret := range []string{"s3nh4-secreta", "abc.def.ghi", "chave", "segredo", "oculto", "tok123", "tok456"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("Valor sensível %q presente no log: %s", secret, output)
+		}
+	}
+
+	record := decode(t, buffer)
+	if record["msg"] != "Autenticando com Bearer [REDACTED]" {
+		t.Errorf("Mensagem incorreta: %v", record["msg"])
+	}
+	if record["password"] != Redacted {
+		t.Errorf("Senha não mascarada: %v", record["password"])
+	}
+
+	// A requisição aparece sem a senha, com os demais campos
+	logged, ok := record["request"].(map[string]any)
+	if !ok || logged["request_id"] != "req-1" || logged["username"] != "jdoe" || logged["password"] != nil {
+		t.Errorf("Requisição registrada incorretamente: %v", record["request"])
+	}
+}
+
+func TestNewLogger_RedactsConfiguredAttributes(t *testing.T) {
+	logger, buffer := newTestLogger(&configs.LoggingConfig{RedactAttributes: []string{"username", "E-mail"}})
+
+	request := models.AuthRequest{RequestID: "req-1", Username: "jdoe"}
+	logger.Info("Usuário autenticado", "request", request, "email", "jdoe@example.com", "dn", "CN=jdoe")
+
+	record := decode(t, buffer)
+	if record["email"] != Redacted {
+		t.Errorf("Atributo configurado não mascarado: %v", record["email"])
+	}
+	if record["dn"] != "CN=jdoe" {
+		t.Errorf("Atributo não configurado alterado: %v", record["dn"])
+	}
+	if logged := record["request"].(map[string]any); logged["username"] != Redacted || logged["request_id"] != "req-1" {
+		t.Errorf("Atributo configurado não mascarado dentro do grupo: %v", logged)
+	}
+}
+
+func TestNewLogger_ContextCorrelation(t *testing.T) {
+	logger, buffer := newTestLogger(&configs.LoggingConfig{})
+
+	ctx := requestContext.WithTenant(requestContext.WithRequestID(context.Background(), "req-1"), "loja")
+	ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	}))
+	logger.InfoContext(ctx, "Requisição processada")
+
+	record := decode(t, buffer)
+	if record[AttrRequestID] != "req-1" || record[AttrTenant] != "loja" {
+		t.Errorf("Dados da requisição ausentes: %v", record)
+	}
+	if record[AttrTraceID] != "01000000000000000000000000000000" || record[AttrSpanID] != "0200000000000000" {
+		t.Errorf("Dados do trace ausentes: %v", record)
+	}
+
+	// Sem dados no contexto, os atributos não são incluídos
+	buffer.Reset()
+	logger.InfoContext(context.Background(), "Sem requisição")
+	if _, ok := decode(t, buffer)[AttrRequestID]; ok {
+		t.Error("Não esperava request_id sem requisição no contexto")
+	}
+}
+
+func TestNewLogger_Level(t *testing.T) {
+	logger, buffer := newTestLogger(&configs.LoggingConfig{Level: slog.LevelWarn})
+
+	logger.Info("ignorado")
+	if buffer.Len() != 0 {
+		t.Errorf("Não esperava registro abaixo do nível configurado: %s", buffer.String())
+	}
+
+	logger.Warn("registrado")
+	if decode(t, buffer)["level"] != "WARN" {
+		t.Errorf("Nível incorreto: %s", buffer.String())
+	}
+}
+
+func TestComponent(t *testing.T) {
+	// O logger do componente é criado antes da configuração, como nas variáveis de pacote
+	component := Component("authentication").With("worker", 1)
+
+	// SetDefault também redireciona o pacote log, restaurado ao final
+	previous, writer, flags := slog.Default(), log.Writer(), log.Flags()
+	defer func() {
+		slog.SetDefault(previous)
+		log.SetOutput(writer)
+		log.SetFlags(flags)
+	}()
+
+	logger, buffer := newTestLogger(&configs.LoggingConfig{})
+	slog.SetDefault(logger)
+
+	component.Info("Requisição com password=123")
+
+	record := decode(t, buffer)
+	if record[AttrComponent] != "authentication" || record["worker"] != float64(1) {
+		t.Errorf("Atributos do componente ausentes: %v", record)
+	}
+	if record["msg"] != "Requisição com password=[REDACTED]" {
+		t.Errorf("Mensagem não mascarada: %v", record["msg"])
+	}
+}
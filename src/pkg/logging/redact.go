@@ -0,0 +1,99 @@
+package logging
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// Redacted substitui os valores mascarados nos logs
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys são os trechos de nomes de atributos que sempre indicam credenciais, comparados
+// sem diferenciar maiúsculas e ignorando separadores (api_key, apiKey e api-key são equivalentes)
+var sensitiveKeys = []string{"password", "passwd", "senha", "secret", "token", "authorization", "apikey", "credential", "cookie"}
+
+// Padrões de credenciais dentro de textos livres, como mensagens e erros
+var (
+	// authSchemePattern encontra credenciais de cabeçalhos Authorization (Bearer <token>, Basic <base64>)
+	authSchemePattern = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[^\s"',;]+`)
+	// keyValuePattern encontra pares chave=valor e "chave": "valor" de credenciais, como em query strings e corpos JSON
+	keyValuePattern = regexp.MustCompile(`(?i)((?:password|passwd|senha|secret|token|api_?key)["']?\s*[=:]\s*["']?)[^\s"'&,;]+`)
+)
+
+// redactor mascara os valores de credenciais e dos atributos com dados pessoais configurados
+type redactor struct {
+	attributes map[string]bool // Nomes normalizados dos atributos com dados pessoais
+}
+
+// newRedactor cria o redactor com os atributos de dados pessoais informados, além das credenciais
+func newRedactor(attributes []string) *redactor {
+	r := &redactor{attributes: make(map[string]bool, len(attributes))}
+	for _, attribute := range attributes {
+		r.attributes[normalizeKey(attribute)] = true
+	}
+
+	return r
+}
+
+// replaceAttr é o slog.HandlerOptions.ReplaceAttr dos handlers do serviço. Os valores já chegam
+// resolvidos: os tipos com LogValue, como models.AuthRequest, têm cada campo verificado em separado.
+func (r *redactor) replaceAttr(groups []string, attr slog.Attr) slog.Attr {
+	if len(groups) == 0 && (attr.Key == slog.TimeKey || attr.Key == slog.LevelKey || attr.Key == slog.SourceKey) {
+		return attr
+	}
+
+	if r.sensitive(attr.Key) {
+		return slog.String(attr.Key, Redacted)
+	}
+	for _, group := range groups {
+		if r.sensitive(group) {
+			return slog.String(attr.Key, Redacted)
+		}
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, RedactText(attr.Value.String()))
+	case slog.KindAny:
+		// Erros costumam carregar URLs e respostas da API, que podem conter tokens
+		if err, ok := attr.Value.Any().(error); ok {
+			return slog.String(attr.Key, RedactText(err.Error()))
+		}
+	}
+
+	return attr
+}
+
+// sensitive indica se o atributo deve ter o valor mascarado
+func (r *redactor) sensitive(key string) bool {
+	normalized := normalizeKey(key)
+	if r.attributes[normalized] {
+		return true
+	}
+
+	for _, sensitiveKey := range sensitiveKeys {
+		if strings.Contains(normalized, sensitiveKey) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RedactText mascara as credenciais encontradas em um texto livre, como tokens Bearer e pares
+// password=valor
+// Parâmetros:
+//   - text: Texto a ser verificado
+//
+// Retorna:
+//   - string: Texto com as credenciais substituídas por [REDACTED]
+func RedactText(text string) string {
+	text = authSchemePattern.ReplaceAllString(text, "${1} "+Redacted)
+	return keyValuePattern.ReplaceAllString(text, "${1}"+Redacted)
+}
+
+// normalizeKey converte o nome do atributo para minúsculas, sem separadores
+func normalizeKey(key string) string {
+	return strings.NewReplacer("_", "", "-", "", ".", "").Replace(strings.ToLower(key))
+}
@@ -0,0 +1,83 @@
+package requestContext
+
+import "context"
+
+// contextKey é o tipo das chaves usadas no contexto, evitando colisão com outros pacotes
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	tenantKey
+	sourceKey
+	callerKey
+)
+
+// WithRequestID retorna uma cópia do contexto com o ID da requisição de autenticação
+// Parâmetros:
+//   - ctx: Contexto de origem
+//   - requestID: ID da requisição
+//
+// Retorna:
+//   - context.Context: Contexto com o ID da requisição
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID retorna o ID da requisição guardado no contexto, ou vazio quando não houver
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// WithTenant retorna uma cópia do contexto com o tenant da requisição de autenticação
+// Parâmetros:
+//   - ctx: Contexto de origem
+//   - tenant: Identificador do tenant
+//
+// Retorna:
+//   - context.Context: Contexto com o tenant
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey, tenant)
+}
+
+// Tenant retorna o tenant guardado no contexto, ou vazio quando não houver
+func Tenant(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey).(string)
+	return tenant
+}
+
+// WithSource retorna uma cópia do contexto com o endereço do usuário final, repassado pelo chamador
+// autenticado e usado como origem na limitação de tentativas.
+// Parâmetros:
+//   - ctx: Contexto de origem
+//   - source: Endereço do usuário final
+//
+// Retorna:
+//   - context.Context: Contexto com a origem
+func WithSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, sourceKey, source)
+}
+
+// Source retorna a origem guardada no contexto, ou vazio quando não houver
+func Source(ctx context.Context) string {
+	source, _ := ctx.Value(sourceKey).(string)
+	return source
+}
+
+// WithCaller retorna uma cópia do contexto com o chamador que encaminhou a tentativa de autenticação,
+// identificado pelo servidor e compartilhado por todos os usuários de uma aplicação ou proxy.
+// Parâmetros:
+//   - ctx: Contexto de origem
+//   - caller: Chamador da tentativa (ex.: endereço da conexão ou tenant)
+//
+// Retorna:
+//   - context.Context: Contexto com o chamador
+func WithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerKey, caller)
+}
+
+// Caller retorna o chamador guardado no contexto, ou vazio quando não houver
+func Caller(ctx context.Context) string {
+	caller, _ := ctx.Value(callerKey).(string)
+	return caller
+}
@@ -0,0 +1,47 @@
+package requestContext
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRequestValues(t *testing.T) {
+	ctx := context.Background()
+	if RequestID(ctx) != "" || Tenant(ctx) != "" || Source(ctx) != "" || Caller(ctx) != "" {
+		t.Error("Esperava valores vazios em contexto sem dados da requisição")
+	}
+
+	ctx = WithRequestID(ctx, "123")
+	ctx = WithTenant(ctx, "loja-01")
+	ctx = WithSource(ctx, "10.0.0.1")
+	ctx = WithCaller(ctx, "tenant:loja-01")
+
+	if RequestID(ctx) != "123" {
+		t.Errorf("RequestID incorreto, obtido: %s", RequestID(ctx))
+	}
+	if Tenant(ctx) != "loja-01" {
+		t.Errorf("Tenant incorreto, obtido: %s", Tenant(ctx))
+	}
+	if Source(ctx) != "10.0.0.1" {
+		t.Errorf("Origem incorreta, obtida: %s", Source(ctx))
+	}
+	if Caller(ctx) != "tenant:loja-01" {
+		t.Errorf("Chamador incorreto, obtido: %s", Caller(ctx))
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	if !ValidRequestID("req-123") {
+		t.Error("Esperava ID válido")
+	}
+	for _, id := range []string{"", "req 123", "req\n123", strings.Repeat("a", MaxRequestIDLength+1)} {
+		if ValidRequestID(id) {
+			t.Errorf("Esperava ID inválido: %q", id)
+		}
+	}
+
+	if id := NewRequestID(); len(id) != 32 || !ValidRequestID(id) {
+		t.Errorf("ID gerado inválido: %q", id)
+	}
+}
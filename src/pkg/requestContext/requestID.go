@@ -0,0 +1,38 @@
+package requestContext
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// MaxRequestIDLength é o tamanho máximo aceito para um ID de requisição informado pelo cliente
+const MaxRequestIDLength = 128
+
+// NewRequestID gera um ID aleatório para requisições recebidas sem ID
+func NewRequestID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
+
+// ValidRequestID indica se um ID de requisição informado pelo cliente pode ser usado. IDs vazios,
+// longos demais ou com caracteres fora do ASCII visível são recusados, pois vão para o log.
+// Parâmetros:
+//   - requestID: ID informado pelo cliente
+//
+// Retorna:
+//   - bool: Verdadeiro caso o ID possa ser usado
+func ValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > MaxRequestIDLength {
+		return false
+	}
+
+	for _, c := range []byte(requestID) {
+		if c <= ' ' || c > '~' {
+			return false
+		}
+	}
+
+	return true
+}
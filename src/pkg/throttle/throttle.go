@@ -0,0 +1,381 @@
+package throttle
+
+import (
+	"auth-ad/src/pkg/configs"
+	"auth-ad/src/pkg/logging"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// logger registra os bloqueios e desbloqueios
+var logger = logging.Component("throttle")
+
+// maxDoublings limita as falhas guardadas além de DelayAfter: mais duplicações não alteram o atraso
+// de nenhuma configuração razoável
+const maxDoublings = 30
+
+// Scope identifica o tipo de chave em que as falhas são contadas
+type Scope string
+
+const (
+	ScopeUser   Scope = "user"   // Nome de usuário, sem diferenciar maiúsculas e minúsculas
+	ScopeSource Scope = "source" // Endereço do usuário final, repassado pelo chamador autenticado
+	ScopeCaller Scope = "caller" // Chamador compartilhado por vários usuários (ex.: endereço do proxy ou tenant)
+)
+
+// attr retorna o nome do atributo de log com o valor da chave, para que o nome de usuário possa ser
+// mascarado por LOG_REDACT_ATTRIBUTES como nos demais logs
+func (s Scope) attr() string {
+	if s == ScopeUser {
+		return "username"
+	}
+
+	return string(s)
+}
+
+// LimitedError indica que a tentativa foi recusada sem consulta ao AD: por um bloqueio ativo, pelo
+// atraso progressivo ainda não decorrido desde a última falha ou por tentativas em andamento que
+// já bastam para o bloqueio
+type LimitedError struct {
+	Scope   Scope
+	Until   time.Time // Momento a partir do qual uma nova tentativa pode ser aceita
+	Blocked bool      // Indica um bloqueio ativo, e não apenas um atraso
+}
+
+// Error retorna a descrição do erro
+func (e *LimitedError) Error() string {
+	if e.Blocked {
+		return fmt.Sprintf("%s bloqueado até %s", e.Scope, e.Until.Format(time.RFC3339))
+	}
+
+	return fmt.Sprintf("%s deve aguardar até %s", e.Scope, e.Until.Format(time.RFC3339))
+}
+
+// RetryAfter retorna a espera sugerida antes de uma nova tentativa
+func (e *LimitedError) RetryAfter() time.Duration {
+	return max(time.Until(e.Until), 0)
+}
+
+// Block é um bloqueio ativo, exibido pela administração
+type Block struct {
+	Scope Scope
+	Key   string
+	Until time.Time
+}
+
+// key identifica as falhas de um usuário, de uma origem ou de um chamador
+type key struct {
+	scope Scope
+	value string
+}
+
+// entry guarda as falhas recentes de uma chave, da mais antiga para a mais recente, as tentativas
+// reservadas e ainda sem resultado e o fim do bloqueio, quando houver
+type entry struct {
+	failures     []time.Time
+	pending      int
+	lastAttempt  time.Time
+	blockedUntil time.Time
+}
+
+// Throttle limita as tentativas de autenticação com falha por usuário, por origem e por chamador,
+// cada um com seus próprios limites: o chamador é compartilhado pelos usuários de uma aplicação ou
+// proxy, e precisa de limites bem maiores que os de uma origem, ou nenhum. As falhas são
+// contadas em uma janela deslizante: a partir de DelayAfter falhas cada nova tentativa só é aceita
+// após um atraso desde a tentativa anterior, que dobra a cada falha até MaxDelay, e com BlockAfter
+// falhas a chave é bloqueada por BlockDuration. Um bloqueio zera as falhas da chave, que volta a
+// ser contada do início ao expirar. As tentativas em andamento contam como falhas até o resultado,
+// para que tentativas simultâneas não ultrapassem os limites.
+type Throttle struct {
+	config configs.ThrottleConfig
+	now    func() time.Time
+
+	mu        sync.Mutex
+	entries   map[key]*entry
+	lastSweep time.Time
+}
+
+// NewThrottle cria a limitação de tentativas, sem falhas registradas
+// Parâmetros:
+//   - config: Janela, atrasos e limites por usuário, por origem e por chamador
+//
+// Retorna:
+//   - *Throttle: Limitação de tentativas
+func NewThrottle(config configs.ThrottleConfig) *Throttle {
+	return &Throttle{config: config, now: time.Now, entries: make(map[key]*entry)}
+}
+
+// Reservation é uma tentativa aceita por Reserve, que conta como falha até que seu resultado seja
+// informado por Success, Failure ou Cancel. Apenas o primeiro resultado informado é considerado.
+type Reservation struct {
+	throttle *Throttle
+	keys     []key
+	done     bool
+}
+
+// Reserve verifica se uma tentativa pode ser feita e, caso possa, a reserva de forma atômica: a
+// tentativa passa a contar nos limites do usuário, da origem e do chamador até que seu resultado
+// seja informado. Uma origem ou um chamador vazios não são limitados.
+// Parâmetros:
+//   - username: Nome de usuário da tentativa
+//   - source: Endereço do usuário final, repassado pelo chamador
+//   - caller: Chamador que encaminhou a tentativa (ex.: endereço da conexão ou tenant)
+//
+// Retorna:
+//   - *Reservation: Tentativa reservada, cujo resultado deve ser sempre informado
+//   - error: *LimitedError caso o usuário, a origem ou o chamador estejam bloqueados ou devam aguardar
+func (t *Throttle) Reserve(username, source, caller string) (*Reservation, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.sweep(now)
+
+	attemptKeys := keys(username, source, caller)
+	for _, k := range attemptKeys {
+		e, ok := t.entries[k]
+		if !ok {
+			continue
+		}
+		if now.Before(e.blockedUntil) {
+			return nil, &LimitedError{Scope: k.scope, Until: e.blockedUntil, Blocked: true}
+		}
+
+		e.prune(now.Add(-t.config.Window))
+		limits := t.limits(k.scope)
+		attempts := len(e.failures) + e.pending
+
+		if limits.BlockAfter > 0 && attempts >= limits.BlockAfter {
+			return nil, &LimitedError{Scope: k.scope, Until: now.Add(max(t.config.BaseDelay, time.Second))}
+		}
+		if until := e.lastAttempt.Add(t.delay(limits, attempts)); now.Before(until) {
+			return nil, &LimitedError{Scope: k.scope, Until: until}
+		}
+	}
+
+	for _, k := range attemptKeys {
+		limits := t.limits(k.scope)
+		if limits.DelayAfter == 0 && limits.BlockAfter == 0 {
+			continue
+		}
+
+		e, ok := t.entries[k]
+		if !ok {
+			e = &entry{}
+			t.entries[k] = e
+		}
+		e.pending++
+		e.lastAttempt = now
+	}
+
+	return &Reservation{throttle: t, keys: attemptKeys}, nil
+}
+
+// Success informa que a tentativa foi bem-sucedida, zerando as falhas do usuário. As falhas da
+// origem e do chamador são mantidas, já que ambos podem testar senhas de vários usuários.
+func (r *Reservation) Success() {
+	r.finish(func(t *Throttle, k key, e *entry, now time.Time) {
+		if k.scope == ScopeUser && !now.Before(e.blockedUntil) {
+			e.failures = nil
+			e.lastAttempt = time.Time{}
+		}
+	})
+}
+
+// Failure informa que a tentativa foi recusada pelo AD, bloqueando o usuário, a origem ou o
+// chamador que atingirem o limite
+func (r *Reservation) Failure() {
+	r.finish(func(t *Throttle, k key, e *entry, now time.Time) {
+		t.failure(k, e, now)
+	})
+}
+
+// Cancel libera a tentativa sem contá-la como falha, quando o AD não chegou a avaliar as
+// credenciais (ex.: AD indisponível)
+func (r *Reservation) Cancel() {
+	r.finish(func(*Throttle, key, *entry, time.Time) {})
+}
+
+// finish libera a reserva da tentativa em cada chave e aplica o resultado informado
+func (r *Reservation) finish(apply func(t *Throttle, k key, e *entry, now time.Time)) {
+	t := r.throttle
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if r.done {
+		return
+	}
+	r.done = true
+
+	now := t.now()
+	for _, k := range r.keys {
+		e, ok := t.entries[k]
+		if !ok {
+			continue
+		}
+		if e.pending > 0 {
+			e.pending--
+		}
+
+		apply(t, k, e, now)
+		if e.pending == 0 && len(e.failures) == 0 && !now.Before(e.blockedUntil) {
+			delete(t.entries, k)
+		}
+	}
+}
+
+// failure registra uma falha da chave, bloqueando-a ao atingir o limite. O chamador deve deter a trava.
+func (t *Throttle) failure(k key, e *entry, now time.Time) {
+	if now.Before(e.blockedUntil) {
+		return
+	}
+
+	limits := t.limits(k.scope)
+	e.prune(now.Add(-t.config.Window))
+	e.failures = append(e.failures, now)
+	e.lastAttempt = now
+
+	if limits.BlockAfter > 0 && len(e.failures) >= limits.BlockAfter {
+		e.failures = nil
+		e.lastAttempt = time.Time{}
+		e.blockedUntil = now.Add(t.config.BlockDuration)
+		logger.Warn("Tentativas de autenticação bloqueadas por excesso de falhas",
+			"scope", string(k.scope), k.scope.attr(), k.value, "until", e.blockedUntil)
+		return
+	}
+
+	// Bastam as falhas que determinam o atraso máximo ou o bloqueio
+	if limit := max(limits.BlockAfter, limits.DelayAfter+maxDoublings); len(e.failures) > limit {
+		e.failures = e.failures[len(e.failures)-limit:]
+	}
+}
+
+// Unblock remove o bloqueio e as falhas de um usuário, de uma origem ou de um chamador
+// Parâmetros:
+//   - scope: Tipo da chave
+//   - value: Nome de usuário, origem ou chamador
+//
+// Retorna:
+//   - bool: Verdadeiro caso a chave estivesse bloqueada
+func (t *Throttle) Unblock(scope Scope, value string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	k := key{scope: scope, value: normalize(scope, value)}
+	e, ok := t.entries[k]
+	if !ok {
+		return false
+	}
+
+	// As tentativas em andamento continuam contando até que seu resultado seja informado
+	blocked := t.now().Before(e.blockedUntil)
+	if e.pending == 0 {
+		delete(t.entries, k)
+	} else {
+		e.failures, e.lastAttempt, e.blockedUntil = nil, time.Time{}, time.Time{}
+	}
+
+	if blocked {
+		logger.Info("Bloqueio removido", "scope", string(scope), scope.attr(), k.value)
+	}
+
+	return blocked
+}
+
+// Blocks retorna os bloqueios ativos, do que termina primeiro ao que termina por último
+func (t *Throttle) Blocks() []Block {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+
+	blocks := make([]Block, 0)
+	for k, e := range t.entries {
+		if now.Before(e.blockedUntil) {
+			blocks = append(blocks, Block{Scope: k.scope, Key: k.value, Until: e.blockedUntil})
+		}
+	}
+
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i].Until.Before(blocks[j].Until)
+	})
+
+	return blocks
+}
+
+// limits retorna os limites de falhas do tipo de chave
+func (t *Throttle) limits(scope Scope) configs.ThrottleLimits {
+	switch scope {
+	case ScopeSource:
+		return t.config.Source
+	case ScopeCaller:
+		return t.config.Caller
+	}
+
+	return t.config.User
+}
+
+// delay calcula o atraso de uma tentativa após a quantidade de falhas informada
+func (t *Throttle) delay(limits configs.ThrottleLimits, failures int) time.Duration {
+	if limits.DelayAfter == 0 || failures < limits.DelayAfter {
+		return 0
+	}
+
+	delay := t.config.BaseDelay
+	for i := limits.DelayAfter; i < failures && delay < t.config.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, t.config.MaxDelay)
+}
+
+// sweep descarta, no máximo uma vez por janela, as chaves sem falhas recentes, tentativas em
+// andamento nem bloqueio ativo. O chamador deve deter a trava.
+func (t *Throttle) sweep(now time.Time) {
+	if now.Sub(t.lastSweep) < t.config.Window {
+		return
+	}
+	t.lastSweep = now
+
+	for k, e := range t.entries {
+		e.prune(now.Add(-t.config.Window))
+		if len(e.failures) == 0 && e.pending == 0 && !now.Before(e.blockedUntil) {
+			delete(t.entries, k)
+		}
+	}
+}
+
+// prune descarta as falhas anteriores ao início da janela
+func (e *entry) prune(windowStart time.Time) {
+	i := 0
+	for i < len(e.failures) && !e.failures[i].After(windowStart) {
+		i++
+	}
+	e.failures = e.failures[i:]
+}
+
+// keys retorna as chaves de uma tentativa: o usuário e, quando informados, a origem e o chamador
+func keys(username, source, caller string) []key {
+	keys := []key{{scope: ScopeUser, value: normalize(ScopeUser, username)}}
+	if source != "" {
+		keys = append(keys, key{scope: ScopeSource, value: normalize(ScopeSource, source)})
+	}
+	if caller != "" {
+		keys = append(keys, key{scope: ScopeCaller, value: normalize(ScopeCaller, caller)})
+	}
+
+	return keys
+}
+
+// normalize ajusta o valor da chave: o AD não diferencia maiúsculas e minúsculas nos nomes de usuário
+func normalize(scope Scope, value string) string {
+	value = strings.TrimSpace(value)
+	if scope == ScopeUser {
+		return strings.ToLower(value)
+	}
+
+	return value
+}
@@ -0,0 +1,245 @@
+package throttle
+
+import (
+	"auth-ad/src/pkg/configs"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// newTestThrottle cria a limitação de tentativas com relógio controlado pelo teste
+func newTestThrottle(now *time.Time) *Throttle {
+	throttle := NewThrottle(configs.ThrottleConfig{
+		Enabled:       true,
+		Window:        time.Minute,
+		BaseDelay:     time.Second,
+		MaxDelay:      4 * time.Second,
+		BlockDuration: 10 * time.Minute,
+		User:          configs.ThrottleLimits{DelayAfter: 2, BlockAfter: 5},
+		Source:        configs.ThrottleLimits{DelayAfter: 0, BlockAfter: 3},
+	})
+	throttle.now = func() time.Time { return *now }
+
+	return throttle
+}
+
+// reserve reserva uma tentativa que deve ser aceita
+func reserve(t *testing.T, throttle *Throttle, username, source string) *Reservation {
+	t.Helper()
+
+	reservation, err := throttle.Reserve(username, source, "")
+	if err != nil {
+		t.Fatalf("Não esperava recusa: %v", err)
+	}
+
+	return reservation
+}
+
+// fail registra falhas seguidas, aguardando o atraso máximo antes de cada tentativa
+func fail(t *testing.T, throttle *Throttle, now *time.Time, username, source string, count int) {
+	t.Helper()
+
+	for range count {
+		*now = now.Add(throttle.config.MaxDelay)
+		reserve(t, throttle, username, source).Failure()
+	}
+}
+
+// checkLimited verifica a recusa de uma tentativa
+func checkLimited(t *testing.T, throttle *Throttle, username, source string, expected LimitedError) {
+	t.Helper()
+
+	_, err := throttle.Reserve(username, source, "")
+	var limited *LimitedError
+	if !errors.As(err, &limited) || limited.Scope != expected.Scope || !limited.Until.Equal(expected.Until) || limited.Blocked != expected.Blocked {
+		t.Fatalf("Recusa incorreta, esperado %+v, obtido %v", expected, err)
+	}
+}
+
+func TestThrottle_ProgressiveDelay(t *testing.T) {
+	now := time.Now()
+	throttle := newTestThrottle(&now)
+
+	reserve(t, throttle, "jdoe", "").Failure()
+	reserve(t, throttle, "JDoe", "").Failure()
+
+	// O atraso desde a última tentativa começa em DelayAfter falhas e dobra a cada falha até MaxDelay
+	for _, delay := range []time.Duration{time.Second, 2 * time.Second} {
+		checkLimited(t, throttle, "jdoe", "", LimitedError{Scope: ScopeUser, Until: now.Add(delay)})
+		now = now.Add(delay)
+		reserve(t, throttle, "jdoe", "").Failure()
+	}
+	checkLimited(t, throttle, "jdoe", "", LimitedError{Scope: ScopeUser, Until: now.Add(4 * time.Second)})
+
+	// Um sucesso zera as falhas do usuário
+	now = now.Add(4 * time.Second)
+	reserve(t, throttle, "jdoe", "").Success()
+	reserve(t, throttle, "jdoe", "").Cancel()
+}
+
+func TestThrottle_ConcurrentAttempts(t *testing.T) {
+	now := time.Now()
+	throttle := newTestThrottle(&now)
+
+	// As tentativas em andamento contam para o limite, sem esperar o resultado de cada uma
+	var reservations []*Reservation
+	for _, username := range []string{"ana", "bia", "caio"} {
+		reservations = append(reservations, reserve(t, throttle, username, "10.0.0.1"))
+	}
+	checkLimited(t, throttle, "davi", "10.0.0.1", LimitedError{Scope: ScopeSource, Until: now.Add(time.Second)})
+
+	// Um sucesso libera a reserva sem contar falha
+	reservations[0].Success()
+	last := reserve(t, throttle, "davi", "10.0.0.1")
+
+	reservations[1].Failure()
+	reservations[2].Failure()
+	last.Failure()
+	checkLimited(t, throttle, "eva", "10.0.0.1", LimitedError{Scope: ScopeSource, Until: now.Add(10 * time.Minute), Blocked: true})
+
+	// O resultado de uma reserva só é considerado uma vez
+	reservations[1].Failure()
+	if blocks := throttle.Blocks(); len(blocks) != 1 {
+		t.Errorf("Esperava apenas o bloqueio da origem: %v", blocks)
+	}
+}
+
+func TestThrottle_CancelDoesNotCount(t *testing.T) {
+	now := time.Now()
+	throttle := newTestThrottle(&now)
+
+	for range 10 {
+		reserve(t, throttle, "jdoe", "10.0.0.1").Cancel()
+	}
+	if len(throttle.entries) != 0 {
+		t.Errorf("Não esperava falhas registradas: %v", throttle.entries)
+	}
+}
+
+func TestThrottle_SlidingWindow(t *testing.T) {
+	now := time.Now()
+	throttle := newTestThrottle(&now)
+
+	reserve(t, throttle, "jdoe", "").Failure()
+	now = now.Add(40 * time.Second)
+	reserve(t, throttle, "jdoe", "").Failure()
+	checkLimited(t, throttle, "jdoe", "", LimitedError{Scope: ScopeUser, Until: now.Add(time.Second)})
+
+	// A primeira falha sai da janela
+	now = now.Add(30 * time.Second)
+	reserve(t, throttle, "jdoe", "").Cancel()
+}
+
+func TestThrottle_BlocksUser(t *testing.T) {
+	now := time.Now()
+	throttle := newTestThrottle(&now)
+
+	fail(t, throttle, &now, "jdoe", "", 5)
+	checkLimited(t, throttle, "JDOE", "", LimitedError{Scope: ScopeUser, Until: now.Add(10 * time.Minute), Blocked: true})
+
+	// Outros usuários não são afetados
+	reserve(t, throttle, "outro", "").Cancel()
+
+	// Ao expirar, o bloqueio libera o usuário com as falhas zeradas
+	now = now.Add(10 * time.Minute)
+	reserve(t, throttle, "jdoe", "").Cancel()
+	if blocks := throttle.Blocks(); len(blocks) != 0 {
+		t.Errorf("Não esperava bloqueios ativos: %v", blocks)
+	}
+}
+
+func TestThrottle_BlocksSource(t *testing.T) {
+	now := time.Now()
+	throttle := newTestThrottle(&now)
+
+	// Falhas de usuários diferentes contam para a mesma origem
+	reserve(t, throttle, "ana", "10.0.0.1").Failure()
+	reserve(t, throttle, "bia", "10.0.0.1").Failure()
+	reserve(t, throttle, "caio", "10.0.0.1").Failure()
+	checkLimited(t, throttle, "davi", "10.0.0.1", LimitedError{Scope: ScopeSource, Until: now.Add(10 * time.Minute), Blocked: true})
+
+	// Sem origem, apenas o usuário é verificado
+	reserve(t, throttle, "davi", "").Cancel()
+	reserve(t, throttle, "davi", "10.0.0.2").Cancel()
+}
+
+func TestThrottle_SharedCaller(t *testing.T) {
+	now := time.Now()
+	throttle := newTestThrottle(&now)
+	throttle.config.Window = time.Hour
+
+	// failShared registra falhas de usuários diferentes encaminhadas pelo mesmo chamador
+	failShared := func(users ...string) {
+		for _, username := range users {
+			for range 4 {
+				now = now.Add(throttle.config.MaxDelay)
+				reservation, err := throttle.Reserve(username, "", "10.0.0.5")
+				if err != nil {
+					t.Fatalf("Não esperava recusa: %v", err)
+				}
+				reservation.Failure()
+			}
+		}
+	}
+
+	// Sem limites por chamador, as falhas de poucos usuários não bloqueiam os demais usuários do
+	// mesmo proxy, ainda que ultrapassem os limites de uma origem
+	failShared("ana", "bia", "caio")
+	for i := range 50 {
+		reservation, err := throttle.Reserve(fmt.Sprintf("usuario%d", i), "", "10.0.0.5")
+		if err != nil {
+			t.Fatalf("Não esperava recusa do chamador compartilhado: %v", err)
+		}
+		reservation.Success()
+	}
+
+	// Com limites próprios e bem maiores, o chamador só é bloqueado ao atingi-los
+	throttle.config.Caller = configs.ThrottleLimits{BlockAfter: 20}
+	failShared("davi", "edu", "fabi")
+	reservation, err := throttle.Reserve("gabi", "", "10.0.0.5")
+	if err != nil {
+		t.Fatalf("Não esperava recusa abaixo do limite do chamador: %v", err)
+	}
+	reservation.Success()
+
+	failShared("hugo", "iris")
+	_, err = throttle.Reserve("gabi", "", "10.0.0.5")
+	var limited *LimitedError
+	if !errors.As(err, &limited) || limited.Scope != ScopeCaller || !limited.Blocked {
+		t.Fatalf("Esperava o bloqueio do chamador, obtido %v", err)
+	}
+
+	// O endereço do usuário final é limitado à parte do chamador
+	_, err = throttle.Reserve("gabi", "192.0.2.10", "10.0.0.6")
+	if err != nil {
+		t.Fatalf("Não esperava recusa de outro chamador: %v", err)
+	}
+}
+
+func TestThrottle_Unblock(t *testing.T) {
+	now := time.Now()
+	throttle := newTestThrottle(&now)
+
+	fail(t, throttle, &now, "jdoe", "", 5)
+	now = now.Add(time.Second)
+	fail(t, throttle, &now, "ana", "10.0.0.2", 3)
+
+	blocks := throttle.Blocks()
+	if len(blocks) != 2 || blocks[1] != (Block{Scope: ScopeSource, Key: "10.0.0.2", Until: now.Add(10 * time.Minute)}) {
+		t.Fatalf("Bloqueios incorretos: %v", blocks)
+	}
+
+	if !throttle.Unblock(ScopeUser, "JDoe") {
+		t.Error("Esperava remover o bloqueio do usuário")
+	}
+	if throttle.Unblock(ScopeUser, "jdoe") {
+		t.Error("Não esperava bloqueio após a remoção")
+	}
+	if throttle.Unblock(ScopeSource, "10.0.0.9") {
+		t.Error("Não esperava bloqueio de uma origem desconhecida")
+	}
+
+	reserve(t, throttle, "jdoe", "").Cancel()
+	checkLimited(t, throttle, "jdoe", "10.0.0.2", LimitedError{Scope: ScopeSource, Until: now.Add(10 * time.Minute), Blocked: true})
+}
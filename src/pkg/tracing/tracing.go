@@ -0,0 +1,104 @@
+package tracing
+
+import (
+	"auth-ad/src/pkg/configs"
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Atributos comuns dos spans do serviço
+const (
+	AttrRequestID = attribute.Key("auth.request_id") // ID da requisição de autenticação
+	AttrTenant    = attribute.Key("auth.tenant")     // Tenant da requisição
+)
+
+// Setup configura o TracerProvider global conforme config e o propagador W3C Trace Context, usado
+// nas chamadas HTTP à API. Com o exportador none, os spans não são registrados, mas o contexto
+// de trace recebido continua sendo propagado.
+// Parâmetros:
+//   - ctx: Contexto da criação do exportador
+//   - config: Configurações dos traces
+//
+// Retorna:
+//   - func(context.Context) error: Função que envia os spans pendentes e encerra o exportador
+//   - error: Erro em caso de falha na criação do exportador
+func Setup(ctx context.Context, config *configs.TracingConfig) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if config.Exporter != configs.TracingExporterOtlp {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := newOtlpExporter(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar o exportador de traces: %w", err)
+	}
+
+	provider := NewTracerProvider(config, exporter)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// NewTracerProvider cria o TracerProvider do serviço, que envia os spans ao exportador em lotes.
+// A amostragem segue a decisão do trace pai e, nos traces iniciados pelo serviço, config.SampleRatio.
+// Parâmetros:
+//   - config: Configurações dos traces
+//   - exporter: Exportador dos spans, como um exportador em memória nos testes
+//
+// Retorna:
+//   - *sdktrace.TracerProvider: TracerProvider, encerrado por Shutdown
+func NewTracerProvider(config *configs.TracingConfig, exporter sdktrace.SpanExporter) *sdktrace.TracerProvider {
+	serviceResource, err := resource.Merge(resource.Default(),
+		resource.NewSchemaless(attribute.String("service.name", config.ServiceName)))
+	if err != nil {
+		// Esquemas conflitantes: mantém apenas o nome do serviço
+		serviceResource = resource.NewSchemaless(attribute.String("service.name", config.ServiceName))
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(serviceResource),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	)
+}
+
+// End encerra um span, marcando-o com erro caso err não seja nil
+// Parâmetros:
+//   - span: Span a ser encerrado
+//   - err: Erro da operação
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// newOtlpExporter cria o exportador OTLP no protocolo configurado. As variáveis OTEL_EXPORTER_OTLP_*
+// (cabeçalhos, certificados, compressão) continuam valendo para o que não é configurado aqui.
+func newOtlpExporter(ctx context.Context, config *configs.TracingConfig) (sdktrace.SpanExporter, error) {
+	if config.Protocol == configs.OtlpProtocolHttp {
+		var options []otlptracehttp.Option
+		if config.Endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpointURL(config.Endpoint))
+		}
+		return otlptracehttp.New(ctx, options...)
+	}
+
+	var options []otlptracegrpc.Option
+	if config.Endpoint != "" {
+		options = append(options, otlptracegrpc.WithEndpointURL(config.Endpoint))
+	}
+	return otlptracegrpc.New(ctx, options...)
+}
@@ -0,0 +1,92 @@
+package tracing
+
+import (
+	"auth-ad/src/pkg/configs"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewTracerProvider(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := NewTracerProvider(&configs.TracingConfig{ServiceName: "auth-ad-test", SampleRatio: 1}, exporter)
+
+	_, span := provider.Tracer("test").Start(context.Background(), "operação")
+	End(span, errors.New("falha"))
+
+	// O exportador em memória descarta os spans no encerramento; ForceFlush envia o lote sem encerrar
+	if err := provider.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("Erro inesperado no envio dos spans: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Esperado 1 span, recebidos %d", len(spans))
+	}
+	if spans[0].Status.Code != codes.Error || len(spans[0].Events) != 1 {
+		t.Errorf("Esperado span com erro registrado, recebido %+v", spans[0].Status)
+	}
+
+	serviceName, ok := spans[0].Resource.Set().Value("service.name")
+	if !ok || serviceName.AsString() != "auth-ad-test" {
+		t.Errorf("Nome do serviço incorreto: %v", serviceName)
+	}
+}
+
+func TestNewTracerProvider_SampleRatio(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := NewTracerProvider(&configs.TracingConfig{ServiceName: "auth-ad", SampleRatio: 0}, exporter)
+
+	// Sem amostragem, os traces iniciados pelo serviço não são registrados
+	_, span := provider.Tracer("test").Start(context.Background(), "descartado")
+	span.End()
+
+	// Os traces já amostrados pelo chamador continuam sendo registrados
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	_, span = provider.Tracer("test").Start(trace.ContextWithRemoteSpanContext(context.Background(), parent), "registrado")
+	span.End()
+
+	provider.ForceFlush(context.Background())
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Name != "registrado" {
+		t.Errorf("Esperado apenas o span com pai amostrado, recebidos %d", len(spans))
+	}
+}
+
+func TestSetup_WithoutExporter(t *testing.T) {
+	previous := otel.GetTextMapPropagator()
+	defer otel.SetTextMapPropagator(previous)
+
+	shutdown, err := Setup(context.Background(), &configs.TracingConfig{Exporter: configs.TracingExporterNone})
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("Erro inesperado no encerramento: %v", err)
+	}
+
+	// O contexto de trace recebido é propagado mesmo sem exportador
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	})
+	header := http.Header{}
+	otel.GetTextMapPropagator().Inject(trace.ContextWithSpanContext(context.Background(), parent), propagation.HeaderCarrier(header))
+	if header.Get("traceparent") != "00-01000000000000000000000000000000-0200000000000000-01" {
+		t.Errorf("traceparent incorreto: %q", header.Get("traceparent"))
+	}
+}